@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+)
+
+// runAcronymTest drills acronyms and their expansions from pairs file in
+// both directions, items are picked by spaced retest schedule.
+func runAcronymTest(options Options, test Test) (Result, error) {
+	retestFile := getRetestFile(options.Database)
+
+	retest, err := loadRetest(retestFile)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cards := map[string]Pair{}
+	keys := []string{}
+	for _, pair := range options.Pairs {
+		forward := "acronym:" + pair.Key + ":forward"
+		backward := "acronym:" + pair.Key + ":backward"
+
+		cards[forward] = Pair{Key: pair.Key, Value: pair.Value}
+		cards[backward] = Pair{Key: pair.Value, Value: pair.Key}
+
+		keys = append(keys, forward, backward)
+	}
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+
+	pairs := []Pair{}
+	score := 0
+	for _, key := range retest.pickDue(keys, test.Count, clock()) {
+		pair := cards[key]
+
+		pair.Answer, err = readLine(pair.Key, height/2)
+		if err != nil {
+			return Result{}, err
+		}
+
+		correct := strings.EqualFold(
+			strings.Join(strings.Fields(pair.Answer), " "),
+			strings.Join(strings.Fields(pair.Value), " "),
+		)
+		if correct {
+			score++
+		} else {
+			err = showCorrection(pair)
+			if err != nil {
+				return Result{}, err
+			}
+		}
+
+		retest.update(key, correct, clock())
+		pairs = append(pairs, pair)
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	err = saveRetest(retestFile, retest)
+	if err != nil {
+		return Result{}, err
+	}
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			plainLines(formatPairs(pairs, true)), score, len(pairs),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    len(pairs),
+		Note:     note,
+		Mode:     modeAcronym,
+		Pairs:    pairs,
+	}, nil
+}
+
+// showCorrection shows correct answer for wrongly answered item.
+func showCorrection(pair Pair) error {
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(pair.Key, height/2-1)
+	printCentered("correct: "+pair.Value, height/2)
+	printCentered("Press Enter to continue", height/2+2)
+	screen.HideCursor()
+	screen.Flush()
+
+	return wait()
+}
@@ -0,0 +1,32 @@
+package main
+
+// count of consecutive failed tests which decrease count of items
+const adaptiveFailures = 2
+
+// adaptiveSpan is count of items in adaptive mode, it grows by one after
+// perfect recall and drops by one after consecutive failures, like in
+// classic digit span test
+type adaptiveSpan struct {
+	count    int
+	failures int
+}
+
+// update counts the result and returns count of items of the next test.
+func (span *adaptiveSpan) update(result Result) int {
+	if result.Score == result.Count {
+		span.count++
+		span.failures = 0
+
+		return span.count
+	}
+
+	span.failures++
+	if span.failures >= adaptiveFailures {
+		span.failures = 0
+		if span.count > 1 {
+			span.count--
+		}
+	}
+
+	return span.count
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// accuracy of block from which harder or easier next blocks are suggested
+const (
+	adjustHarderAccuracy = 0.9
+	adjustEasierAccuracy = 0.6
+)
+
+// Adjustment is change of difficulty chosen by the user between blocks of
+// session run with --adjust-blocks
+type Adjustment struct {
+	// count of tests completed before the prompt
+	Test int `json:"test"`
+
+	// accuracy of the finished block
+	Accuracy float64 `json:"accuracy"`
+
+	// change of count of items of the next blocks suggested by accuracy and
+	// chosen by the user: -1, 0 or 1
+	Suggested int `json:"suggested"`
+	Change    int `json:"change"`
+}
+
+// getBlockEnds returns indexes of tests which finish blocks of consecutive
+// tests with the same parameters, except the last block of the session.
+func getBlockEnds(tests []Test) map[int]bool {
+	ends := map[int]bool{}
+	for _, block := range getDryRunBlocks(tests) {
+		if block.last < len(tests) {
+			ends[block.last-1] = true
+		}
+	}
+
+	return ends
+}
+
+// getSuggestedChange returns change of count of items suggested by
+// accuracy of the block.
+func getSuggestedChange(accuracy float64) int {
+	switch {
+	case accuracy >= adjustHarderAccuracy:
+		return 1
+	case accuracy < adjustEasierAccuracy:
+		return -1
+	}
+
+	return 0
+}
+
+func formatChange(change int) string {
+	switch change {
+	case 1:
+		return "harder"
+	case -1:
+		return "easier"
+	}
+
+	return "keep"
+}
+
+// askAdjustment shows accuracy of the finished block and asks whether the
+// next blocks should have one item more or less, Enter takes the
+// suggested change. Count of items never drops below one.
+func askAdjustment(block []Result, completed int, next int) (
+	Adjustment, error,
+) {
+	var sum float64
+	for _, result := range block {
+		sum += getAccuracy(result)
+	}
+
+	adjustment := Adjustment{
+		Test:     completed,
+		Accuracy: sum / float64(len(block)),
+	}
+
+	adjustment.Suggested = getSuggestedChange(adjustment.Accuracy)
+	if next+adjustment.Suggested < 1 {
+		adjustment.Suggested = 0
+	}
+
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(
+		fmt.Sprintf(
+			"block accuracy: %s%%, next block has %d items",
+			formatFloat(adjustment.Accuracy*100, 1), next,
+		),
+		height/2-1,
+	)
+	printCentered(
+		fmt.Sprintf(
+			"Up: harder, Down: easier, Space: keep, Enter: %s",
+			formatChange(adjustment.Suggested),
+		),
+		height/2+1,
+	)
+	screen.HideCursor()
+	screen.Flush()
+
+	for {
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyEnter:
+			adjustment.Change = adjustment.Suggested
+		case event.Key == ui.KeyArrowUp || event.Ch == '+':
+			adjustment.Change = 1
+		case (event.Key == ui.KeyArrowDown || event.Ch == '-') &&
+			next > 1:
+			adjustment.Change = -1
+		case event.Key == ui.KeySpace:
+			adjustment.Change = 0
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return adjustment, errAborted
+		case event.Key == ui.KeyEsc:
+			return adjustment, errStopped
+		default:
+			continue
+		}
+
+		clearScreen()
+		return adjustment, nil
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// sessions tagged by this tag are excluded from stats
+const anomalyTag = "anomaly"
+
+const (
+	// count of previous sessions forming personal baseline of the session
+	anomalyBaseline = 20
+
+	// sessions are not judged until baseline has this count of sessions
+	anomalyMinBaseline = 5
+
+	// modified z-score from which session is anomalous, as suggested by
+	// Iglewicz and Hoaglin
+	anomalyThreshold = 3.5
+)
+
+// anomaly is session whose accuracy is far from the baseline
+type anomaly struct {
+	session Session
+
+	// median accuracy of the baseline and modified z-score of the session
+	median float64
+	score  float64
+}
+
+// getAnomalies returns sessions whose accuracy is far above or below median
+// of the previous sessions. Distance is measured by median absolute
+// deviation, so the baseline isn't shifted by other outliers. Sessions
+// tagged as anomalies are not taken into baselines.
+func getAnomalies(database Database) []anomaly {
+	anomalies := []anomaly{}
+	baseline := []float64{}
+
+	for _, index := range findSessions(database, Query{}, orderDate, false) {
+		session := database.Sessions[index]
+		accuracy := getSessionAccuracy(session)
+
+		if len(baseline) >= anomalyMinBaseline {
+			median, deviation := getMedianDeviation(baseline)
+			if deviation > 0 {
+				score := 0.6745 * (accuracy - median) / deviation
+				if math.Abs(score) >= anomalyThreshold {
+					anomalies = append(anomalies, anomaly{
+						session: session, median: median, score: score,
+					})
+				}
+			}
+		}
+
+		if session.hasTag(anomalyTag) {
+			continue
+		}
+
+		baseline = append(baseline, accuracy)
+		if len(baseline) > anomalyBaseline {
+			baseline = baseline[1:]
+		}
+	}
+
+	return anomalies
+}
+
+// getMedianDeviation returns median of values and median of absolute
+// deviations from it.
+func getMedianDeviation(values []float64) (float64, float64) {
+	median := getMedian(values)
+
+	deviations := []float64{}
+	for _, value := range values {
+		deviations = append(deviations, math.Abs(value-median))
+	}
+
+	return median, getMedian(deviations)
+}
+
+func getMedian(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+// printAnomalies lists anomalous sessions, the ones not excluded yet can be
+// excluded by stats --exclude-anomalies.
+func printAnomalies(database Database) {
+	anomalies := getAnomalies(database)
+	if len(anomalies) == 0 {
+		return
+	}
+
+	fmt.Printf(
+		"\nanomalous sessions, far from median of up to %d previous ones:\n",
+		anomalyBaseline,
+	)
+
+	included := 0
+	for _, anomaly := range anomalies {
+		direction := "above"
+		if anomaly.score < 0 {
+			direction = "below"
+		}
+
+		if !anomaly.session.hasTag(anomalyTag) {
+			included++
+		}
+
+		fmt.Printf(
+			"%s, %s median %s%%\n",
+			formatHistorySession(anomaly.session), direction,
+			formatFloat(anomaly.median*100, 1),
+		)
+	}
+
+	if included > 0 {
+		fmt.Printf(
+			"%d of them are counted in stats, exclude them by "+
+				"short stats --exclude-anomalies\n",
+			included,
+		)
+	}
+}
+
+// excludeAnomalies tags anomalous scored sessions of the profile, so they
+// are excluded from stats, with dryRun they are only printed. Sessions are
+// included back by removing the tag with short edit.
+func excludeAnomalies(file string, profile string, dryRun bool) error {
+	release, err := acquireLock(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	scored := database.getProfile(profile).getScored()
+
+	anomalous := map[string]bool{}
+	for _, anomaly := range getAnomalies(scored) {
+		anomalous[getSessionKey(anomaly.session)] = true
+	}
+
+	edit := Edit{AddTags: []string{anomalyTag}}
+
+	changed := 0
+	for index := range database.Sessions {
+		session := &database.Sessions[index]
+		if !anomalous[getSessionKey(*session)] {
+			continue
+		}
+
+		before := formatHistorySession(*session)
+		if !edit.apply(session) {
+			continue
+		}
+
+		changed++
+		fmt.Printf("- %s\n+ %s\n", before, formatHistorySession(*session))
+	}
+
+	if dryRun {
+		fmt.Printf("%d sessions would be excluded from stats\n", changed)
+		return nil
+	}
+
+	fmt.Printf("%d sessions excluded from stats\n", changed)
+	if changed == 0 {
+		return nil
+	}
+
+	return saveDatabase(file, database)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// users of API are profiles, their names are saved with sessions
+var apiUserName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+const (
+	// sessions of API users are saved under profiles with this prefix, so
+	// they never match local, Discord or Matrix profiles
+	apiProfilePrefix = "api-"
+
+	// maximal size of posted session
+	maxAPIBody = 1 << 20
+)
+
+// API is [api] section of config, every user of served API reads and
+// saves sessions of own profile only, so users can't read or change sessions
+// of each other
+type API struct {
+	// SHA-256 of bearer tokens by names of users, tokens are created by
+	// short token
+	Tokens map[string]string `toml:"tokens"`
+}
+
+func (api API) validate() error {
+	for name, hash := range api.Tokens {
+		if !apiUserName.MatchString(name) {
+			return fmt.Errorf(
+				"tokens: user name %q should contain only letters, digits, "+
+					"_ and -",
+				name,
+			)
+		}
+
+		key, err := hex.DecodeString(hash)
+		if err != nil || len(key) != sha256.Size {
+			return fmt.Errorf("tokens: %s: expected hex SHA-256 of token", name)
+		}
+	}
+
+	return nil
+}
+
+// authenticate returns user whose token is in Authorization header, all
+// hashes are compared, so time of response doesn't tell which one matched.
+func (api API) authenticate(request *http.Request) (string, bool) {
+	header := request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+
+	hash := sha256.Sum256([]byte(strings.TrimPrefix(header, "Bearer ")))
+
+	user := ""
+	for name, expected := range api.Tokens {
+		key, _ := hex.DecodeString(expected)
+		if subtle.ConstantTimeCompare(hash[:], key) == 1 {
+			user = name
+		}
+	}
+
+	return user, user != ""
+}
+
+// createToken prints new random token of the user and its hash, which is
+// added to config by the owner of the server.
+func createToken(config string, user string) error {
+	if !apiUserName.MatchString(user) {
+		return fmt.Errorf(
+			"user name should contain only letters, digits, _ and -",
+		)
+	}
+
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return err
+	}
+
+	token := hex.EncodeToString(secret)
+	hash := sha256.Sum256([]byte(token))
+
+	fmt.Printf("token of %s: %s\n", user, token)
+	fmt.Printf("add its hash to [api.tokens] section of %s:\n", config)
+	fmt.Printf("%s = %q\n", user, hex.EncodeToString(hash[:]))
+
+	return nil
+}
+
+// apiServer serves sessions and stats of authenticated users
+type apiServer struct {
+	api      API
+	database string
+}
+
+// serveAPI serves REST API with sessions of users saved in the database
+// under their profiles, they are seen on the dashboard like local profiles.
+func serveAPI(file string, config string, address string) error {
+	loaded, _, err := loadConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if len(loaded.API.Tokens) == 0 {
+		return fmt.Errorf(
+			"no tokens in [api.tokens] of %s, create them by short token",
+			config,
+		)
+	}
+
+	server := &apiServer{
+		api:      loaded.API,
+		database: file,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/sessions", server.authorized(server.handleSessions))
+	mux.HandleFunc("/api/stats", server.authorized(server.handleStats))
+
+	log.info("serving api", Fields{
+		"address": address, "users": len(loaded.API.Tokens),
+	})
+
+	return http.ListenAndServe(address, mux)
+}
+
+// authorized passes requests with valid token to the handler with profile
+// of the user.
+func (server *apiServer) authorized(
+	handler func(http.ResponseWriter, *http.Request, string),
+) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		user, ok := server.api.authenticate(request)
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="short"`)
+			http.Error(writer, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		log.debug("api request", Fields{
+			"user": user, "method": request.Method, "path": request.URL.Path,
+		})
+
+		handler(writer, request, apiProfilePrefix+user)
+	}
+}
+
+// handleSessions lists sessions of the user or appends posted session,
+// since parameter takes sessions from the date.
+func (server *apiServer) handleSessions(
+	writer http.ResponseWriter, request *http.Request, profile string,
+) {
+	switch request.Method {
+	case http.MethodGet:
+		loaded, err := loadDatabase(server.database)
+		if err != nil {
+			writeAPIError(writer, err, http.StatusInternalServerError)
+			return
+		}
+
+		sessions := []Session{}
+		since := request.URL.Query().Get("since")
+		for _, session := range loaded.getProfile(profile).Sessions {
+			if since == "" || session.Date.Format("2006-01-02") >= since {
+				sessions = append(sessions, session)
+			}
+		}
+
+		sort.Slice(sessions, func(i, j int) bool {
+			return sessions[i].Date.Before(sessions[j].Date)
+		})
+
+		writeAPIResponse(writer, sessions, http.StatusOK)
+
+	case http.MethodPost:
+		var session Session
+		err := json.NewDecoder(
+			http.MaxBytesReader(writer, request.Body, maxAPIBody),
+		).Decode(&session)
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeAPIError(
+				writer,
+				fmt.Errorf("session is larger than %d bytes", maxAPIBody),
+				http.StatusRequestEntityTooLarge,
+			)
+			return
+		}
+
+		if err != nil || len(session.Results) == 0 {
+			writeAPIError(
+				writer, fmt.Errorf("expected session with results"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		if session.Date.IsZero() {
+			session.Date = time.Now()
+		}
+
+		// profile is the user, whatever is posted
+		session.Profile = profile
+
+		err = appendSession(server.database, session)
+		if err != nil {
+			writeAPIError(writer, err, http.StatusInternalServerError)
+			return
+		}
+
+		writeAPIResponse(writer, session, http.StatusCreated)
+
+	default:
+		writer.Header().Set("Allow", "GET, POST")
+		http.Error(
+			writer, "method not allowed", http.StatusMethodNotAllowed,
+		)
+	}
+}
+
+// handleStats returns summary of scored sessions of the user by modes.
+func (server *apiServer) handleStats(
+	writer http.ResponseWriter, request *http.Request, profile string,
+) {
+	loaded, err := loadDatabase(server.database)
+	if err != nil {
+		writeAPIError(writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeAPIResponse(
+		writer, getModeStats(loaded.getProfile(profile).getScored()),
+		http.StatusOK,
+	)
+}
+
+func writeAPIResponse(
+	writer http.ResponseWriter, response interface{}, status int,
+) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	err := json.NewEncoder(writer).Encode(response)
+	if err != nil {
+		log.warn("can't write api response", Fields{"error": err})
+	}
+}
+
+func writeAPIError(writer http.ResponseWriter, err error, status int) {
+	writeAPIResponse(writer, map[string]string{"error": err.Error()}, status)
+}
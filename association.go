@@ -0,0 +1,65 @@
+package main
+
+// runAssociationTest shows pairs of words, then every pair is cued by one
+// of its words and the other one is asked, like in paired-associate recall.
+func runAssociationTest(options Options, test Test) (Result, error) {
+	pairs := getAssociationPairs(options, test.Count)
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	clearScreen()
+	printLines(formatPairs(pairs, false))
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := recallPairs(options, modeAssociation, getCuedPairs(pairs), duration)
+	result.Interference = interference
+
+	return result, err
+}
+
+// getAssociationPairs returns pairs from pairs file or pairs of different
+// words from word list.
+func getAssociationPairs(options Options, count int) []Pair {
+	if len(options.Pairs) > 0 {
+		return getMappingPairs(options, count)
+	}
+
+	picked := pickWords(getWordList(options), count*2)
+
+	pairs := []Pair{}
+	for index := 0; index+1 < len(picked); index += 2 {
+		pairs = append(pairs, Pair{Key: picked[index], Value: picked[index+1]})
+	}
+
+	return pairs
+}
+
+// getCuedPairs returns pairs whose keys are cues, half of pairs on average
+// are cued by their values, so both directions are learned.
+func getCuedPairs(pairs []Pair) []Pair {
+	cued := []Pair{}
+	for _, pair := range pairs {
+		if randomInt(2) == 1 {
+			pair.Key, pair.Value = pair.Value, pair.Key
+		}
+
+		cued = append(cued, pair)
+	}
+
+	return cued
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshots are named after the database and the day they are taken on
+const backupDateLayout = "2006-01-02"
+
+// Backups is [backups] section of config, snapshot of the database is
+// taken when session is finished, the latest snapshot of every day, week
+// and month is kept until there are enough of them
+type Backups struct {
+	// directory of snapshots, backups next to the database by default
+	Directory string `toml:"directory"`
+
+	// counts of days, weeks and months whose snapshots are kept, zero
+	// everywhere disables backups
+	Daily   int `toml:"daily"`
+	Weekly  int `toml:"weekly"`
+	Monthly int `toml:"monthly"`
+}
+
+type backup struct {
+	file string
+	date time.Time
+}
+
+func (backups Backups) validate() error {
+	if backups.Daily < 0 || backups.Weekly < 0 || backups.Monthly < 0 {
+		return fmt.Errorf("daily, weekly and monthly can't be negative")
+	}
+
+	return nil
+}
+
+func (backups Backups) isEnabled() bool {
+	return backups.Daily > 0 || backups.Weekly > 0 || backups.Monthly > 0
+}
+
+func (backups Backups) getDirectory(database string) string {
+	if backups.Directory != "" {
+		return expandHome(backups.Directory)
+	}
+
+	return filepath.Join(filepath.Dir(database), "backups")
+}
+
+// backupDatabase replaces today's snapshot of the database and removes
+// snapshots which are not kept by the policy.
+func backupDatabase(database string, backups Backups, now time.Time) error {
+	if !backups.isEnabled() {
+		return nil
+	}
+
+	_, err := os.Stat(database)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	directory := backups.getDirectory(database)
+
+	err = os.MkdirAll(directory, 0700)
+	if err != nil {
+		return err
+	}
+
+	snapshot := filepath.Join(
+		directory,
+		filepath.Base(database)+"-"+now.Format(backupDateLayout),
+	)
+
+	err = writeSnapshot(database, snapshot)
+	if err != nil {
+		return err
+	}
+
+	existing, err := listBackups(database, directory)
+	if err != nil {
+		return err
+	}
+
+	kept := backups.getKept(existing)
+	for _, backup := range existing {
+		if kept[backup.file] {
+			continue
+		}
+
+		err = os.Remove(backup.file)
+		if err != nil {
+			return err
+		}
+
+		log.info("backup removed", Fields{"file": backup.file})
+	}
+
+	return nil
+}
+
+// writeSnapshot writes consistent copy of the database by VACUUM INTO, or
+// copies compressed JSON database, the copy is renamed over the snapshot
+// only when it's complete.
+func writeSnapshot(database string, snapshot string) error {
+	compressed, err := isCompressedDatabase(database)
+	if err != nil {
+		return err
+	}
+
+	// compressed JSON is replaced only by renames, so copy is consistent
+	if compressed {
+		temp := snapshot + ".tmp"
+
+		err = copyFile(database, temp)
+		if err != nil {
+			os.Remove(temp)
+			return err
+		}
+
+		return os.Rename(temp, snapshot)
+	}
+
+	db, err := openSQLite(database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	temp := snapshot + ".tmp"
+	os.Remove(temp)
+
+	_, err = db.Exec(`VACUUM INTO ?`, temp)
+	if err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("can't back up %s: %s", database, err)
+	}
+
+	return os.Rename(temp, snapshot)
+}
+
+// listBackups returns snapshots of the database from the newest one.
+func listBackups(database string, directory string) ([]backup, error) {
+	files, err := ioutil.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return []backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(database) + "-"
+
+	backups := []backup{}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), prefix) {
+			continue
+		}
+
+		date, err := time.ParseInLocation(
+			backupDateLayout, strings.TrimPrefix(file.Name(), prefix),
+			time.Local,
+		)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{
+			file: filepath.Join(directory, file.Name()), date: date,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].date.After(backups[j].date)
+	})
+
+	return backups, nil
+}
+
+// getKept returns files of snapshots kept by the policy, snapshots should
+// be sorted from the newest one.
+func (backups Backups) getKept(existing []backup) map[string]bool {
+	kept := map[string]bool{}
+
+	periods := []struct {
+		count int
+		key   func(time.Time) string
+	}{
+		{backups.Daily, func(date time.Time) string {
+			return date.Format(backupDateLayout)
+		}},
+		{backups.Weekly, func(date time.Time) string {
+			year, week := date.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{backups.Monthly, func(date time.Time) string {
+			return date.Format("2006-01")
+		}},
+	}
+
+	for _, period := range periods {
+		seen := map[string]bool{}
+		for _, backup := range existing {
+			key := period.key(backup.date)
+			if seen[key] || len(seen) >= period.count {
+				continue
+			}
+
+			seen[key] = true
+			kept[backup.file] = true
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// getBackups returns snapshots taken on the dates, dates should be sorted
+// from the newest one like listBackups sorts them.
+func getBackups(t *testing.T, dates ...string) []backup {
+	backups := []backup{}
+	for _, date := range dates {
+		parsed, err := time.Parse(backupDateLayout, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		backups = append(backups, backup{file: date, date: parsed})
+	}
+
+	return backups
+}
+
+func TestBackupsGetKept(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Backups
+		backups []string
+		kept    []string
+	}{
+		{
+			name:   "days, weeks and months",
+			policy: Backups{Daily: 2, Weekly: 3, Monthly: 3},
+			backups: []string{
+				"2024-03-05", "2024-03-04", "2024-03-03", "2024-03-01",
+				"2024-02-29", "2024-02-25", "2024-01-31", "2023-12-31",
+			},
+			kept: []string{
+				"2024-01-31", "2024-02-25", "2024-02-29",
+				"2024-03-03", "2024-03-04", "2024-03-05",
+			},
+		},
+		{
+			// 2021-01-03 is Sunday of the last ISO week of 2020
+			name:   "week across new year",
+			policy: Backups{Weekly: 3},
+			backups: []string{
+				"2021-01-04", "2021-01-03", "2020-12-28", "2020-12-27",
+			},
+			kept: []string{"2020-12-27", "2021-01-03", "2021-01-04"},
+		},
+		{
+			name:    "month boundary",
+			policy:  Backups{Monthly: 2},
+			backups: []string{"2024-03-01", "2024-02-29", "2024-02-01"},
+			kept:    []string{"2024-02-29", "2024-03-01"},
+		},
+		{
+			name:    "fewer snapshots than days",
+			policy:  Backups{Daily: 7},
+			backups: []string{"2024-03-05", "2024-03-01"},
+			kept:    []string{"2024-03-01", "2024-03-05"},
+		},
+		{
+			name:    "disabled",
+			policy:  Backups{},
+			backups: []string{"2024-03-05"},
+			kept:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		kept := []string{}
+		for file := range test.policy.getKept(getBackups(t, test.backups...)) {
+			kept = append(kept, file)
+		}
+
+		sort.Strings(kept)
+
+		if !reflect.DeepEqual(kept, test.kept) {
+			t.Errorf("%s: expected %v kept, got %v", test.name, test.kept, kept)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// unanswered tests are forgotten after this time
+	botAnswerTimeout = 10 * time.Minute
+
+	// items are shown for a second each if --expose is not specified
+	botSecondsPerItem = 1
+)
+
+// chatBot runs tests of chat users, transports like Discord or Matrix
+// deliver commands of users and show items and scores, results are appended
+// to the database with profile named after the chat user
+type chatBot struct {
+	database string
+	options  Options
+
+	// profiles of users are named by the prefix and the user id
+	prefix string
+
+	// command starting the test, it's suggested when nothing is pending
+	command string
+
+	mutex   sync.Mutex
+	pending map[string]chatTest
+}
+
+type chatTest struct {
+	items []string
+	shown time.Time
+}
+
+// newChatBot returns bot running tests with options of command line, items
+// are hidden after a second each if exposure is not specified.
+func newChatBot(
+	file string, options Options, prefix string, command string,
+) *chatBot {
+	if options.Exposure == 0 {
+		options.Exposure = time.Duration(options.Count) *
+			botSecondsPerItem * time.Second
+	}
+
+	return &chatBot{
+		database: file,
+		options:  options,
+		prefix:   prefix,
+		command:  command,
+		pending:  map[string]chatTest{},
+	}
+}
+
+// start generates items for the user, transport hides them after exposure.
+func (bot *chatBot) start(user string) []string {
+	items := bot.options.Charset.generateTokens(
+		nil, bot.options.Min, bot.options.Max, bot.options.Count,
+	)
+
+	bot.mutex.Lock()
+	bot.pending[user] = chatTest{items: items, shown: time.Now()}
+	bot.mutex.Unlock()
+
+	return items
+}
+
+// answer scores items recalled by the user and appends the test to the
+// database as session of the user's profile.
+func (bot *chatBot) answer(user string, text string) (string, error) {
+	bot.mutex.Lock()
+	test, ok := bot.pending[user]
+	delete(bot.pending, user)
+	bot.mutex.Unlock()
+
+	if !ok || time.Since(test.shown) > botAnswerTimeout {
+		return "no numbers to recall, start with " + bot.command, nil
+	}
+
+	input := bot.options.Charset.parseTokens(text)
+
+	scoring := bot.options.getScoring(modeDigits)
+	score := scoreSequence(scoring, test.items, input)
+
+	exposure := bot.options.Exposure.Seconds()
+
+	result := Result{
+		Score:    score,
+		Duration: exposure,
+		Count:    len(test.items),
+		Mode:     modeDigits,
+		Scoring:  scoring,
+		Exposure: exposure,
+	}
+
+	if !bot.options.Charset.isDigits() {
+		result.Charset = bot.options.Charset.Name
+		result.Items = test.items
+		result.Input = input
+	}
+
+	err := appendSession(bot.database, Session{
+		Date:        test.shown,
+		AvgDuration: exposure,
+		TotalScore:  score,
+		Results:     []Result{result},
+		Profile:     bot.prefix + user,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"score: %d/%d\ncorrect: %s", score, len(test.items),
+		strings.Join(test.items, " "),
+	), nil
+}
+
+// getExposureSeconds returns exposure of items shown in messages.
+func (bot *chatBot) getExposureSeconds() string {
+	return formatFloat(bot.options.Exposure.Seconds(), 0)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// length of break when config doesn't specify it, like in 20-20-20 rule
+const defaultBreakDuration = 20 * time.Second
+
+// prompts of breaks when config doesn't specify them
+var defaultBreakPrompts = []string{
+	"Look at something far away.",
+	"Stand up and stretch your back.",
+	"Roll your shoulders and relax your neck.",
+	"Close your eyes and breathe slowly.",
+}
+
+// Breaks is [breaks] section of config, which sets micro-breaks between
+// tests of long sessions
+type Breaks struct {
+	// count of tests between breaks, zero disables breaks
+	Every int `toml:"every"`
+
+	Duration Duration `toml:"duration"`
+
+	// what to do during the break, prompts are taken in turn
+	Prompts []string `toml:"prompts"`
+}
+
+func (breaks Breaks) validate() error {
+	switch {
+	case breaks.Every < 0:
+		return fmt.Errorf("every can't be negative")
+	case breaks.Duration.Duration < 0:
+		return fmt.Errorf("duration can't be negative")
+	}
+
+	return nil
+}
+
+// pauseRequested is set by p pressed while items are shown or on screens
+// between tests, the pause is taken before the next test
+var pauseRequested bool
+
+// takePause blanks the screen until Enter or p is pressed, tests measure
+// their own time, so the pause doesn't affect durations.
+func takePause() error {
+	pauseRequested = false
+
+	for {
+		_, height := screen.Size()
+
+		clearScreen()
+		printCentered("Paused. Enter or p: continue, Esc: stop", height-1)
+		screen.HideCursor()
+		screen.Flush()
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyEnter, event.Ch == 'p':
+			clearScreen()
+			return nil
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return errAborted
+		case event.Key == ui.KeyEsc:
+			return errStopped
+		}
+	}
+}
+
+// takeDue takes break if it's due after specified count of finished tests,
+// there is no break before the first test and after the last one.
+func (breaks Breaks) takeDue(finished, total int) error {
+	if breaks.Every == 0 || finished == 0 || finished%breaks.Every != 0 ||
+		finished >= total {
+		return nil
+	}
+
+	return breaks.takeBreak(finished/breaks.Every - 1)
+}
+
+// takeBreak shows prompt of the break with countdown, Enter ends the break
+// early. Tests measure their own time, so breaks don't affect durations.
+func (breaks Breaks) takeBreak(number int) error {
+	duration := breaks.Duration.Duration
+	if duration == 0 {
+		duration = defaultBreakDuration
+	}
+
+	prompts := breaks.Prompts
+	if len(prompts) == 0 {
+		prompts = defaultBreakPrompts
+	}
+
+	prompt := prompts[number%len(prompts)]
+
+	deadline := time.Now().Add(duration)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				screen.Interrupt()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	_, height := screen.Size()
+
+	for {
+		left := time.Until(deadline).Round(time.Second)
+
+		clearScreen()
+		printCentered("Break: "+prompt, height/2-1)
+		if left > 0 {
+			printCentered(
+				fmt.Sprintf("%d:%02d, Enter: skip", left/time.Minute,
+					left%time.Minute/time.Second),
+				height/2+1,
+			)
+		} else {
+			printCentered("Enter: continue", height/2+1)
+		}
+
+		screen.HideCursor()
+		screen.Flush()
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch event.Key {
+		case ui.KeyEnter:
+			clearScreen()
+			return nil
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return errAborted
+		case ui.KeyEsc:
+			return errStopped
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// getWeekStart returns weekday weeks of summaries, plans and stats start
+// from, empty week_start is monday.
+func (config Config) getWeekStart() time.Weekday {
+	if config.WeekStart == "" {
+		return time.Monday
+	}
+
+	// week_start is checked when config is loaded
+	day, err := parseWeekday(config.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+
+	return day
+}
+
+// getLocation returns time zone sessions are bucketed into days in, empty
+// timezone is local zone of the system. Dates of sessions keep offset of the
+// zone they were recorded in, days are taken from them converted into
+// location of now, which is passed to stats, so histories recorded in
+// different zones are bucketed the same way.
+func (config Config) getLocation() *time.Location {
+	if config.Timezone == "" {
+		return time.Local
+	}
+
+	// timezone is checked when config is loaded
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return location
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf(
+		"unknown week_start %q, expected name of weekday, like monday", name,
+	)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// version of challenge code layout, 2 draws numbers from [min, max], so
+// stimuli of codes of the first version can't be generated again
+const challengeVersion = 2
+
+// codes are uppercase and have no padding, so they are easy to dictate
+var challengeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Challenge is seed and parameters of session, which make its stimuli the
+// same for everyone, so friends can compare their results fairly
+type Challenge struct {
+	Seed     int64
+	Mode     string
+	Tests    int
+	Count    int
+	Min      int
+	Max      int
+	Exposure time.Duration
+	Charset  string
+}
+
+// seeded is source of random numbers while challenge is played, stimuli
+// are generated from crypto/rand otherwise
+var seeded *rand.Rand
+
+func seedRandom(seed int64) {
+	seeded = rand.New(rand.NewSource(seed))
+}
+
+func newChallenge(options Options) Challenge {
+	return Challenge{
+		Seed:     int64(randomInt(1 << 31)),
+		Mode:     options.Mode,
+		Tests:    options.Tests,
+		Count:    options.Count,
+		Min:      options.Min,
+		Max:      options.Max,
+		Exposure: options.Exposure,
+		Charset:  options.Charset.Name,
+	}
+}
+
+// encode returns code of the challenge, it's varints of parameters followed
+// by checksum byte, which catches typos.
+func (challenge Challenge) encode() string {
+	data := []byte{challengeVersion}
+
+	charset := challenge.Charset
+	if charset == charsetDigits {
+		charset = ""
+	}
+
+	for _, value := range []int64{
+		challenge.Seed,
+		int64(indexOf(modes, challenge.Mode)),
+		int64(challenge.Tests),
+		int64(challenge.Count),
+		int64(challenge.Min),
+		int64(challenge.Max),
+		int64(challenge.Exposure / time.Millisecond),
+		int64(len(charset)),
+	} {
+		buffer := make([]byte, binary.MaxVarintLen64)
+		size := binary.PutUvarint(buffer, uint64(value))
+		data = append(data, buffer[:size]...)
+	}
+
+	data = append(data, charset...)
+
+	checksum := sha256.Sum256(data)
+
+	return challengeEncoding.EncodeToString(append(data, checksum[0]))
+}
+
+func decodeChallenge(code string) (Challenge, error) {
+	data, err := challengeEncoding.DecodeString(
+		strings.ToUpper(strings.TrimSpace(code)),
+	)
+	if err != nil || len(data) < 2 {
+		return Challenge{}, fmt.Errorf("challenge code %q is malformed", code)
+	}
+
+	checksum := sha256.Sum256(data[:len(data)-1])
+	if checksum[0] != data[len(data)-1] {
+		return Challenge{}, fmt.Errorf(
+			"challenge code %q is mistyped, checksum doesn't match", code,
+		)
+	}
+
+	if data[0] != challengeVersion {
+		return Challenge{}, fmt.Errorf(
+			"challenge code %q is made by another version of short", code,
+		)
+	}
+
+	data = data[1 : len(data)-1]
+
+	values := []int64{}
+	for len(values) < 8 {
+		value, size := binary.Uvarint(data)
+		if size <= 0 || value > 1<<40 {
+			return Challenge{}, fmt.Errorf(
+				"challenge code %q is malformed", code,
+			)
+		}
+
+		values = append(values, int64(value))
+		data = data[size:]
+	}
+
+	// modes are encoded by index, so new modes are appended to their list
+	if values[1] >= int64(len(modes)) || values[7] != int64(len(data)) {
+		return Challenge{}, fmt.Errorf("challenge code %q is malformed", code)
+	}
+
+	challenge := Challenge{
+		Seed:     values[0],
+		Mode:     modes[values[1]],
+		Tests:    int(values[2]),
+		Count:    int(values[3]),
+		Min:      int(values[4]),
+		Max:      int(values[5]),
+		Exposure: time.Duration(values[6]) * time.Millisecond,
+		Charset:  string(data),
+	}
+
+	if challenge.Charset == "" {
+		challenge.Charset = charsetDigits
+	}
+
+	return challenge, nil
+}
+
+// apply overrides parameters of the session by the challenge.
+func (challenge Challenge) apply(options *Options) error {
+	charset, err := parseCharset(challenge.Charset)
+	if err != nil {
+		return err
+	}
+
+	options.Mode = challenge.Mode
+	options.Tests = challenge.Tests
+	options.Count = challenge.Count
+	options.Lengths = []int{challenge.Count}
+
+	// interleaved schedule shuffles tests using the same random numbers
+	options.Schedule = scheduleBlocked
+
+	// results are verified by strict scoring
+	options.Scoring = ""
+	options.Min = challenge.Min
+	options.Max = challenge.Max
+	options.Exposure = challenge.Exposure
+	options.Charset = charset
+
+	return nil
+}
+
+// createChallenge prints code of challenge with parameters taken from
+// command line options.
+func createChallenge(args map[string]interface{}) error {
+	options, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	challenge := newChallenge(options)
+	options.Challenge = &challenge
+
+	err = options.validate()
+	if err != nil {
+		return err
+	}
+
+	code := challenge.encode()
+
+	fmt.Println(code)
+	fmt.Fprintf(
+		os.Stderr, "share it, everyone plays it by: short challenge play %s\n",
+		code,
+	)
+
+	return nil
+}
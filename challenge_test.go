@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codes and stimuli are shared between players, so they must not change
+// between versions
+var testChallenge = Challenge{
+	Seed:     42,
+	Mode:     modeDigits,
+	Tests:    2,
+	Count:    4,
+	Min:      0,
+	Max:      9,
+	Exposure: 2 * time.Second,
+	Charset:  charsetDigits,
+}
+
+const testChallengeCode = "AIVAAAQEAAE5ADYA2Q"
+
+func TestChallengeCode(t *testing.T) {
+	code := testChallenge.encode()
+	if code != testChallengeCode {
+		t.Errorf("expected code %s, got %s", testChallengeCode, code)
+	}
+
+	challenge, err := decodeChallenge(strings.ToLower(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if challenge != testChallenge {
+		t.Errorf("expected %+v, got %+v", testChallenge, challenge)
+	}
+
+	_, err = decodeChallenge("AIVAAAQEAAE5ADZA2Q")
+	if err == nil {
+		t.Errorf("mistyped code is accepted")
+	}
+}
+
+func TestChallengeStimuli(t *testing.T) {
+	stimuli, err := testChallenge.getStimuli()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"5", "7", "8", "0"}, {"3", "5", "7", "6"}}
+	if !reflect.DeepEqual(stimuli, expected) {
+		t.Errorf("expected stimuli %v, got %v", expected, stimuli)
+	}
+}
+
+func TestSignedResult(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.toml")
+
+	// signatures of ed25519 are deterministic, so the key makes them known
+	seed := hex.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	err := ioutil.WriteFile(getChallengeKeyFile(config), []byte(seed), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := Session{
+		Date:      time.Date(2024, 3, 5, 18, 42, 0, 0, time.UTC),
+		Challenge: testChallengeCode,
+		Results: []Result{
+			{
+				Items: []string{"5", "7", "8", "0"},
+				Input: []string{"5", "7", "8", "0"},
+				Score: 4, Duration: 1.5,
+			},
+			{
+				Items: []string{"3", "5", "7", "6"},
+				Input: []string{"3", "5"},
+				Score: 2, Duration: 1.5,
+			},
+		},
+	}
+
+	file, err := writeSignedResult(filepath.Join(dir, "db"), config, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result signedResult
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "7a41a1d5368e7662c2ea7ac2f776d4905bf78ffd516437e8" +
+		"fc4011bccf9b2f8c300e8c370f27017ea6724ef4d8ca28a284269b2f" +
+		"cfb7d40bd80b7bc92f35c700"
+	signature := hex.EncodeToString(result.Signature)
+	if signature != expected {
+		t.Errorf("expected signature %s, got %s", expected, signature)
+	}
+
+	err = verifyResult(file)
+	if err != nil {
+		t.Fatalf("signed result is not verified: %s", err)
+	}
+
+	// raised score breaks the signature
+	result.Tests[1].Score = 4
+
+	content, err = json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(file, content, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifyResult(file)
+	if err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("changed result is verified: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	charsetDigits  = "digits"
+	charsetLetters = "letters"
+	charsetAlnum   = "alnum"
+
+	// prefix of charset with characters specified by the user
+	charsetCustom = "custom:"
+)
+
+// Charset is material of digits and reverse modes, digits are numbers from
+// -i to -a, other charsets are single characters, like in letter span test
+type Charset struct {
+	Name  string
+	chars []rune
+
+	// letters are entered in any case
+	upper bool
+}
+
+func parseCharset(value string) (Charset, error) {
+	switch {
+	case value == charsetDigits:
+		return Charset{Name: value}, nil
+
+	case value == charsetLetters:
+		return Charset{
+			Name:  value,
+			chars: []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ"),
+			upper: true,
+		}, nil
+
+	case value == charsetAlnum:
+		return Charset{
+			Name:  value,
+			chars: []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"),
+			upper: true,
+		}, nil
+
+	case strings.HasPrefix(value, charsetCustom):
+		chars := []rune{}
+		for _, symbol := range strings.TrimPrefix(value, charsetCustom) {
+			if unicode.IsSpace(symbol) {
+				return Charset{}, fmt.Errorf(
+					"--charset: custom characters can't contain spaces",
+				)
+			}
+
+			if !containsRune(chars, symbol) {
+				chars = append(chars, symbol)
+			}
+		}
+
+		if len(chars) < 2 {
+			return Charset{}, fmt.Errorf(
+				"--charset: at least 2 custom characters are required",
+			)
+		}
+
+		return Charset{Name: value, chars: chars}, nil
+	}
+
+	return Charset{}, fmt.Errorf(
+		"--charset: unknown charset %q, expected digits, letters, alnum or "+
+			"custom:<chars>",
+		value,
+	)
+}
+
+func containsRune(runes []rune, symbol rune) bool {
+	for _, candidate := range runes {
+		if candidate == symbol {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (charset Charset) isDigits() bool {
+	return len(charset.chars) == 0
+}
+
+// generateTokens returns random numbers from min to max or characters of
+// the charset, they are drawn from crypto/rand if the source is nil.
+func (charset Charset) generateTokens(
+	source *rand.Rand, min, max, count int,
+) []string {
+	if charset.isDigits() {
+		return strings.Fields(
+			joinNumbers(generateRandomNumbers(source, min, max, count)),
+		)
+	}
+
+	tokens := []string{}
+	for i := 0; i < count; i++ {
+		index := randomIntFrom(source, len(charset.chars))
+		tokens = append(tokens, string(charset.chars[index]))
+	}
+
+	return tokens
+}
+
+// accept tells if symbol can be entered by the user.
+func (charset Charset) accept(symbol rune) bool {
+	if charset.isDigits() {
+		return isDigit(symbol)
+	}
+
+	return containsRune(charset.chars, charset.normalize(symbol))
+}
+
+func (charset Charset) normalize(symbol rune) rune {
+	if charset.upper {
+		return unicode.ToUpper(symbol)
+	}
+
+	return symbol
+}
+
+// parseTokens splits text entered by the user into tokens, numbers are
+// parsed like parseNumbers does, so pieces which are not numbers are zeros.
+// Characters may be typed with or without spaces between them.
+func (charset Charset) parseTokens(text string) []string {
+	if charset.isDigits() {
+		tokens := []string{}
+		for _, number := range parseNumbers(text) {
+			tokens = append(tokens, strconv.Itoa(number))
+		}
+
+		return tokens
+	}
+
+	tokens := []string{}
+	for _, symbol := range text {
+		if !unicode.IsSpace(symbol) {
+			tokens = append(tokens, string(charset.normalize(symbol)))
+		}
+	}
+
+	return tokens
+}
+
+// reverseTokens returns tokens in reverse order.
+func reverseTokens(tokens []string) []string {
+	reversed := []string{}
+	for index := len(tokens) - 1; index >= 0; index-- {
+		reversed = append(reversed, tokens[index])
+	}
+
+	return reversed
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// generateSquares returns random squares of chessboard, they are drawn
+// from crypto/rand if the source is nil.
+func generateSquares(source *rand.Rand, count int) []string {
+	squares := []string{}
+	for i := 0; i < count; i++ {
+		file := rune('a' + randomIntFrom(source, 8))
+		rank := rune('1' + randomIntFrom(source, 8))
+
+		squares = append(squares, string(file)+string(rank))
+	}
+
+	return squares
+}
+
+func isSquare(token string) bool {
+	return len(token) == 2 &&
+		token[0] >= 'a' && token[0] <= 'h' &&
+		token[1] >= '1' && token[1] <= '8'
+}
+
+func isChessSymbol(symbol rune) bool {
+	return (symbol >= 'a' && symbol <= 'h') || (symbol >= '1' && symbol <= '8')
+}
+
+func runChessTest(options Options, test Test) (Result, error) {
+	squares := generateSquares(seeded, test.Count)
+	wholeTest := strings.Join(squares, " ")
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+	y := height / 2
+
+	clearScreen()
+	printCentered(wholeTest, y)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	clearScreen()
+
+	x, y := getInputPosition(wholeTest)
+
+	text, err := readText(x, y, isChessSymbol)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input := strings.Fields(text)
+
+	// malformed coordinates like "e" or "e44" are kept, so they are shown
+	// in feedback and count as mistakes
+	invalid := 0
+	for _, token := range input {
+		if !isSquare(token) {
+			invalid++
+		}
+	}
+
+	scoring := options.getScoring(modeChess)
+	score := scoreSequence(scoring, squares, input)
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		shown, entered := alignTokens(squares, input)
+
+		lines := []styledLine{
+			getCorrectLine(options, modeChess, shown, " "),
+			getDiffLine("entered: ", entered, " ", isSameAt(squares, input)),
+		}
+
+		if invalid > 0 {
+			lines = append(lines, plainLines([]string{
+				"some coordinates are not valid squares",
+			})...)
+		}
+
+		note, err = showFeedback(lines, score, len(squares))
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Interference: interference,
+		Score:        score,
+		Duration:     duration,
+		Count:        len(squares),
+		Note:         note,
+		Mode:         modeChess,
+		Scoring:      scoring,
+		Items:        squares,
+		Input:        input,
+	}, nil
+}
+
+// compareTokens is compare for string tokens, counts tokens recalled in
+// order until the first mistake.
+func compareTokens(validTokens, inputTokens []string) int {
+	score := 0
+	for index, token := range validTokens {
+		if index >= len(inputTokens) || inputTokens[index] != token {
+			break
+		}
+
+		score++
+	}
+
+	return score
+}
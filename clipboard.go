@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// summary of session copied to clipboard when --format is not specified
+const clipboardTemplate = "{date} {mode}: {tests} tests, score {avg_score}, " +
+	"accuracy {accuracy}%, {avg_duration} sec, fitness {fitness}"
+
+// getClipboardSummary returns line of the session to paste into journals,
+// it's rendered by --format template if it's specified.
+func getClipboardSummary(
+	session Session, template lineTemplate, fitness Fitness,
+) string {
+	if template == nil {
+		template, _ = parseTemplate(clipboardTemplate)
+	}
+
+	return template.render(getTemplateValues(session, fitness))
+}
+
+// copyToClipboard passes text to stdin of the clipboard command, the text is
+// sent to the terminal by OSC 52 escape sequence if there is no command, so
+// it's copied even over SSH.
+func copyToClipboard(text string, command string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		// stdout may be piped into a script, sequence is for the terminal
+		terminal, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("can't copy to clipboard: %s", err)
+		}
+		defer terminal.Close()
+
+		_, err = fmt.Fprintf(
+			terminal, "\x1b]52;c;%s\a",
+			base64.StdEncoding.EncodeToString([]byte(text)),
+		)
+
+		return err
+	}
+
+	copier := exec.Command(args[0], args[1:]...)
+	copier.Stdin = strings.NewReader(text)
+
+	output, err := copier.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"can't run clipboard command: %s: %s",
+			err, strings.TrimSpace(string(output)),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// width of chart of every profile, two charts are printed side by side
+const compareChartWidth = plotWidth / 2
+
+// profileMetrics is summary of scored sessions of a profile
+type profileMetrics struct {
+	sessions int
+	tests    int
+	score    float64
+	accuracy float64
+	span     int
+	rating   float64
+	streak   int
+	last     time.Time
+}
+
+// compareProfiles prints metrics of two profiles side by side, followed by
+// charts of their accuracy by days of the last sessions in the location.
+func compareProfiles(
+	file string, names []string, last int, location *time.Location,
+) error {
+	loaded, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	databases := []Database{}
+	for _, name := range names {
+		database := loaded.getProfile(name).getScored()
+		if len(database.Sessions) == 0 {
+			return fmt.Errorf("profile %s has no scored sessions", name)
+		}
+
+		databases = append(databases, database)
+	}
+
+	metrics := []profileMetrics{}
+	for _, database := range databases {
+		metrics = append(
+			metrics, getProfileMetrics(database, clock().In(location)),
+		)
+	}
+
+	fmt.Printf("%-10s %16s %16s\n", "", names[0], names[1])
+	for _, row := range []struct {
+		name   string
+		format func(profileMetrics) string
+	}{
+		{"sessions", func(metrics profileMetrics) string {
+			return formatInt(metrics.sessions)
+		}},
+		{"tests", func(metrics profileMetrics) string {
+			return formatInt(metrics.tests)
+		}},
+		{"average", func(metrics profileMetrics) string {
+			return formatFloat(metrics.score, 2)
+		}},
+		{"accuracy", func(metrics profileMetrics) string {
+			return formatFloat(metrics.accuracy*100, 1) + "%"
+		}},
+		{"best span", func(metrics profileMetrics) string {
+			return formatInt(metrics.span)
+		}},
+		{"rating", func(metrics profileMetrics) string {
+			return formatFloat(metrics.rating, 0)
+		}},
+		{"streak", func(metrics profileMetrics) string {
+			return formatInt(metrics.streak) + " days"
+		}},
+		{"last", func(metrics profileMetrics) string {
+			return metrics.last.Format("2006-01-02")
+		}},
+	} {
+		fmt.Printf(
+			"%-10s %16s %16s\n",
+			row.name+":", row.format(metrics[0]), row.format(metrics[1]),
+		)
+	}
+
+	charts := [][]string{}
+	for index, database := range databases {
+		days, accuracy := getDailyAccuracy(database, last, location)
+
+		chart := []string{
+			fmt.Sprintf("%s, %s - %s", names[index], days[0], days[len(days)-1]),
+		}
+		chart = append(chart, renderScaledBars(
+			accuracy, 100, compareChartWidth, plotHeight,
+		)...)
+
+		charts = append(charts, chart)
+	}
+
+	fmt.Printf("\naccuracy by days of the last %d sessions, %%:\n", last)
+	for _, line := range joinColumns(charts[0], charts[1]) {
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func getProfileMetrics(database Database, now time.Time) profileMetrics {
+	metrics := profileMetrics{sessions: len(database.Sessions)}
+
+	scores := 0
+	for _, session := range database.Sessions {
+		metrics.tests += getSessionTests(session)
+		metrics.accuracy += getSessionAccuracy(session)
+
+		for _, result := range session.Results {
+			scores += result.Score
+		}
+
+		if span := getMaxSpan(session); span > metrics.span {
+			metrics.span = span
+		}
+	}
+
+	if metrics.tests > 0 {
+		metrics.score = float64(scores) / float64(metrics.tests)
+	}
+
+	metrics.accuracy /= float64(metrics.sessions)
+	metrics.streak, _ = getStreak(database, now)
+	metrics.last = database.Sessions[len(database.Sessions)-1].Date
+
+	metrics.rating = initialRating
+	if ratings := getRatings(database); len(ratings) > 0 {
+		metrics.rating = ratings[len(ratings)-1].Rating
+	}
+
+	return metrics
+}
+
+// getDailyAccuracy returns days of the last sessions and average accuracy
+// of sessions of every day in percents, sessions should be sorted.
+func getDailyAccuracy(
+	database Database, last int, location *time.Location,
+) ([]string, []float64) {
+	sessions := database.Sessions
+	if len(sessions) > last {
+		sessions = sessions[len(sessions)-last:]
+	}
+
+	days := []string{}
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, session := range sessions {
+		day := session.Date.In(location).Format("2006-01-02")
+		if counts[day] == 0 {
+			days = append(days, day)
+		}
+
+		sums[day] += getSessionAccuracy(session) * 100
+		counts[day]++
+	}
+
+	if len(days) > compareChartWidth {
+		days = days[len(days)-compareChartWidth:]
+	}
+
+	values := []float64{}
+	for _, day := range days {
+		values = append(values, sums[day]/float64(counts[day]))
+	}
+
+	return days, values
+}
+
+// joinColumns puts lines of the right column after lines of the left one,
+// the left column is padded to its widest line.
+func joinColumns(left, right []string) []string {
+	width := 0
+	for _, line := range left {
+		width = int(math.Max(float64(width), float64(len([]rune(line)))))
+	}
+
+	lines := []string{}
+	for index := 0; index < len(left) || index < len(right); index++ {
+		var leftLine, rightLine string
+		if index < len(left) {
+			leftLine = left[index]
+		}
+
+		if index < len(right) {
+			rightLine = right[index]
+		}
+
+		padding := strings.Repeat(" ", width-len([]rune(leftLine))+4)
+		lines = append(lines, leftLine+padding+rightLine)
+	}
+
+	return lines
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// extensions of compressed files, both formats allow appending by
+// concatenating compressed streams
+const (
+	gzipExtension = ".gz"
+	zstdExtension = ".zst"
+)
+
+// compressedStream closes compressor before the file under it
+type compressedStream struct {
+	io.Reader
+	io.Writer
+
+	closers []func() error
+}
+
+func (stream *compressedStream) Close() error {
+	var result error
+	for _, close := range stream.closers {
+		err := close()
+		if result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+func isCompressed(file string) bool {
+	extension := filepath.Ext(file)
+
+	return extension == gzipExtension || extension == zstdExtension
+}
+
+// findCompressed returns compressed copy of the file if there is one, so
+// logs which are compressed by gzip or zstd in place are still used.
+func findCompressed(file string) string {
+	for _, extension := range []string{gzipExtension, zstdExtension} {
+		_, err := os.Stat(file + extension)
+		if err == nil {
+			return file + extension
+		}
+	}
+
+	return file
+}
+
+// openCompressed opens file for reading, it's decompressed while it's read
+// if its extension is .gz or .zst.
+func openCompressed(file string) (io.ReadCloser, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(file) {
+	case gzipExtension:
+		reader, err := gzip.NewReader(fd)
+		if err == io.EOF {
+			// empty file, nothing was appended yet
+			return fd, nil
+		}
+		if err != nil {
+			fd.Close()
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+
+		return &compressedStream{
+			Reader:  reader,
+			closers: []func() error{reader.Close, fd.Close},
+		}, nil
+
+	case zstdExtension:
+		reader, err := zstd.NewReader(fd)
+		if err != nil {
+			fd.Close()
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+
+		return &compressedStream{
+			Reader: reader,
+			closers: []func() error{
+				func() error {
+					reader.Close()
+					return nil
+				},
+				fd.Close,
+			},
+		}, nil
+	}
+
+	return fd, nil
+}
+
+// createCompressed truncates or creates the file for writing, content is
+// compressed while it's written if extension of the file is .gz or .zst.
+func createCompressed(file string) (io.WriteCloser, error) {
+	return openCompressedWriter(file, os.O_TRUNC)
+}
+
+// appendCompressed opens the file for appending, compressed content is
+// appended as separate stream, which is read as continuation of the file.
+func appendCompressed(file string) (io.WriteCloser, error) {
+	return openCompressedWriter(file, os.O_APPEND)
+}
+
+func openCompressedWriter(file string, flag int) (io.WriteCloser, error) {
+	fd, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|flag, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return compressInto(fd, file)
+}
+
+// compressInto wraps opened file with compressor chosen by extension of the
+// name, which is not the name of the opened file if it's temporary. The
+// file is closed with the compressor.
+func compressInto(fd *os.File, name string) (io.WriteCloser, error) {
+	switch filepath.Ext(name) {
+	case gzipExtension:
+		writer := gzip.NewWriter(fd)
+
+		return &compressedStream{
+			Writer:  writer,
+			closers: []func() error{writer.Close, fd.Close},
+		}, nil
+
+	case zstdExtension:
+		writer, err := zstd.NewWriter(fd)
+		if err != nil {
+			fd.Close()
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		return &compressedStream{
+			Writer:  writer,
+			closers: []func() error{writer.Close, fd.Close},
+		}, nil
+	}
+
+	return fd, nil
+}
+
+// writeCompressed writes whole content into the file, compressing it by
+// extension.
+func writeCompressed(file string, content []byte) error {
+	writer, err := createCompressed(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// isCompressedDatabase tells if the database is compressed JSON, databases
+// named like compressed ones which previous versions imported into SQLite
+// stay SQLite.
+func isCompressedDatabase(file string) (bool, error) {
+	if !isCompressed(file) {
+		return false, nil
+	}
+
+	ok, err := isSQLite(file)
+
+	return !ok, err
+}
+
+// readCompressedDatabase decodes JSON database while it's decompressed,
+// missing file means empty database.
+func readCompressedDatabase(file string) (Database, error) {
+	fd, err := openCompressed(file)
+	if os.IsNotExist(err) {
+		return newDatabase(), nil
+	}
+	if err != nil {
+		return Database{}, err
+	}
+	defer fd.Close()
+
+	database, _, err := decodeDatabaseStream(bufio.NewReader(fd))
+	if err != nil {
+		return Database{}, fmt.Errorf("can't decode database %s: %s", file, err)
+	}
+
+	return database, nil
+}
+
+// writeCompressedDatabase encodes JSON database while it's compressed into
+// temporary file, which replaces the database when it's complete.
+func writeCompressedDatabase(file string, database Database) error {
+	database.Version = databaseVersion
+
+	return writeFileBy(file, func(writer io.Writer) error {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "    ")
+
+		return encoder.Encode(database)
+	})
+}
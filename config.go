@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+type Config struct {
+	Scripts map[string]Script `toml:"scripts"`
+	Plan    Plan              `toml:"plan"`
+
+	// minimal gap between scored sessions, sessions started earlier are
+	// recorded as practice
+	Cooldown Duration `toml:"cooldown"`
+
+	// values of command line options used when they are not specified
+	Defaults Defaults `toml:"defaults"`
+
+	Retention Retention `toml:"retention"`
+	Theme     Theme     `toml:"theme"`
+
+	// snapshots of the database taken when sessions are finished
+	Backups Backups `toml:"backups"`
+
+	// weights of components of memory fitness index
+	Fitness Fitness `toml:"fitness"`
+
+	// micro-breaks between tests of long sessions
+	Breaks Breaks `toml:"breaks"`
+
+	// schedule of notifications sent by short remind
+	Reminders Reminders `toml:"reminders"`
+
+	// endpoint results of every session are posted to
+	Webhook Webhook `toml:"webhook"`
+
+	// bot running tests in Discord, served by serve --discord
+	Discord Discord `toml:"discord"`
+
+	// bot running tests in Matrix rooms, served by serve --matrix
+	Matrix Matrix `toml:"matrix"`
+
+	// users of REST API served by serve --api
+	API API `toml:"api"`
+
+	// movement keys of grid modes and scrolling
+	Keys Keys `toml:"keys"`
+
+	// skill of bot opponent played with --opponent
+	Opponent Opponent `toml:"opponent"`
+
+	// locale of numbers in stats and reports, like "de", or "auto" to take
+	// it from environment
+	Locale string `toml:"locale"`
+
+	// time zone sessions are bucketed into days in, like "Europe/Berlin",
+	// local zone of the system by default
+	Timezone string `toml:"timezone"`
+
+	// weekday weeks start from, monday by default
+	WeekStart string `toml:"week_start"`
+
+	// modes whose rules are not shown before their first test, modes are
+	// added by pressing d on their rules
+	HiddenRules []string `toml:"hidden_rules"`
+
+	// level of records written to the internal log
+	LogLevel string `toml:"log_level"`
+
+	// command which records speech of the user and prints its transcript,
+	// used with --speech
+	SpeechCommand string `toml:"speech_command"`
+
+	// command which pronounces digit given as the last argument in spoken
+	// mode
+	SpeakCommand string `toml:"speak_command"`
+
+	// command to convert recorded session into GIF, invoked with paths of
+	// the cast and the GIF
+	GifCommand string `toml:"gif_command"`
+
+	// command which puts its stdin on the clipboard, like pbcopy, session
+	// summary is copied by OSC 52 sequence if it's not set
+	ClipboardCommand string `toml:"clipboard_command"`
+}
+
+// named sequence of blocks, each block is a series of tests with the same
+// parameters
+type Script struct {
+	Blocks []Block `toml:"blocks"`
+}
+
+type Block struct {
+	Mode   string `toml:"mode"`
+	Count  int    `toml:"count"`
+	Trials int    `toml:"trials"`
+
+	// seconds to show numbers for, zero means until Enter is pressed
+	Exposure float64 `toml:"exposure"`
+}
+
+// safeMode makes loadConfig ignore config file, so the user can recover
+// from broken config with built-in defaults
+var safeMode bool
+
+// loadConfig reads config from specified file, missing file means empty
+// config. Returns hash of config contents to track changes.
+func loadConfig(file string) (Config, string, error) {
+	config := Config{}
+
+	if safeMode {
+		return config, "", nil
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, "", nil
+		}
+
+		return Config{}, "", err
+	}
+
+	// hash doesn't depend on line endings, which may be changed by git
+	content = normalizeText(content)
+
+	_, err = toml.Decode(string(content), &config)
+	if err != nil {
+		return Config{}, "", fmt.Errorf("can't parse config %s: %s", file, err)
+	}
+
+	for name, script := range config.Scripts {
+		err = script.validate()
+		if err != nil {
+			return Config{}, "", fmt.Errorf(
+				"%s: script %q: %s", file, name, err,
+			)
+		}
+	}
+
+	if config.Plan.isDefined() {
+		err = config.Plan.validate()
+		if err != nil {
+			return Config{}, "", fmt.Errorf("%s: plan: %s", file, err)
+		}
+	}
+
+	err = config.Webhook.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: webhook: %s", file, err)
+	}
+
+	err = config.Backups.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: backups: %s", file, err)
+	}
+
+	err = config.Discord.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: discord: %s", file, err)
+	}
+
+	err = config.Matrix.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: matrix: %s", file, err)
+	}
+
+	err = config.API.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: api: %s", file, err)
+	}
+
+	err = config.Keys.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: keys: %s", file, err)
+	}
+
+	err = config.Breaks.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: breaks: %s", file, err)
+	}
+
+	err = config.Opponent.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: opponent: %s", file, err)
+	}
+
+	err = config.Reminders.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: reminders: %s", file, err)
+	}
+
+	err = config.Fitness.validate()
+	if err != nil {
+		return Config{}, "", fmt.Errorf("%s: fitness: %s", file, err)
+	}
+
+	if config.Locale != "" {
+		_, err = getNumberFormat(config.Locale)
+		if err != nil {
+			return Config{}, "", fmt.Errorf("%s: %s", file, err)
+		}
+	}
+
+	if config.Timezone != "" {
+		_, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			return Config{}, "", fmt.Errorf(
+				"%s: unknown timezone %q", file, config.Timezone,
+			)
+		}
+	}
+
+	if config.WeekStart != "" {
+		_, err = parseWeekday(config.WeekStart)
+		if err != nil {
+			return Config{}, "", fmt.Errorf("%s: %s", file, err)
+		}
+	}
+
+	for _, mode := range config.HiddenRules {
+		if indexOf(modes, mode) < 0 {
+			return Config{}, "", fmt.Errorf(
+				"%s: unknown mode %q in hidden_rules", file, mode,
+			)
+		}
+	}
+
+	if config.LogLevel != "" && !isKnownLevel(config.LogLevel) {
+		return Config{}, "", fmt.Errorf(
+			"%s: unknown log_level %q, expected one of %v",
+			file, config.LogLevel, levels,
+		)
+	}
+
+	hash := sha256.Sum256(content)
+
+	return config, hex.EncodeToString(hash[:]), nil
+}
+
+func (script Script) validate() error {
+	if len(script.Blocks) == 0 {
+		return fmt.Errorf("no blocks defined")
+	}
+
+	for index, block := range script.Blocks {
+		switch {
+		case block.Mode != "" && !isKnownMode(block.Mode):
+			return fmt.Errorf("block #%d: unknown mode %q", index+1, block.Mode)
+		case block.Count <= 0:
+			return fmt.Errorf("block #%d: count should be positive", index+1)
+		case block.Trials <= 0:
+			return fmt.Errorf("block #%d: trials should be positive", index+1)
+		case block.Exposure < 0:
+			return fmt.Errorf("block #%d: exposure can't be negative", index+1)
+		}
+	}
+
+	return nil
+}
+
+// trackConfigChanges logs config_changed event if config differs from the
+// one used last time.
+func trackConfigChanges(database string, file string, hash string) {
+	events, err := readEvents(getEventsFile(database))
+	if err != nil {
+		return
+	}
+
+	previous := ""
+	for _, event := range events {
+		if event.Type == eventConfigChanged {
+			previous, _ = event.Details["hash"].(string)
+		}
+	}
+
+	if hash != previous {
+		logEvent(database, eventConfigChanged, map[string]interface{}{
+			"file": file,
+			"hash": hash,
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// getConflictPatterns returns patterns of copies of the database which are
+// left by file sync tools when it's changed on two devices at once:
+// Syncthing adds date and device before extension, Dropbox and Nextcloud
+// add "conflicted copy" in parentheses.
+func getConflictPatterns(file string) []*regexp.Regexp {
+	extension := filepath.Ext(file)
+	stem := regexp.QuoteMeta(
+		strings.TrimSuffix(filepath.Base(file), extension),
+	)
+	extension = regexp.QuoteMeta(extension)
+
+	return []*regexp.Regexp{
+		regexp.MustCompile(
+			`^` + stem + `\.sync-conflict-\d{8}-\d{6}-[A-Z0-9]+` +
+				extension + `$`,
+		),
+		regexp.MustCompile(
+			`^` + stem + ` \([^)]*conflicted copy[^)]*\)` + extension + `$`,
+		),
+	}
+}
+
+// getConflicts returns sync conflict copies of the database next to it.
+func getConflicts(file string) ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Dir(file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	conflicts := []string{}
+	for _, info := range files {
+		for _, pattern := range getConflictPatterns(file) {
+			if pattern.MatchString(info.Name()) {
+				conflicts = append(
+					conflicts, filepath.Join(filepath.Dir(file), info.Name()),
+				)
+				break
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// warnConflicts tells about sync conflict copies of the database, sessions
+// saved in them on another device are not seen until they are merged.
+func warnConflicts(file string) {
+	conflicts, err := getConflicts(file)
+	if err != nil {
+		log.warn("can't look for sync conflicts", Fields{"error": err})
+		return
+	}
+
+	if len(conflicts) > 0 {
+		fmt.Fprintf(
+			os.Stderr,
+			"%d sync conflict copies of %s found, "+
+				"merge them by short merge\n",
+			len(conflicts), file,
+		)
+	}
+}
+
+// mergeConflicts adds sessions which are only in sync conflict copies to
+// the database, every copy is merged and removed after confirmation. With
+// dryRun missing sessions are only printed.
+func mergeConflicts(file string, dryRun bool) error {
+	release, err := acquireLock(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	conflicts, err := getConflicts(file)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Printf("no sync conflict copies of %s found\n", file)
+		return nil
+	}
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	input := bufio.NewReader(os.Stdin)
+
+	for _, conflict := range conflicts {
+		copied, err := readConflict(conflict)
+		if err != nil {
+			return err
+		}
+
+		missing := getMissingSessions(database, copied)
+
+		fmt.Printf(
+			"%s: %d sessions, %d of them are not in the database\n",
+			conflict, len(copied.Sessions), len(missing),
+		)
+		for _, session := range missing {
+			fmt.Printf("+ %s\n", formatHistorySession(session))
+		}
+
+		if dryRun {
+			continue
+		}
+
+		prompt := "merge them and remove the copy? [y/N] "
+		if len(missing) == 0 {
+			prompt = "nothing to merge, remove the copy? [y/N] "
+		}
+
+		confirmed, err := askConfirmation(input, prompt)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			continue
+		}
+
+		if len(missing) > 0 {
+			database.Sessions = append(database.Sessions, missing...)
+			sort.SliceStable(database.Sessions, func(i, j int) bool {
+				return database.Sessions[i].Date.Before(
+					database.Sessions[j].Date,
+				)
+			})
+
+			err = saveDatabase(file, database)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = os.Remove(conflict)
+		if err != nil {
+			return err
+		}
+
+		log.info("sync conflict merged", Fields{
+			"database": file, "conflict": conflict, "sessions": len(missing),
+		})
+	}
+
+	return nil
+}
+
+// readConflict reads copy of the database without importing it, copies of
+// databases of previous versions are JSON.
+func readConflict(file string) (Database, error) {
+	ok, err := isSQLite(file)
+	if err != nil {
+		return Database{}, err
+	}
+
+	if ok {
+		return loadDatabase(file)
+	}
+
+	fd, err := openCompressed(file)
+	if err != nil {
+		return Database{}, err
+	}
+	defer fd.Close()
+
+	database, _, err := decodeDatabaseStream(bufio.NewReader(fd))
+	if err != nil {
+		return Database{}, fmt.Errorf("can't decode %s: %s", file, err)
+	}
+
+	return database, nil
+}
+
+// getMissingSessions returns sessions of the copy which are not in the
+// database.
+func getMissingSessions(database Database, copied Database) []Session {
+	known := map[string]bool{}
+	for _, session := range database.Sessions {
+		known[getSessionKey(session)] = true
+	}
+
+	missing := []Session{}
+	for _, session := range copied.Sessions {
+		if !known[getSessionKey(session)] {
+			missing = append(missing, session)
+		}
+	}
+
+	return missing
+}
+
+// getSessionKey identifies session by the moment it's started at and its
+// profile.
+func getSessionKey(session Session) string {
+	return session.Date.UTC().Format(time.RFC3339Nano) + " " + session.Profile
+}
+
+func askConfirmation(input *bufio.Reader, prompt string) (bool, error) {
+	fmt.Print(prompt)
+
+	answer, err := input.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGetConflicts(t *testing.T) {
+	dir := t.TempDir()
+
+	conflicts := []string{
+		"short.sync-conflict-20240305-184200-ABCDEF1.db",
+		"short (alice's conflicted copy 2024-03-05).db",
+	}
+
+	others := []string{
+		"short.db",
+		"short.db.lock",
+		"other.sync-conflict-20240305-184200-ABCDEF1.db",
+		"short.sync-conflict-2024-ABCDEF1.db",
+		"short (copy).db",
+	}
+
+	for _, name := range append(append([]string{}, conflicts...), others...) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := getConflicts(filepath.Join(dir, "short.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{}
+	for _, name := range conflicts {
+		expected = append(expected, filepath.Join(dir, name))
+	}
+
+	sort.Strings(found)
+	sort.Strings(expected)
+
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected conflicts %q, got %q", expected, found)
+	}
+}
+
+func TestMergeConflictSessions(t *testing.T) {
+	date := time.Date(2024, 3, 5, 18, 42, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	database := Database{Sessions: []Session{
+		{Date: date},
+		{Date: date.Add(time.Hour), Profile: "alice"},
+	}}
+
+	copied := Database{Sessions: []Session{
+		// the same sessions, one of them is dated in another zone
+		{Date: date},
+		{Date: date.Add(time.Hour).In(moscow), Profile: "alice"},
+
+		// session of another profile started at the same moment
+		{Date: date.Add(time.Hour), Profile: "bob"},
+
+		// session saved on another device only
+		{Date: date.Add(2 * time.Hour)},
+	}}
+
+	// copies of previous versions are JSON
+	file := filepath.Join(t.TempDir(), "short.sync-conflict.db")
+
+	content, err := encodeDatabase(copied)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(file, content, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := readConflict(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing := getMissingSessions(database, read)
+	if len(missing) != 2 {
+		t.Fatalf("expected 2 missing sessions, got %d", len(missing))
+	}
+
+	bob := missing[0]
+	if bob.Profile != "bob" || !bob.Date.Equal(date.Add(time.Hour)) {
+		t.Errorf("session of another profile is not merged: %+v", missing[0])
+	}
+
+	if !missing[1].Date.Equal(date.Add(2 * time.Hour)) {
+		t.Errorf("new session is not merged: %+v", missing[1])
+	}
+}
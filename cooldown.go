@@ -0,0 +1,26 @@
+package main
+
+import (
+	"time"
+)
+
+// getCooldownEnd returns time when the cooldown after the last scored
+// session ends, zero time if there is no cooldown.
+func getCooldownEnd(database Database, cooldown time.Duration) time.Time {
+	if cooldown == 0 {
+		return time.Time{}
+	}
+
+	var last time.Time
+	for _, session := range database.Sessions {
+		if !session.Practice && session.Date.After(last) {
+			last = session.Date
+		}
+	}
+
+	if last.IsZero() {
+		return time.Time{}
+	}
+
+	return last.Add(cooldown)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// count of the latest events of the database included into crash report
+const crashReportEvents = 20
+
+// keys of config and log fields whose values are secrets, they are not
+// written to crash reports and logs, webhook URLs often carry tokens too
+var secretKeys = []string{"secret", "token", "access_token", "tokens", "url"}
+
+const redacted = "<redacted>"
+
+// recoverCrash restores the terminal after panic and saves crash report next
+// to the config, returned error tells where the report is saved.
+func recoverCrash(
+	value interface{}, stack []byte, database, config string,
+) error {
+	if screen.IsInit() {
+		screen.Close()
+	}
+
+	log.error("crashed", Fields{"panic": fmt.Sprint(value)})
+
+	path, err := writeCrashReport(value, stack, database, config)
+	if err != nil {
+		return fmt.Errorf(
+			"short crashed: %v\n%s\ncan't save crash report: %s",
+			value, stack, err,
+		)
+	}
+
+	return fmt.Errorf(
+		"short crashed: %v\ncrash report saved to %s", value, path,
+	)
+}
+
+func writeCrashReport(
+	value interface{}, stack []byte, database, config string,
+) (string, error) {
+	now := time.Now()
+
+	report := &strings.Builder{}
+	fmt.Fprintf(report, "time: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(report, "args: %q\n", os.Args)
+	fmt.Fprintf(report, "panic: %v\n\n%s\n", value, stack)
+
+	fmt.Fprintf(report, "last events of %s:\n", database)
+	events, err := readEvents(getEventsFile(database))
+	if err != nil {
+		fmt.Fprintf(report, "can't read events: %s\n", err)
+	}
+
+	if len(events) > crashReportEvents {
+		events = events[len(events)-crashReportEvents:]
+	}
+
+	for _, event := range events {
+		fmt.Fprintln(report, formatEvent(event))
+	}
+
+	fmt.Fprintf(report, "\nconfig %s:\n", config)
+	content, err := ioutil.ReadFile(config)
+	switch {
+	case os.IsNotExist(err):
+		fmt.Fprintln(report, "not found")
+	case err != nil:
+		fmt.Fprintf(report, "can't read config: %s\n", err)
+	default:
+		err = writeRedactedConfig(report, content)
+		if err != nil {
+			fmt.Fprintf(report, "can't parse config, it's skipped: %s\n", err)
+		}
+	}
+
+	dir := filepath.Dir(config)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(
+		dir, "crash-"+now.Format("20060102-150405")+".txt",
+	)
+
+	return path, ioutil.WriteFile(path, []byte(report.String()), 0600)
+}
+
+// writeRedactedConfig writes the config with values of secret keys
+// replaced, so crash reports can be shared.
+func writeRedactedConfig(writer io.Writer, content []byte) error {
+	table := map[string]interface{}{}
+
+	_, err := toml.Decode(string(content), &table)
+	if err != nil {
+		return err
+	}
+
+	redactSecrets(table)
+
+	return toml.NewEncoder(writer).Encode(table)
+}
+
+// redactSecrets replaces values of secret keys of the table and its nested
+// tables.
+func redactSecrets(table map[string]interface{}) {
+	for key, value := range table {
+		if indexOf(secretKeys, key) >= 0 {
+			table[key] = redacted
+			continue
+		}
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			redactSecrets(value)
+		case []map[string]interface{}:
+			for _, item := range value {
+				redactSecrets(item)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"time"
+)
+
+// bests are taken from sessions of this period
+const dashboardRecentDays = 30
+
+// profileSummary is row of the dashboard
+type profileSummary struct {
+	Name     string
+	Sessions int
+	Streak   int
+	Today    bool
+	Rating   int
+
+	// the best session accuracy and the longest fully recalled test of
+	// recent days
+	BestAccuracy int
+	BestCount    int
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>short</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 1em; border-bottom: 1px solid #ccc; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>short</h1>
+<table>
+<tr>
+<th>profile</th><th>sessions</th><th>streak</th><th>rating</th>
+<th>best accuracy, {{.Days}} days</th><th>longest recall, {{.Days}} days</th>
+</tr>
+{{range .Profiles}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Sessions}}</td>
+<td>{{.Streak}}{{if .Today}} &#10003;{{end}}</td>
+<td>{{.Rating}}</td>
+<td>{{.BestAccuracy}}%</td>
+<td>{{.BestCount}}</td>
+</tr>
+{{end}}
+</table>
+<p>updated {{.Updated}}</p>
+</body>
+</html>
+`))
+
+// serveDashboard serves read-only page comparing all profiles of the
+// database, which is read on every request.
+func serveDashboard(file, config, address string) error {
+	settings, _, err := loadConfig(config)
+	if err != nil {
+		return err
+	}
+
+	location := settings.getLocation()
+
+	http.HandleFunc("/", func(
+		writer http.ResponseWriter, request *http.Request,
+	) {
+		if request.URL.Path != "/" {
+			http.NotFound(writer, request)
+			return
+		}
+
+		database, err := loadDatabase(file)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		now := clock().In(location)
+
+		summaries := []profileSummary{}
+		for _, name := range getProfileNames(database) {
+			summaries = append(
+				summaries,
+				summarizeProfile(name, database.getProfile(name), now),
+			)
+		}
+
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		err = dashboardTemplate.Execute(writer, map[string]interface{}{
+			"Days":     dashboardRecentDays,
+			"Profiles": summaries,
+			"Updated":  now.Format("2006-01-02 15:04"),
+		})
+		if err != nil {
+			log.warn("can't render dashboard", Fields{"error": err})
+		}
+	})
+
+	log.info("serving dashboard", Fields{"address": address})
+
+	return http.ListenAndServe(address, nil)
+}
+
+func summarizeProfile(
+	name string, database Database, now time.Time,
+) profileSummary {
+	summary := profileSummary{Name: name}
+
+	database = database.getScored()
+
+	summary.Sessions = len(database.Sessions)
+	summary.Streak, summary.Today = getStreak(database, now)
+
+	ratings := getRatings(database)
+	summary.Rating = int(initialRating)
+	if len(ratings) > 0 {
+		summary.Rating = int(math.Round(ratings[len(ratings)-1].Rating))
+	}
+
+	since := now.AddDate(0, 0, -dashboardRecentDays)
+	for _, session := range database.Sessions {
+		if session.Date.Before(since) {
+			continue
+		}
+
+		accuracy := int(getSessionAccuracy(session) * 100)
+		if accuracy > summary.BestAccuracy {
+			summary.BestAccuracy = accuracy
+		}
+
+		for _, result := range session.Results {
+			if result.Score == result.Count &&
+				result.Count > summary.BestCount {
+				summary.BestCount = result.Count
+			}
+		}
+	}
+
+	return summary
+}
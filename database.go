@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode"
+)
+
+// version of database schema, should be bumped on every incompatible change
+const databaseVersion = 3
+
+type Database struct {
+	Version  int       `json:"version"`
+	Sessions []Session `json:"sessions"`
+}
+
+// test session as stored in database
+type Session struct {
+	Date        time.Time `json:"date"`
+	AvgDuration float64   `json:"avg_duration"`
+	TotalScore  int       `json:"total_score"`
+	Results     []Result  `json:"results"`
+
+	// how tests of different count of numbers were ordered, empty if all
+	// tests had the same count
+	Schedule string `json:"schedule,omitempty"`
+
+	// name of the script the session was run by
+	Script string `json:"script,omitempty"`
+
+	// session parameters were set by progressive overload plan
+	Planned bool `json:"planned,omitempty"`
+
+	// name of memory sport discipline preset the session was run by
+	Preset string `json:"preset,omitempty"`
+
+	// code of challenge the session was played by
+	Challenge string `json:"challenge,omitempty"`
+
+	// seed of random numbers items were generated from, set by --seed
+	Seed *int64 `json:"seed,omitempty"`
+
+	// session was stopped by Esc, Ctrl+C or termination signal, only its
+	// completed tests are recorded
+	Aborted bool `json:"aborted,omitempty"`
+
+	// changes of difficulty chosen between blocks by --adjust-blocks
+	Adjustments []Adjustment `json:"adjustments,omitempty"`
+
+	// multiplier of time limits of the session, zero means no scaling
+	TimeScale float64 `json:"time_scale,omitempty"`
+
+	// outcome of session played against bot opponent
+	Match *Match `json:"match,omitempty"`
+
+	// practice sessions are not counted in statistics
+	Practice bool `json:"practice,omitempty"`
+
+	// labels attached by the user in history
+	Tags []string `json:"tags,omitempty"`
+
+	// person who played the session, sessions recorded before profiles
+	// belong to the default one
+	Profile string `json:"profile,omitempty"`
+
+	// aggregates of results removed by retention policy
+	Pruned *Aggregate `json:"pruned,omitempty"`
+
+	Environment *Environment `json:"environment,omitempty"`
+}
+
+func newDatabase() Database {
+	return Database{Version: databaseVersion, Sessions: []Session{}}
+}
+
+// loadDatabase reads database from specified SQLite file, JSON databases of
+// previous versions are imported first, missing file means empty database.
+// Databases named *.gz or *.zst are kept as compressed JSON.
+func loadDatabase(file string) (Database, error) {
+	compressed, err := isCompressedDatabase(file)
+	if err != nil {
+		return Database{}, err
+	}
+
+	if compressed {
+		return readCompressedDatabase(file)
+	}
+
+	err = ensureSQLite(file)
+	if err != nil {
+		return Database{}, err
+	}
+
+	_, err = os.Stat(file)
+	if os.IsNotExist(err) {
+		return newDatabase(), nil
+	}
+
+	db, err := openSQLite(file)
+	if err != nil {
+		return Database{}, err
+	}
+	defer db.Close()
+
+	sessions, err := readSessions(db)
+	if err != nil {
+		return Database{}, fmt.Errorf("can't read database %s: %s", file, err)
+	}
+
+	return Database{Version: databaseVersion, Sessions: sessions}, nil
+}
+
+// decodeDatabase decodes database of any known format, returning notes about
+// what had to be inferred or defaulted if the database was in legacy format.
+func decodeDatabase(content []byte) (Database, []string, error) {
+	content = bytes.TrimSpace(normalizeText(content))
+	if len(content) == 0 {
+		return newDatabase(), nil, nil
+	}
+
+	if isLegacyDatabase(content) {
+		return decodeLegacyDatabase(content)
+	}
+
+	database := newDatabase()
+	err := json.Unmarshal(content, &database)
+	if err != nil {
+		return Database{}, nil, err
+	}
+
+	if database.Version > databaseVersion {
+		return Database{}, nil, fmt.Errorf(
+			"unsupported database version %d, latest known is %d",
+			database.Version, databaseVersion,
+		)
+	}
+
+	return database, nil, nil
+}
+
+// decodeDatabaseStream decodes database like decodeDatabase while it's
+// read, only legacy databases, which are small, are read at once.
+func decodeDatabaseStream(reader *bufio.Reader) (Database, []string, error) {
+	first, err := peekDatabase(reader)
+	if err != nil {
+		return Database{}, nil, err
+	}
+
+	switch first {
+	case 0:
+		return newDatabase(), nil, nil
+	case '[':
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return Database{}, nil, err
+		}
+
+		return decodeLegacyDatabase(content)
+	}
+
+	database := newDatabase()
+	err = json.NewDecoder(reader).Decode(&database)
+	if err != nil {
+		return Database{}, nil, err
+	}
+
+	if database.Version > databaseVersion {
+		return Database{}, nil, fmt.Errorf(
+			"unsupported database version %d, latest known is %d",
+			database.Version, databaseVersion,
+		)
+	}
+
+	return database, nil, nil
+}
+
+// peekDatabase skips byte order mark and spaces, returns the first symbol
+// of the database without reading it, or zero if the database is empty.
+func peekDatabase(reader *bufio.Reader) (byte, error) {
+	mark, err := reader.Peek(len(byteOrderMark))
+	if err == nil && string(mark) == byteOrderMark {
+		reader.Discard(len(byteOrderMark))
+	}
+
+	for {
+		symbol, err := reader.Peek(1)
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if !unicode.IsSpace(rune(symbol[0])) {
+			return symbol[0], nil
+		}
+
+		reader.Discard(1)
+	}
+}
+
+func encodeDatabase(database Database) ([]byte, error) {
+	database.Version = databaseVersion
+
+	content, err := json.MarshalIndent(database, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
+
+// saveDatabase replaces all sessions of the database in one transaction,
+// so the database is never left half-written. Sessions should be loaded
+// while the lock is held, so sessions appended meanwhile are not lost.
+func saveDatabase(file string, database Database) error {
+	release, err := lockDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	compressed, err := isCompressedDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	if compressed {
+		return writeCompressedDatabase(file, database)
+	}
+
+	err = ensureSQLite(file)
+	if err != nil {
+		return err
+	}
+
+	db, err := openSQLite(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return replaceSessions(db, database.Sessions)
+}
+
+// writeFile replaces contents of the file using temporary file, so the file
+// is never left half-written.
+func writeFile(file string, content []byte) error {
+	return writeFileBy(file, func(writer io.Writer) error {
+		_, err := writer.Write(content)
+		return err
+	})
+}
+
+// writeFileBy replaces contents of the file like writeFile, contents are
+// written by the function while they are compressed.
+func writeFileBy(file string, write func(io.Writer) error) error {
+	temp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		return err
+	}
+
+	// content is compressed if the file is named like compressed one
+	writer, err := compressInto(temp, file)
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	err = write(writer)
+	if err == nil {
+		err = temp.Chmod(0600)
+	}
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), file)
+}
+
+type Aggregate struct {
+	Mode     string  `json:"mode"`
+	Tests    int     `json:"tests"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// getUntagged returns database without sessions tagged by the tag.
+func (database Database) getUntagged(tag string) Database {
+	sessions := []Session{}
+	for _, session := range database.Sessions {
+		if !session.hasTag(tag) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	database.Sessions = sessions
+
+	return database
+}
+
+// getScored returns database without practice sessions.
+func (database Database) getScored() Database {
+	sessions := []Session{}
+	for _, session := range database.Sessions {
+		if !session.Practice {
+			sessions = append(sessions, session)
+		}
+	}
+
+	database.Sessions = sessions
+
+	return database
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// formats of dates in date mode, all of them have no spaces, so dates can
+// be entered separated by spaces
+var dateFormats = []string{
+	"2006-01-02",
+	"02.01.2006",
+	"01/02/2006",
+	"2-Jan-2006",
+}
+
+const (
+	minDateYear = 1950
+	maxDateYear = 2049
+)
+
+func generateDates(count int) []time.Time {
+	from := time.Date(minDateYear, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(maxDateYear+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := int(to.Sub(from).Hours() / 24)
+
+	dates := []time.Time{}
+	for i := 0; i < count; i++ {
+		dates = append(dates, from.AddDate(0, 0, randomInt(days)))
+	}
+
+	return dates
+}
+
+// parseRecalledDate parses date in preferred format or in any other known
+// format, so the user may enter date the way it's easier for them.
+func parseRecalledDate(text string, preferred string) (time.Time, bool) {
+	for _, format := range append([]string{preferred}, dateFormats...) {
+		date, err := time.Parse(format, text)
+		if err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func runDatesTest(options Options, test Test) (Result, error) {
+	format := dateFormats[randomInt(len(dateFormats))]
+	dates := generateDates(test.Count)
+
+	items := []string{}
+	for _, date := range dates {
+		items = append(items, date.Format(format))
+	}
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(strings.Join(items, "  "), height/2)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	text, err := readLine("", height/2-1)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input := strings.Fields(text)
+
+	// every date is scored on its own, one forgotten date doesn't zero the
+	// rest of the test
+	correct := make([]bool, len(input))
+
+	score := 0
+	for index, date := range dates {
+		if index >= len(input) {
+			break
+		}
+
+		recalled, ok := parseRecalledDate(input[index], format)
+		if ok && recalled.Equal(date) {
+			correct[index] = true
+			score++
+		}
+	}
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, modeDates, items, "  "),
+				getDiffLine("entered: ", input, "  ", func(index int) bool {
+					return correct[index]
+				}),
+			},
+			score, len(dates),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Interference: interference,
+		Score:        score,
+		Duration:     duration,
+		Count:        len(dates),
+		Note:         note,
+		Mode:         modeDates,
+		Items:        items,
+		Input:        input,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Defaults are values of command line options used when options are not
+// specified, keys are names of options without dashes, like c or mode
+type Defaults map[string]interface{}
+
+// options which can't have defaults, config is read by path of --config and
+// --safe ignores it
+var fixedOptions = []string{
+	"--config", "--safe", "--help", "-h", "--version",
+}
+
+// option line of usage, like "    -c <count>   show specified count"
+var usageOption = regexp.MustCompile(`^    (-\S+)( <[^>]+>)?\s+(.*)$`)
+
+// applyDefaults sets values of options from defaults section of config,
+// options specified in arguments override them.
+func applyDefaults(args map[string]interface{}, argv []string) error {
+	config, _, err := loadConfig(expandHome(args["--config"].(string)))
+	if err != nil {
+		return err
+	}
+
+	given := getGivenOptions(args, argv)
+
+	names := []string{}
+	for name := range config.Defaults {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		option := getOptionName(name)
+
+		current, ok := args[option]
+		if !ok || indexOf(fixedOptions, option) >= 0 {
+			return fmt.Errorf("defaults: unknown option %q", name)
+		}
+
+		if given[option] {
+			continue
+		}
+
+		value := config.Defaults[name]
+
+		switch current.(type) {
+		case bool:
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf(
+					"defaults: %s is flag, expected true or false", name,
+				)
+			}
+
+			args[option] = flag
+
+		case string, nil:
+			switch value.(type) {
+			case string, int64, float64:
+				args[option] = fmt.Sprint(value)
+			default:
+				return fmt.Errorf(
+					"defaults: %s expects string or number, got %v",
+					name, value,
+				)
+			}
+
+		default:
+			return fmt.Errorf("defaults: %s can't have default", name)
+		}
+	}
+
+	return nil
+}
+
+// getOptionName returns option named in defaults, single letter options
+// are short ones.
+func getOptionName(name string) string {
+	name = strings.TrimLeft(name, "-")
+	if len(name) == 1 {
+		return "-" + name
+	}
+
+	return "--" + name
+}
+
+// getGivenOptions returns options specified in arguments, long options may
+// be abbreviated by unique prefix, short ones may be followed by value.
+func getGivenOptions(
+	args map[string]interface{}, argv []string,
+) map[string]bool {
+	given := map[string]bool{}
+	for _, arg := range argv {
+		switch {
+		case arg == "--":
+			return given
+
+		case strings.HasPrefix(arg, "--"):
+			name := strings.SplitN(arg, "=", 2)[0]
+			if _, ok := args[name]; ok {
+				given[name] = true
+				continue
+			}
+
+			for option := range args {
+				if strings.HasPrefix(option, name) {
+					given[option] = true
+				}
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			given[arg[:2]] = true
+		}
+	}
+
+	return given
+}
+
+// initConfig writes template of config with commented defaults of every
+// option, existing config is not overwritten.
+func initConfig(file string) error {
+	_, err := os.Stat(file)
+	if err == nil {
+		return fmt.Errorf("%s already exists", file)
+	}
+
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return err
+	}
+
+	err = writeFile(file, []byte(getConfigTemplate()))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("config template is written to %s\n", file)
+
+	return nil
+}
+
+// getConfigTemplate returns defaults section with every option of usage
+// commented out, descriptions of options are taken from usage.
+func getConfigTemplate() string {
+	lines := []string{
+		"# defaults of command line options, options specified in command",
+		"# line override them, uncomment the ones you always use",
+		"[defaults]",
+	}
+
+	options := strings.Split(usage[strings.Index(usage, "\nOptions:"):], "\n")
+	for index := 0; index < len(options); index++ {
+		match := usageOption.FindStringSubmatch(options[index])
+		if match == nil || indexOf(fixedOptions, match[1]) >= 0 {
+			continue
+		}
+
+		option, argument, description := match[1], match[2], match[3]
+		for index+1 < len(options) &&
+			strings.HasPrefix(options[index+1], "     ") {
+			index++
+			description += " " + strings.TrimSpace(options[index])
+		}
+
+		lines = append(lines, "")
+		for _, line := range wrapText(description, 76) {
+			lines = append(lines, "# "+line)
+		}
+
+		name := strings.TrimLeft(option, "-")
+		if argument == "" {
+			lines = append(lines, "# "+name+" = false")
+		} else {
+			lines = append(lines, "# "+name+" = "+getUsageDefault(description))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// getUsageDefault returns default value from description of option, numbers
+// are not quoted.
+func getUsageDefault(description string) string {
+	value := ""
+	if start := strings.Index(description, "[default: "); start >= 0 {
+		value = description[start+len("[default: "):]
+		value = value[:strings.Index(value, "]")]
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
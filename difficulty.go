@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// subjective difficulty of trial is rated from 1 (easy) to 5 (hard)
+const (
+	minDifficulty = 1
+	maxDifficulty = 5
+)
+
+// rateDifficulty asks the user how difficult the test felt, Enter skips the
+// rating.
+func rateDifficulty(result *Result) error {
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(
+		fmt.Sprintf("score: %d/%d", result.Score, result.Count), height/2-1,
+	)
+	printCentered(
+		fmt.Sprintf(
+			"how difficult was it? %d: easy .. %d: hard, Enter: skip",
+			minDifficulty, maxDifficulty,
+		),
+		height/2+1,
+	)
+	screen.HideCursor()
+	screen.Flush()
+
+	for {
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyEnter:
+			clearScreen()
+			return nil
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return errAborted
+		case event.Key == ui.KeyEsc:
+			return errStopped
+		case event.Ch >= '0'+minDifficulty && event.Ch <= '0'+maxDifficulty:
+			result.Difficulty = int(event.Ch - '0')
+			clearScreen()
+			return nil
+		}
+	}
+}
+
+// printDifficulty compares rated difficulty of tests with their accuracy,
+// well calibrated ratings go down as accuracy goes up.
+func printDifficulty(database Database) {
+	ratings, accuracies := []float64{}, []float64{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.Difficulty != 0 {
+				ratings = append(ratings, float64(result.Difficulty))
+				accuracies = append(accuracies, getAccuracy(result))
+			}
+		}
+	}
+
+	if len(ratings) == 0 {
+		fmt.Println("no rated tests recorded yet, use --rate-difficulty")
+		return
+	}
+
+	printAccuracyGroups(database, func(session Session, result Result) string {
+		if result.Difficulty == 0 {
+			return fmt.Sprintf("%-12s", "not rated")
+		}
+
+		return fmt.Sprintf("difficulty %d", result.Difficulty)
+	})
+
+	correlation, ok := getCorrelation(ratings, accuracies)
+	if !ok {
+		fmt.Println("\ncorrelation needs tests of different ratings and scores")
+		return
+	}
+
+	fmt.Printf(
+		"\ncorrelation of difficulty and accuracy: %s\n",
+		formatFloat(correlation, 2),
+	)
+
+	switch {
+	case correlation <= -0.5:
+		fmt.Println("ratings predict performance well")
+	case correlation <= -0.2:
+		fmt.Println("ratings predict performance somewhat")
+	default:
+		fmt.Println("ratings don't predict performance")
+	}
+}
+
+// getCorrelation returns Pearson correlation coefficient, which is
+// undefined if any of the values are all the same.
+func getCorrelation(xs, ys []float64) (float64, bool) {
+	var meanX, meanY float64
+	for index := range xs {
+		meanX += xs[index]
+		meanY += ys[index]
+	}
+
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var covariance, varianceX, varianceY float64
+	for index := range xs {
+		dx, dy := xs[index]-meanX, ys[index]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
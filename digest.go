@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// integrations which may batch sessions into daily digests
+const integrationWebhook = "webhook"
+
+// digestDay is summary of sessions of the day
+type digestDay struct {
+	Date     string    `json:"date"`
+	Sessions int       `json:"sessions"`
+	Tests    int       `json:"tests"`
+	Accuracy float64   `json:"accuracy"`
+	BestSpan int       `json:"best_span"`
+	Results  []Session `json:"results"`
+}
+
+// pending sessions of every integration, which are not posted yet
+type digest map[string][]Session
+
+func getDigestFile(database string) string {
+	return database + ".digest"
+}
+
+func loadDigest(file string) (digest, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return digest{}, nil
+		}
+
+		return nil, err
+	}
+
+	pending := digest{}
+	err = json.Unmarshal(content, &pending)
+
+	return pending, err
+}
+
+// updateDigest adds session to pending sessions of integration and returns
+// digests of days before today, which are removed from pending sessions.
+func updateDigest(
+	database string, integration string, session Session, now time.Time,
+) ([]digestDay, error) {
+	file := getDigestFile(database)
+
+	pending, err := loadDigest(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := append(pending[integration], session)
+
+	today := getDay(now)
+
+	byDay := map[string][]Session{}
+	kept := []Session{}
+	for _, session := range sessions {
+		if !getDay(session.Date.In(now.Location())).Before(today) {
+			kept = append(kept, session)
+			continue
+		}
+
+		date := session.Date.In(now.Location()).Format("2006-01-02")
+		byDay[date] = append(byDay[date], session)
+	}
+
+	pending[integration] = kept
+
+	content, err := json.Marshal(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeFile(file, content)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []digestDay{}
+	for date, sessions := range byDay {
+		days = append(days, summarizeDay(date, sessions))
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	return days, nil
+}
+
+func summarizeDay(date string, sessions []Session) digestDay {
+	day := digestDay{Date: date, Sessions: len(sessions), Results: sessions}
+
+	var sum float64
+	for _, session := range sessions {
+		for _, result := range session.Results {
+			day.Tests++
+			sum += getAccuracy(result)
+
+			if result.Score == result.Count && result.Count > day.BestSpan {
+				day.BestSpan = result.Count
+			}
+		}
+	}
+
+	if day.Tests > 0 {
+		day.Accuracy = sum / float64(day.Tests)
+	}
+
+	return day
+}
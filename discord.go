@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	discordAPI     = "https://discord.com/api/v10"
+	discordTimeout = 10 * time.Second
+
+	// results of Discord users are saved under profiles with this prefix
+	discordProfilePrefix = "discord-"
+)
+
+// types of interactions, responses and flags of Discord API
+const (
+	discordPing         = 1
+	discordCommand      = 2
+	discordPong         = 1
+	discordMessage      = 4
+	discordOptionString = 3
+	discordEphemeral    = 1 << 6
+)
+
+// Discord is [discord] section of config, the bot is application whose
+// interactions endpoint URL points to short serve --discord
+type Discord struct {
+	ApplicationID string `toml:"application_id"`
+
+	// hex public key of the application, requests are verified by it
+	PublicKey string `toml:"public_key"`
+
+	// bot token, used only to register slash commands
+	Token string `toml:"token"`
+}
+
+func (discord Discord) validate() error {
+	if discord.PublicKey == "" {
+		return nil
+	}
+
+	key, err := hex.DecodeString(discord.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("public_key should be hex ed25519 key")
+	}
+
+	return nil
+}
+
+// slash commands of the bot, /digits shows items which are deleted after
+// exposure and /answer scores recalled items
+var discordCommands = []map[string]interface{}{
+	{
+		"name":        "digits",
+		"type":        1,
+		"description": "show numbers to memorize, they disappear soon",
+	},
+	{
+		"name":        "answer",
+		"type":        1,
+		"description": "recall numbers shown by /digits",
+		"options": []map[string]interface{}{
+			{
+				"name":        "items",
+				"type":        discordOptionString,
+				"description": "numbers separated by spaces",
+				"required":    true,
+			},
+		},
+	},
+}
+
+type discordInteraction struct {
+	Type          int    `json:"type"`
+	Token         string `json:"token"`
+	ApplicationID string `json:"application_id"`
+	Data          struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+
+	// member is set in channels, user is set in direct messages
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+}
+
+type discordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+func (interaction discordInteraction) getUser() discordUser {
+	if interaction.Member != nil {
+		return interaction.Member.User
+	}
+
+	if interaction.User != nil {
+		return *interaction.User
+	}
+
+	return discordUser{}
+}
+
+// discordBot delivers slash commands of Discord users to the chat bot
+type discordBot struct {
+	config Discord
+	chat   *chatBot
+}
+
+// serveDiscord registers slash commands and serves interactions endpoint
+// of the bot.
+func serveDiscord(file string, args map[string]interface{}) error {
+	options, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+
+	discord := config.Discord
+	if discord.ApplicationID == "" || discord.PublicKey == "" {
+		return fmt.Errorf(
+			"application_id and public_key are not set in [discord] of %s",
+			options.Config,
+		)
+	}
+
+	if discord.Token != "" {
+		err = discord.register()
+		if err != nil {
+			return err
+		}
+	}
+
+	bot := &discordBot{
+		config: discord,
+		chat:   newChatBot(file, options, discordProfilePrefix, "/digits"),
+	}
+
+	address := args["--listen"].(string)
+
+	log.info("serving discord interactions", Fields{"address": address})
+
+	return http.ListenAndServe(address, bot)
+}
+
+// register overwrites slash commands of the application.
+func (discord Discord) register() error {
+	body, err := json.Marshal(discordCommands)
+	if err != nil {
+		return err
+	}
+
+	return discord.call(
+		http.MethodPut,
+		"/applications/"+discord.ApplicationID+"/commands", body,
+	)
+}
+
+func (discord Discord) call(method string, path string, body []byte) error {
+	request, err := http.NewRequest(
+		method, discordAPI+path, bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if discord.Token != "" {
+		request.Header.Set("Authorization", "Bot "+discord.Token)
+	}
+
+	client := http.Client{Timeout: discordTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf(
+			"discord: %s %s: %s: %s",
+			method, path, response.Status, strings.TrimSpace(string(message)),
+		)
+	}
+
+	return nil
+}
+
+// verify checks that request is signed by Discord, endpoints with invalid
+// signatures are rejected by Discord when URL is set.
+func (discord Discord) verify(request *http.Request, body []byte) bool {
+	key, _ := hex.DecodeString(discord.PublicKey)
+
+	signature, err := hex.DecodeString(
+		request.Header.Get("X-Signature-Ed25519"),
+	)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+
+	message := append(
+		[]byte(request.Header.Get("X-Signature-Timestamp")), body...,
+	)
+
+	return ed25519.Verify(key, message, signature)
+}
+
+func (bot *discordBot) ServeHTTP(
+	writer http.ResponseWriter, request *http.Request,
+) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil || !bot.config.verify(request, body) {
+		http.Error(writer, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var interaction discordInteraction
+	err = json.Unmarshal(body, &interaction)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{"type": discordPong}
+	if interaction.Type == discordCommand {
+		response = bot.handle(interaction)
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(response)
+}
+
+// handle runs slash command and returns message replied to the user.
+func (bot *discordBot) handle(
+	interaction discordInteraction,
+) map[string]interface{} {
+	user := interaction.getUser()
+
+	var (
+		content string
+		flags   int
+		err     error
+	)
+
+	switch interaction.Data.Name {
+	case "digits":
+		content = bot.start(user, interaction.Token)
+	case "answer":
+		items := ""
+		for _, option := range interaction.Data.Options {
+			if option.Name == "items" {
+				items = option.Value
+			}
+		}
+
+		content, err = bot.chat.answer(user.ID, items)
+		if err != nil {
+			log.error("can't save discord result", Fields{
+				"user": user.ID, "error": err,
+			})
+
+			content = "can't save result: " + err.Error()
+		}
+
+		// others can't peek correct numbers in the channel
+		flags = discordEphemeral
+	default:
+		content = "unknown command " + interaction.Data.Name
+		flags = discordEphemeral
+	}
+
+	return map[string]interface{}{
+		"type": discordMessage,
+		"data": map[string]interface{}{"content": content, "flags": flags},
+	}
+}
+
+// start generates items for the user, the message with them is deleted
+// after exposure.
+func (bot *discordBot) start(user discordUser, token string) string {
+	items := bot.chat.start(user.ID)
+
+	time.AfterFunc(bot.chat.options.Exposure, func() {
+		err := bot.config.call(
+			http.MethodDelete,
+			"/webhooks/"+bot.config.ApplicationID+"/"+token+
+				"/messages/@original",
+			nil,
+		)
+		if err != nil {
+			log.warn("can't delete discord message", Fields{"error": err})
+		}
+	})
+
+	return fmt.Sprintf(
+		"<@%s>, memorize in %s sec, then recall with /answer:\n**%s**",
+		user.ID, bot.chat.getExposureSeconds(), strings.Join(items, " "),
+	)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// snapshot older than this is reported, backups are taken only when
+// sessions are finished
+const staleBackupAge = 7 * 24 * time.Hour
+
+// statuses of checks of doctor
+const (
+	doctorOK      = "ok"
+	doctorWarning = "warning"
+	doctorProblem = "problem"
+)
+
+// runDoctor checks database, config and backups and prints what is found,
+// fails if any check found a problem.
+func runDoctor(file string, configFile string) error {
+	problems := 0
+	report := func(status string, subject string, message string) {
+		if status == doctorProblem {
+			problems++
+		}
+
+		fmt.Printf("%-8s %s: %s\n", status, subject, message)
+	}
+
+	config, _, err := loadConfig(configFile)
+	if err != nil {
+		report(doctorProblem, "config", err.Error())
+	} else {
+		report(doctorOK, "config", configFile)
+	}
+
+	checkDatabase(file, report)
+	checkConflicts(file, report)
+	checkLock(file, report)
+
+	if err == nil {
+		checkBackups(file, config.Backups, report)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("doctor found %d problems", problems)
+	}
+
+	return nil
+}
+
+func checkDatabase(file string, report func(string, string, string)) {
+	_, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		report(doctorOK, "database", file+" is created by the first session")
+		return
+	}
+
+	ok, err := isSQLite(file)
+	switch {
+	case err != nil:
+		report(doctorProblem, "database", err.Error())
+		return
+	case !ok && isCompressed(file):
+		database, err := readCompressedDatabase(file)
+		if err != nil {
+			report(doctorProblem, "database", err.Error())
+			return
+		}
+
+		report(doctorOK, "database", fmt.Sprintf(
+			"%s, compressed JSON, %d sessions", file, len(database.Sessions),
+		))
+		return
+	case !ok:
+		report(
+			doctorWarning, "database",
+			file+" is JSON, it's imported into SQLite by the next session",
+		)
+		return
+	}
+
+	db, err := openSQLite(file)
+	if err != nil {
+		report(doctorProblem, "database", err.Error())
+		return
+	}
+	defer db.Close()
+
+	var integrity string
+	err = db.QueryRow(`PRAGMA quick_check`).Scan(&integrity)
+	if err == nil && integrity != "ok" {
+		err = fmt.Errorf("integrity check failed: %s", integrity)
+	}
+
+	var sessions int
+	if err == nil {
+		err = db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&sessions)
+	}
+
+	if err != nil {
+		report(doctorProblem, "database", fmt.Sprintf("%s: %s", file, err))
+		return
+	}
+
+	report(
+		doctorOK, "database", fmt.Sprintf("%s, %d sessions", file, sessions),
+	)
+}
+
+func checkConflicts(file string, report func(string, string, string)) {
+	conflicts, err := getConflicts(file)
+	switch {
+	case err != nil:
+		report(doctorProblem, "sync", err.Error())
+	case len(conflicts) > 0:
+		report(
+			doctorWarning, "sync",
+			fmt.Sprintf(
+				"%d conflict copies of the database, merge them by short merge",
+				len(conflicts),
+			),
+		)
+	}
+}
+
+func checkLock(file string, report func(string, string, string)) {
+	pid, err := readLock(getLockFile(file))
+	switch {
+	case os.IsNotExist(err):
+		return
+	case err != nil || !isRunning(pid):
+		report(
+			doctorWarning, "lock",
+			getLockFile(file)+" is left by dead process, it's removed by "+
+				"the next session",
+		)
+	default:
+		report(
+			doctorOK, "lock",
+			fmt.Sprintf("session is running by pid %d", pid),
+		)
+	}
+}
+
+func checkBackups(
+	file string, backups Backups, report func(string, string, string),
+) {
+	if !backups.isEnabled() {
+		report(
+			doctorWarning, "backups",
+			"disabled, set daily, weekly or monthly in [backups] of config",
+		)
+		return
+	}
+
+	directory := backups.getDirectory(file)
+
+	existing, err := listBackups(file, directory)
+	if err != nil {
+		report(doctorProblem, "backups", err.Error())
+		return
+	}
+
+	policy := []string{}
+	for _, period := range []struct {
+		count int
+		name  string
+	}{
+		{backups.Daily, "daily"},
+		{backups.Weekly, "weekly"},
+		{backups.Monthly, "monthly"},
+	} {
+		if period.count > 0 {
+			policy = append(
+				policy, fmt.Sprintf("%d %s", period.count, period.name),
+			)
+		}
+	}
+
+	summary := fmt.Sprintf(
+		"%d snapshots in %s, keeping %s",
+		len(existing), directory, strings.Join(policy, ", "),
+	)
+
+	switch {
+	case len(existing) == 0:
+		report(
+			doctorWarning, "backups",
+			summary+", the first one is taken when session is finished",
+		)
+	case time.Since(existing[0].date) > staleBackupAge:
+		report(
+			doctorWarning, "backups",
+			summary+", the latest one is taken on "+
+				existing[0].date.Format(backupDateLayout),
+		)
+	default:
+		report(
+			doctorOK, "backups",
+			summary+", the latest one is taken on "+
+				existing[0].date.Format(backupDateLayout),
+		)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// block of consecutive tests with the same parameters
+type dryRunBlock struct {
+	first int
+	last  int
+	test  Test
+}
+
+// printDryRun prints resolved parameters of the session, its blocks and
+// example stimuli of every block without starting the session.
+func printDryRun(options Options, config Config, tests []Test, practice bool) {
+	fmt.Println("options:")
+	for _, line := range [][2]string{
+		{"database", options.Database},
+		{"config", options.Config},
+		{"profile", options.Profile},
+		{"script", options.Script},
+		{"preset", getPresetName(options.Preset)},
+		{"plan", fmt.Sprint(options.Plan)},
+		{"range", fmt.Sprintf("%d-%d", options.Min, options.Max)},
+		{"schedule", options.Schedule},
+		{"time scale", fmt.Sprint(options.TimeScale)},
+		{"seed", formatSeed(options.Seed)},
+		{"scoring", options.getScoring(options.Mode)},
+		{"feedback", fmt.Sprint(options.Feedback)},
+		{"feedback delay", formatDelay(options.FeedbackDelay)},
+		{"interference", formatDelay(options.Interference)},
+		{"heat", fmt.Sprint(options.Heat)},
+		{"speech", fmt.Sprint(options.Speech)},
+		{"opponent", fmt.Sprint(options.Opponent != nil)},
+		{"adjust blocks", fmt.Sprint(options.AdjustBlocks)},
+		{"pause on blur", fmt.Sprint(options.PauseOnBlur)},
+		{"record", fmt.Sprint(options.Record)},
+		{"practice", fmt.Sprint(practice)},
+		{"recorded", fmt.Sprint(!options.Untracked)},
+		{"cooldown", config.Cooldown.String()},
+		{"colors", theme.colors},
+		{"locale", config.Locale},
+	} {
+		if line[1] != "" {
+			fmt.Printf("  %-15s %s\n", line[0]+":", line[1])
+		}
+	}
+
+	fmt.Printf("\n%d tests:\n", len(tests))
+	for _, block := range getDryRunBlocks(tests) {
+		exposure := "until Enter"
+		if block.test.Exposure > 0 {
+			exposure = block.test.Exposure.String()
+		}
+
+		fmt.Printf(
+			"  %d-%d  %s, count %d, exposure %s\n",
+			block.first, block.last, block.test.Mode, block.test.Count,
+			exposure,
+		)
+		fmt.Printf(
+			"      example: %s\n",
+			strings.Join(getExampleStimuli(options, block.test), " "),
+		)
+	}
+}
+
+// formatDelay returns empty string for zero delay, so it's not printed.
+func formatDelay(delay time.Duration) string {
+	if delay == 0 {
+		return ""
+	}
+
+	return delay.String()
+}
+
+// formatSeed returns empty string if there is no seed, so it's not
+// printed.
+func formatSeed(seed *int64) string {
+	if seed == nil {
+		return ""
+	}
+
+	return fmt.Sprint(*seed)
+}
+
+func getPresetName(preset *Preset) string {
+	if preset == nil {
+		return ""
+	}
+
+	return preset.Name
+}
+
+// getDryRunBlocks groups consecutive tests with the same parameters,
+// tests are numbered from 1.
+func getDryRunBlocks(tests []Test) []dryRunBlock {
+	blocks := []dryRunBlock{}
+	for index, test := range tests {
+		last := len(blocks) - 1
+		if last >= 0 && blocks[last].test == test {
+			blocks[last].last = index + 1
+			continue
+		}
+
+		blocks = append(blocks, dryRunBlock{index + 1, index + 1, test})
+	}
+
+	return blocks
+}
+
+// getExampleStimuli returns items which could be shown in the test.
+func getExampleStimuli(options Options, test Test) []string {
+	switch test.Mode {
+	case modeMapping:
+		items := []string{}
+		for _, pair := range getMappingPairs(options, test.Count) {
+			items = append(items, pair.Key+"="+pair.Value)
+		}
+
+		return items
+
+	case modeSentence:
+		corpus := options.Corpus
+		if len(corpus) == 0 {
+			corpus = builtinCorpus
+		}
+
+		return pickSentence(corpus, test.Count)
+
+	case modeWords:
+		return pickWords(getWordList(options), test.Count)
+
+	case modeAcronym:
+		items := []string{}
+		count := clamp(test.Count, 0, len(options.Pairs))
+		for _, pair := range options.Pairs[:count] {
+			items = append(items, pair.Key+"="+pair.Value)
+		}
+
+		return items
+
+	case modeChess:
+		return generateSquares(seeded, test.Count)
+
+	case modeAssociation:
+		items := []string{}
+		for _, pair := range getAssociationPairs(options, test.Count) {
+			items = append(items, pair.Key+"="+pair.Value)
+		}
+
+		return items
+
+	case modeSpatial:
+		return generateCells(seeded, test.Count)
+
+	case modeDates:
+		items := []string{}
+		for _, date := range generateDates(test.Count) {
+			items = append(items, date.Format(dateFormats[0]))
+		}
+
+		return items
+
+	case modeRows:
+		length := test.RowLength
+		if length == 0 {
+			length = defaultRowLength
+		}
+
+		return generateRows(test.Count, length, test.Binary)
+
+	case modeSpoken:
+		return strings.Fields(joinNumbers(
+			generateRandomNumbers(seeded, 0, 9, test.Count),
+		))
+	}
+
+	return strings.Fields(joinNumbers(
+		generateRandomNumbers(seeded, options.Min, options.Max, test.Count),
+	))
+}
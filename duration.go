@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is time.Duration which can be decoded from config, in addition to
+// units supported by time.ParseDuration it understands days (d), weeks (w)
+// and years (y), like "90d".
+type Duration struct {
+	time.Duration
+}
+
+func (duration *Duration) UnmarshalText(text []byte) error {
+	value, err := parseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	duration.Duration = value
+
+	return nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if strings.HasSuffix(value, suffix) {
+			count, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	return time.ParseDuration(value)
+}
+
+// String formats whole days as days, unlike time.Duration.
+func (duration Duration) String() string {
+	day := 24 * time.Hour
+	if duration.Duration > 0 && duration.Duration%day == 0 {
+		return fmt.Sprintf("%dd", duration.Duration/day)
+	}
+
+	return duration.Duration.String()
+}
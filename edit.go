@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Edit is change of metadata applied to every session matching the query
+type Edit struct {
+	AddTags    []string
+	RemoveTags []string
+
+	// nil leaves practice flag unchanged
+	Practice *bool
+}
+
+func (edit Edit) apply(session *Session) bool {
+	before := formatHistorySession(*session)
+
+	for _, tag := range edit.AddTags {
+		if !session.hasTag(tag) {
+			session.Tags = append(session.Tags, tag)
+		}
+	}
+
+	for _, tag := range edit.RemoveTags {
+		session.Tags = changeTags(session.Tags, "-"+tag)
+	}
+
+	if len(session.Tags) == 0 {
+		session.Tags = nil
+	}
+
+	if edit.Practice != nil {
+		session.Practice = *edit.Practice
+	}
+
+	return formatHistorySession(*session) != before
+}
+
+// editSessions applies edit to sessions matching the query, with dryRun
+// changed sessions are only printed.
+func editSessions(file string, query Query, edit Edit, dryRun bool) error {
+	release, err := acquireLock(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	indexes := findSessions(database, query, orderDate, false)
+
+	changed := 0
+	for _, index := range indexes {
+		session := &database.Sessions[index]
+		before := formatHistorySession(*session)
+
+		if !edit.apply(session) {
+			continue
+		}
+
+		changed++
+
+		fmt.Printf("- %s\n+ %s\n", before, formatHistorySession(*session))
+	}
+
+	if dryRun {
+		fmt.Printf(
+			"%d of %d matching sessions would be changed\n",
+			changed, len(indexes),
+		)
+
+		return nil
+	}
+
+	fmt.Printf("%d of %d matching sessions changed\n", changed, len(indexes))
+
+	if changed == 0 {
+		return nil
+	}
+
+	return saveDatabase(file, database)
+}
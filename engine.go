@@ -0,0 +1,460 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docopt/docopt-go"
+)
+
+// modes which frontends can draw by themselves, items of other modes are
+// asked interactively by the terminal
+var engineModes = []string{modeDigits, modeReverse, modeChess, modeSpatial}
+
+// options which frontends can set, others are rejected, so frontends can't
+// read files of the server, like --pairs or --wordlist, or serve commands
+var engineOptions = []string{
+	"-n", "-c", "-i", "-a", "--digits", "--mode", "--reverse", "--scoring",
+	"--charset", "--profile", "--expose", "--lengths", "--schedule",
+	"--time-scale", "--seed",
+}
+
+// sessions of frontends which are not used for this time are dropped
+const engineSessionTimeout = 30 * time.Minute
+
+// serveGRPC serves the engine over gRPC, it's set by the build with -tags
+// grpc, which needs generated code of enginepb/engine.proto.
+var serveGRPC = func(file string, args map[string]interface{}) error {
+	return errors.New(
+		"short is built without gRPC support, rebuild it with -tags grpc",
+	)
+}
+
+// engine runs sessions of frontends which draw tests by themselves, items
+// are generated and scored like in the terminal and sessions are saved to
+// the same database
+type engine struct {
+	database string
+	config   string
+
+	mutex    sync.Mutex
+	sessions map[string]*engineSession
+}
+
+type engineSession struct {
+	options Options
+	tests   []Test
+	results []Result
+	started time.Time
+
+	// time of the last request of the session, idle sessions are dropped
+	used time.Time
+
+	// items of the trial which is not submitted yet
+	items []string
+	shown time.Time
+
+	// source of items of session started with --seed, items of other
+	// sessions are drawn from crypto/rand
+	random *mathrand.Rand
+}
+
+// engineTrial is test which frontend shows and then asks to recall
+type engineTrial struct {
+	Index    int
+	Tests    int
+	Mode     string
+	Items    []string
+	Exposure time.Duration
+	Rate     time.Duration
+
+	// count of cells in row and column of spatial grid
+	Side int
+}
+
+// engineRecall is score of the recalled trial
+type engineRecall struct {
+	Score    int
+	Count    int
+	Expected []string
+	Finished bool
+}
+
+// modeStats is summary of scored sessions of the mode
+type modeStats struct {
+	Mode         string  `json:"mode"`
+	Sessions     int     `json:"sessions"`
+	Tests        int     `json:"tests"`
+	BestSpan     int     `json:"best_span"`
+	BestAccuracy float64 `json:"best_accuracy"`
+}
+
+func newEngine(file string, config string) *engine {
+	return &engine{
+		database: file,
+		config:   config,
+		sessions: map[string]*engineSession{},
+	}
+}
+
+// startSession starts session with command line options of short, like
+// "--mode chess -c 5", and returns its id and count of tests.
+func (engine *engine) startSession(argv []string) (string, int, error) {
+	// usage is not printed to the server's output on errors
+	parser := docopt.Parser{
+		HelpHandler: docopt.NoHelpHandler, SkipHelpFlags: true,
+	}
+
+	args, err := parser.ParseArgs(usage, argv, "")
+	if err != nil {
+		return "", 0, fmt.Errorf(
+			"options don't match usage: %s", strings.Join(argv, " "),
+		)
+	}
+
+	err = checkEngineArgs(args, argv)
+	if err != nil {
+		return "", 0, err
+	}
+
+	args["-f"] = engine.database
+	args["--config"] = engine.config
+
+	// defaults of the config are used like in the terminal
+	err = applyDefaults(args, argv)
+	if err != nil {
+		return "", 0, err
+	}
+
+	options, err := parseOptions(args)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if indexOf(engineModes, options.Mode) < 0 {
+		return "", 0, fmt.Errorf(
+			"%s mode can't be driven by frontend", options.Mode,
+		)
+	}
+
+	config, _, err := loadConfig(engine.config)
+	if err != nil {
+		return "", 0, err
+	}
+
+	tests, err := getTests(options, config)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for index := range tests {
+		tests[index].Exposure = scaleDuration(
+			tests[index].Exposure, options.TimeScale,
+		)
+		tests[index].Rate = scaleDuration(tests[index].Rate, options.TimeScale)
+	}
+
+	id, err := getEngineSessionID()
+	if err != nil {
+		return "", 0, err
+	}
+
+	session := &engineSession{
+		options: options,
+		tests:   tests,
+		started: time.Now(),
+		used:    time.Now(),
+	}
+
+	// every session has its own source, so concurrent sessions don't
+	// change items of each other
+	if options.Seed != nil {
+		session.random = mathrand.New(mathrand.NewSource(*options.Seed))
+	}
+
+	engine.mutex.Lock()
+	engine.removeIdleSessions()
+	engine.sessions[id] = session
+	engine.mutex.Unlock()
+
+	log.info("engine session started", Fields{
+		"session": id, "tests": len(tests), "mode": options.Mode,
+	})
+
+	return id, len(tests), nil
+}
+
+// findSession returns session which is not idle for too long and marks it
+// as used, engine must be locked.
+func (engine *engine) findSession(id string) (*engineSession, error) {
+	engine.removeIdleSessions()
+
+	session, ok := engine.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %q is not found or expired", id)
+	}
+
+	session.used = time.Now()
+
+	return session, nil
+}
+
+// removeIdleSessions drops sessions which frontends stopped to use without
+// finishing them, engine must be locked.
+func (engine *engine) removeIdleSessions() {
+	for id, session := range engine.sessions {
+		if time.Since(session.used) > engineSessionTimeout {
+			delete(engine.sessions, id)
+
+			log.info("engine session expired", Fields{"session": id})
+		}
+	}
+}
+
+// checkEngineArgs rejects commands and options which frontends can't set.
+func checkEngineArgs(args map[string]interface{}, argv []string) error {
+	for name, value := range args {
+		if strings.HasPrefix(name, "-") {
+			continue
+		}
+
+		// commands are flags, arguments are strings or lists of them
+		switch value := value.(type) {
+		case bool:
+			if value {
+				return fmt.Errorf("%s can't be run by frontend", name)
+			}
+		case string:
+			return fmt.Errorf("%s can't be set by frontend", name)
+		case []string:
+			if len(value) > 0 {
+				return fmt.Errorf("%s can't be set by frontend", name)
+			}
+		}
+	}
+
+	given := []string{}
+	for option := range getGivenOptions(args, argv) {
+		given = append(given, option)
+	}
+
+	sort.Strings(given)
+
+	for _, option := range given {
+		if indexOf(engineOptions, option) < 0 {
+			return fmt.Errorf("%s can't be set by frontend", option)
+		}
+	}
+
+	return nil
+}
+
+// nextTrial generates items of the next test of the session, the trial is
+// repeated until it's submitted.
+func (engine *engine) nextTrial(id string) (engineTrial, error) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	session, err := engine.findSession(id)
+	if err != nil {
+		return engineTrial{}, err
+	}
+
+	test := session.tests[len(session.results)]
+
+	if session.items == nil {
+		session.items = getEngineItems(session.options, test, session.random)
+		session.shown = time.Now()
+	}
+
+	trial := engineTrial{
+		Index:    len(session.results),
+		Tests:    len(session.tests),
+		Mode:     test.Mode,
+		Items:    session.items,
+		Exposure: test.Exposure,
+		Rate:     test.Rate,
+	}
+
+	if test.Mode == modeSpatial {
+		trial.Side = getSpatialSide(test.Count)
+	}
+
+	return trial, nil
+}
+
+// submitRecall scores items recalled by the frontend, study time is
+// measured by the frontend, zero means time since the trial was requested.
+// The session is saved after the last test.
+func (engine *engine) submitRecall(
+	id string, input []string, study time.Duration,
+) (engineRecall, error) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	session, err := engine.findSession(id)
+	if err != nil {
+		return engineRecall{}, err
+	}
+
+	if session.items == nil {
+		return engineRecall{}, fmt.Errorf("trial is not requested")
+	}
+
+	if study < 0 {
+		return engineRecall{}, fmt.Errorf(
+			"study time can't be negative: %s", study,
+		)
+	}
+
+	if study == 0 {
+		study = time.Since(session.shown)
+	}
+
+	test := session.tests[len(session.results)]
+
+	expected := session.items
+	if test.Mode == modeReverse {
+		expected = reverseTokens(expected)
+	}
+
+	scoring := session.options.getScoring(test.Mode)
+	score := scoreSequence(scoring, expected, input)
+
+	result := Result{
+		Score:    score,
+		Duration: study.Seconds(),
+		Count:    len(expected),
+		Mode:     test.Mode,
+		Scoring:  scoring,
+		Exposure: test.Exposure.Seconds(),
+	}
+
+	switch {
+	case test.Mode == modeChess || test.Mode == modeSpatial:
+		result.Items = session.items
+		result.Input = input
+	case !session.options.Charset.isDigits():
+		result.Charset = session.options.Charset.Name
+		result.Items = session.items
+		result.Input = input
+	}
+
+	session.results = append(session.results, result)
+	session.items = nil
+
+	recall := engineRecall{
+		Score:    score,
+		Count:    len(expected),
+		Expected: expected,
+		Finished: len(session.results) == len(session.tests),
+	}
+
+	if !recall.Finished {
+		return recall, nil
+	}
+
+	delete(engine.sessions, id)
+
+	return recall, appendSession(engine.database, session.getSession())
+}
+
+func (session *engineSession) getSession() Session {
+	total := 0
+	duration := 0.0
+	for _, result := range session.results {
+		total += result.Score
+		duration += result.Duration
+	}
+
+	return Session{
+		Date:        session.started,
+		AvgDuration: duration / float64(len(session.results)),
+		TotalScore:  total,
+		Results:     session.results,
+		Profile:     session.options.Profile,
+		Seed:        session.options.Seed,
+	}
+}
+
+// getStats returns summary of scored sessions of the profile by modes.
+func (engine *engine) getStats(profile string) ([]modeStats, error) {
+	database, err := loadDatabase(engine.database)
+	if err != nil {
+		return nil, err
+	}
+
+	return getModeStats(database.getProfile(profile).getScored()), nil
+}
+
+// getModeStats returns summary of sessions by modes sorted by names.
+func getModeStats(database Database) []modeStats {
+	byMode := map[string]*modeStats{}
+	for _, session := range database.Sessions {
+		mode := getSessionMode(session)
+
+		stats, ok := byMode[mode]
+		if !ok {
+			stats = &modeStats{Mode: mode}
+			byMode[mode] = stats
+		}
+
+		stats.Sessions++
+		stats.Tests += len(session.Results)
+
+		if span := getMaxSpan(session); span > stats.BestSpan {
+			stats.BestSpan = span
+		}
+
+		accuracy := getSessionAccuracy(session)
+		if accuracy > stats.BestAccuracy {
+			stats.BestAccuracy = accuracy
+		}
+	}
+
+	result := []modeStats{}
+	for _, stats := range byMode {
+		result = append(result, *stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Mode < result[j].Mode
+	})
+
+	return result
+}
+
+// getEngineItems generates items of the test like terminal tests do, they
+// are drawn from crypto/rand if the source is nil.
+func getEngineItems(
+	options Options, test Test, source *mathrand.Rand,
+) []string {
+	switch test.Mode {
+	case modeChess:
+		return generateSquares(source, test.Count)
+	case modeSpatial:
+		return generateCells(source, test.Count)
+	default:
+		return options.Charset.generateTokens(
+			source, options.Min, options.Max, test.Count,
+		)
+	}
+}
+
+func getEngineSessionID() (string, error) {
+	id := make([]byte, 16)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,676 @@
+// Engine drives tests of short for frontends which draw them by themselves,
+// like mobile or Electron apps. Items are generated and scored by the same
+// code as in the terminal and sessions are saved to the same database.
+//
+// Go code is generated by go generate ./enginepb.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: engine.proto
+
+package enginepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StartSessionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// options like in command line: "--mode", "chess", "-c", "5"; only
+	// options of tests and --profile can be set, others are rejected
+	Options       []string `protobuf:"bytes,1,rep,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartSessionRequest) Reset() {
+	*x = StartSessionRequest{}
+	mi := &file_engine_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartSessionRequest) ProtoMessage() {}
+
+func (x *StartSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartSessionRequest.ProtoReflect.Descriptor instead.
+func (*StartSessionRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartSessionRequest) GetOptions() []string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type StartSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Tests         int32                  `protobuf:"varint,2,opt,name=tests,proto3" json:"tests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartSessionResponse) Reset() {
+	*x = StartSessionResponse{}
+	mi := &file_engine_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartSessionResponse) ProtoMessage() {}
+
+func (x *StartSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartSessionResponse.ProtoReflect.Descriptor instead.
+func (*StartSessionResponse) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StartSessionResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StartSessionResponse) GetTests() int32 {
+	if x != nil {
+		return x.Tests
+	}
+	return 0
+}
+
+type NextTrialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextTrialRequest) Reset() {
+	*x = NextTrialRequest{}
+	mi := &file_engine_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextTrialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextTrialRequest) ProtoMessage() {}
+
+func (x *NextTrialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextTrialRequest.ProtoReflect.Descriptor instead.
+func (*NextTrialRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NextTrialRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type Trial struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Tests int32                  `protobuf:"varint,2,opt,name=tests,proto3" json:"tests,omitempty"`
+	// digits, reverse, chess or spatial
+	Mode string `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	// numbers, chess squares or cells of spatial grid like "b3", where
+	// columns are letters and rows are counted from the top
+	Items []string `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+	// zero exposure means items are shown until the user hides them
+	ExposureSeconds float64 `protobuf:"fixed64,5,opt,name=exposure_seconds,json=exposureSeconds,proto3" json:"exposure_seconds,omitempty"`
+	// interval between lit cells of spatial mode, zero is one second
+	RateSeconds float64 `protobuf:"fixed64,6,opt,name=rate_seconds,json=rateSeconds,proto3" json:"rate_seconds,omitempty"`
+	// count of cells in row and column of spatial grid
+	GridSide      int32 `protobuf:"varint,7,opt,name=grid_side,json=gridSide,proto3" json:"grid_side,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trial) Reset() {
+	*x = Trial{}
+	mi := &file_engine_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trial) ProtoMessage() {}
+
+func (x *Trial) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trial.ProtoReflect.Descriptor instead.
+func (*Trial) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Trial) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Trial) GetTests() int32 {
+	if x != nil {
+		return x.Tests
+	}
+	return 0
+}
+
+func (x *Trial) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Trial) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Trial) GetExposureSeconds() float64 {
+	if x != nil {
+		return x.ExposureSeconds
+	}
+	return 0
+}
+
+func (x *Trial) GetRateSeconds() float64 {
+	if x != nil {
+		return x.RateSeconds
+	}
+	return 0
+}
+
+func (x *Trial) GetGridSide() int32 {
+	if x != nil {
+		return x.GridSide
+	}
+	return 0
+}
+
+type SubmitRecallRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Input     []string               `protobuf:"bytes,2,rep,name=input,proto3" json:"input,omitempty"`
+	// time items were studied, zero means time since NextTrial
+	StudySeconds  float64 `protobuf:"fixed64,3,opt,name=study_seconds,json=studySeconds,proto3" json:"study_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitRecallRequest) Reset() {
+	*x = SubmitRecallRequest{}
+	mi := &file_engine_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitRecallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitRecallRequest) ProtoMessage() {}
+
+func (x *SubmitRecallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitRecallRequest.ProtoReflect.Descriptor instead.
+func (*SubmitRecallRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SubmitRecallRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SubmitRecallRequest) GetInput() []string {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *SubmitRecallRequest) GetStudySeconds() float64 {
+	if x != nil {
+		return x.StudySeconds
+	}
+	return 0
+}
+
+type Recall struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Score int32                  `protobuf:"varint,1,opt,name=score,proto3" json:"score,omitempty"`
+	Count int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// items in order of recall, reversed for reverse mode
+	Expected      []string `protobuf:"bytes,3,rep,name=expected,proto3" json:"expected,omitempty"`
+	Finished      bool     `protobuf:"varint,4,opt,name=finished,proto3" json:"finished,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recall) Reset() {
+	*x = Recall{}
+	mi := &file_engine_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recall) ProtoMessage() {}
+
+func (x *Recall) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recall.ProtoReflect.Descriptor instead.
+func (*Recall) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Recall) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Recall) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Recall) GetExpected() []string {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *Recall) GetFinished() bool {
+	if x != nil {
+		return x.Finished
+	}
+	return false
+}
+
+type GetStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// empty profile is every profile
+	Profile       string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_engine_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetStatsRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+type Stats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Modes         []*ModeStats           `protobuf:"bytes,1,rep,name=modes,proto3" json:"modes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_engine_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Stats) GetModes() []*ModeStats {
+	if x != nil {
+		return x.Modes
+	}
+	return nil
+}
+
+type ModeStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Sessions      int32                  `protobuf:"varint,2,opt,name=sessions,proto3" json:"sessions,omitempty"`
+	Tests         int32                  `protobuf:"varint,3,opt,name=tests,proto3" json:"tests,omitempty"`
+	BestSpan      int32                  `protobuf:"varint,4,opt,name=best_span,json=bestSpan,proto3" json:"best_span,omitempty"`
+	BestAccuracy  float64                `protobuf:"fixed64,5,opt,name=best_accuracy,json=bestAccuracy,proto3" json:"best_accuracy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModeStats) Reset() {
+	*x = ModeStats{}
+	mi := &file_engine_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModeStats) ProtoMessage() {}
+
+func (x *ModeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModeStats.ProtoReflect.Descriptor instead.
+func (*ModeStats) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ModeStats) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ModeStats) GetSessions() int32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+func (x *ModeStats) GetTests() int32 {
+	if x != nil {
+		return x.Tests
+	}
+	return 0
+}
+
+func (x *ModeStats) GetBestSpan() int32 {
+	if x != nil {
+		return x.BestSpan
+	}
+	return 0
+}
+
+func (x *ModeStats) GetBestAccuracy() float64 {
+	if x != nil {
+		return x.BestAccuracy
+	}
+	return 0
+}
+
+var File_engine_proto protoreflect.FileDescriptor
+
+const file_engine_proto_rawDesc = "" +
+	"\n" +
+	"\fengine.proto\x12\fshort.engine\"/\n" +
+	"\x13StartSessionRequest\x12\x18\n" +
+	"\aoptions\x18\x01 \x03(\tR\aoptions\"K\n" +
+	"\x14StartSessionResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05tests\x18\x02 \x01(\x05R\x05tests\"1\n" +
+	"\x10NextTrialRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\xc8\x01\n" +
+	"\x05Trial\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05tests\x18\x02 \x01(\x05R\x05tests\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12\x14\n" +
+	"\x05items\x18\x04 \x03(\tR\x05items\x12)\n" +
+	"\x10exposure_seconds\x18\x05 \x01(\x01R\x0fexposureSeconds\x12!\n" +
+	"\frate_seconds\x18\x06 \x01(\x01R\vrateSeconds\x12\x1b\n" +
+	"\tgrid_side\x18\a \x01(\x05R\bgridSide\"o\n" +
+	"\x13SubmitRecallRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05input\x18\x02 \x03(\tR\x05input\x12#\n" +
+	"\rstudy_seconds\x18\x03 \x01(\x01R\fstudySeconds\"l\n" +
+	"\x06Recall\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x05R\x05score\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x1a\n" +
+	"\bexpected\x18\x03 \x03(\tR\bexpected\x12\x1a\n" +
+	"\bfinished\x18\x04 \x01(\bR\bfinished\"+\n" +
+	"\x0fGetStatsRequest\x12\x18\n" +
+	"\aprofile\x18\x01 \x01(\tR\aprofile\"6\n" +
+	"\x05Stats\x12-\n" +
+	"\x05modes\x18\x01 \x03(\v2\x17.short.engine.ModeStatsR\x05modes\"\x93\x01\n" +
+	"\tModeStats\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x1a\n" +
+	"\bsessions\x18\x02 \x01(\x05R\bsessions\x12\x14\n" +
+	"\x05tests\x18\x03 \x01(\x05R\x05tests\x12\x1b\n" +
+	"\tbest_span\x18\x04 \x01(\x05R\bbestSpan\x12#\n" +
+	"\rbest_accuracy\x18\x05 \x01(\x01R\fbestAccuracy2\xaa\x02\n" +
+	"\x06Engine\x12U\n" +
+	"\fStartSession\x12!.short.engine.StartSessionRequest\x1a\".short.engine.StartSessionResponse\x12@\n" +
+	"\tNextTrial\x12\x1e.short.engine.NextTrialRequest\x1a\x13.short.engine.Trial\x12G\n" +
+	"\fSubmitRecall\x12!.short.engine.SubmitRecallRequest\x1a\x14.short.engine.Recall\x12>\n" +
+	"\bGetStats\x12\x1d.short.engine.GetStatsRequest\x1a\x13.short.engine.StatsB%Z#github.com/kovetskiy/short/enginepbb\x06proto3"
+
+var (
+	file_engine_proto_rawDescOnce sync.Once
+	file_engine_proto_rawDescData []byte
+)
+
+func file_engine_proto_rawDescGZIP() []byte {
+	file_engine_proto_rawDescOnce.Do(func() {
+		file_engine_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_engine_proto_rawDesc), len(file_engine_proto_rawDesc)))
+	})
+	return file_engine_proto_rawDescData
+}
+
+var file_engine_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_engine_proto_goTypes = []any{
+	(*StartSessionRequest)(nil),  // 0: short.engine.StartSessionRequest
+	(*StartSessionResponse)(nil), // 1: short.engine.StartSessionResponse
+	(*NextTrialRequest)(nil),     // 2: short.engine.NextTrialRequest
+	(*Trial)(nil),                // 3: short.engine.Trial
+	(*SubmitRecallRequest)(nil),  // 4: short.engine.SubmitRecallRequest
+	(*Recall)(nil),               // 5: short.engine.Recall
+	(*GetStatsRequest)(nil),      // 6: short.engine.GetStatsRequest
+	(*Stats)(nil),                // 7: short.engine.Stats
+	(*ModeStats)(nil),            // 8: short.engine.ModeStats
+}
+var file_engine_proto_depIdxs = []int32{
+	8, // 0: short.engine.Stats.modes:type_name -> short.engine.ModeStats
+	0, // 1: short.engine.Engine.StartSession:input_type -> short.engine.StartSessionRequest
+	2, // 2: short.engine.Engine.NextTrial:input_type -> short.engine.NextTrialRequest
+	4, // 3: short.engine.Engine.SubmitRecall:input_type -> short.engine.SubmitRecallRequest
+	6, // 4: short.engine.Engine.GetStats:input_type -> short.engine.GetStatsRequest
+	1, // 5: short.engine.Engine.StartSession:output_type -> short.engine.StartSessionResponse
+	3, // 6: short.engine.Engine.NextTrial:output_type -> short.engine.Trial
+	5, // 7: short.engine.Engine.SubmitRecall:output_type -> short.engine.Recall
+	7, // 8: short.engine.Engine.GetStats:output_type -> short.engine.Stats
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_engine_proto_init() }
+func file_engine_proto_init() {
+	if File_engine_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_engine_proto_rawDesc), len(file_engine_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_engine_proto_goTypes,
+		DependencyIndexes: file_engine_proto_depIdxs,
+		MessageInfos:      file_engine_proto_msgTypes,
+	}.Build()
+	File_engine_proto = out.File
+	file_engine_proto_goTypes = nil
+	file_engine_proto_depIdxs = nil
+}
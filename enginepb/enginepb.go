@@ -0,0 +1,5 @@
+// Package enginepb is gRPC service of short engine, its code is generated
+// from engine.proto by go generate and it's built by go build -tags grpc.
+package enginepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative engine.proto
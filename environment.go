@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	powerAC      = "ac"
+	powerBattery = "battery"
+)
+
+// environment the session was run in, helps to find confounds like worse
+// scores on the laptop over SSH
+type Environment struct {
+	Hostname string `json:"hostname,omitempty"`
+	Terminal string `json:"terminal,omitempty"`
+	SSH      bool   `json:"ssh"`
+	Power    string `json:"power,omitempty"`
+}
+
+func getEnvironment() Environment {
+	hostname, _ := os.Hostname()
+
+	terminal := os.Getenv("TERM_PROGRAM")
+	if terminal == "" {
+		terminal = os.Getenv("TERM")
+	}
+
+	return Environment{
+		Hostname: hostname,
+		Terminal: terminal,
+		SSH:      os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "",
+		Power:    getPowerSource(),
+	}
+}
+
+// getPowerSource returns powerAC, powerBattery or empty string if power
+// source can't be detected (or it's a desktop without battery).
+func getPowerSource() string {
+	switch runtime.GOOS {
+	case "linux":
+		return getLinuxPowerSource()
+	case "darwin":
+		output, err := exec.Command("pmset", "-g", "batt").Output()
+		if err != nil {
+			return ""
+		}
+
+		switch {
+		case strings.Contains(string(output), "'AC Power'"):
+			return powerAC
+		case strings.Contains(string(output), "'Battery Power'"):
+			return powerBattery
+		}
+	}
+
+	return ""
+}
+
+func getLinuxPowerSource() string {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+
+	hasBattery := false
+	for _, supply := range supplies {
+		kind := readSysfs(filepath.Join(supply, "type"))
+		switch kind {
+		case "Mains":
+			if readSysfs(filepath.Join(supply, "online")) == "1" {
+				return powerAC
+			}
+		case "Battery":
+			hasBattery = true
+		}
+	}
+
+	if hasBattery {
+		return powerBattery
+	}
+
+	return ""
+}
+
+func readSysfs(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
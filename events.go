@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	eventSessionStarted  = "session_started"
+	eventSessionFinished = "session_finished"
+	eventSessionAborted  = "session_aborted"
+	eventConfigChanged   = "config_changed"
+)
+
+// application event, events are stored separately from results as
+// append-only log
+type Event struct {
+	Date    time.Time              `json:"date"`
+	Type    string                 `json:"type"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// getEventsFile returns event log of the database, the log may be compressed
+// in place by gzip or zstd.
+func getEventsFile(database string) string {
+	return findCompressed(database + ".events")
+}
+
+// logEvent appends event into event log of specified database, event log is
+// auxiliary, so errors are reported but never stop the application.
+func logEvent(database string, kind string, details map[string]interface{}) {
+	err := appendEvent(getEventsFile(database), Event{
+		Date:    time.Now(),
+		Type:    kind,
+		Details: details,
+	})
+	if err != nil {
+		log.error("can't write event log", Fields{"error": err})
+		fmt.Fprintf(os.Stderr, "can't write event log: %s\n", err)
+	}
+
+	fields := Fields{"database": database}
+	for key, value := range details {
+		fields[key] = value
+	}
+
+	log.info(kind, fields)
+}
+
+func appendEvent(file string, event Event) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	fd, err := appendCompressed(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = fd.Write(append(content, '\n'))
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func readEvents(file string) ([]Event, error) {
+	fd, err := openCompressed(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Event{}, nil
+		}
+
+		return nil, err
+	}
+	defer fd.Close()
+
+	events := []Event{}
+
+	scanner := bufio.NewScanner(fd)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var event Event
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", file, line, err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+func printEvents(database string, kind, since interface{}) error {
+	events, err := readEvents(getEventsFile(database))
+	if err != nil {
+		return err
+	}
+
+	var sinceDate time.Time
+	if since != nil {
+		sinceDate, err = time.ParseInLocation("2006-01-02", since.(string), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", since)
+		}
+	}
+
+	for _, event := range events {
+		if kind != nil && event.Type != kind.(string) {
+			continue
+		}
+
+		if event.Date.Before(sinceDate) {
+			continue
+		}
+
+		fmt.Println(formatEvent(event))
+	}
+
+	return nil
+}
+
+func formatEvent(event Event) string {
+	keys := []string{}
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	line := event.Date.Format("2006-01-02 15:04:05") + " " + event.Type
+	for _, key := range keys {
+		line += fmt.Sprintf(" %s=%v", key, event.Details[key])
+	}
+
+	return line
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	exportJSON = "json"
+
+	// one row per test, for spreadsheets
+	exportCSV = "csv"
+
+	// events of sessions and weeks of the plan, for calendar apps
+	exportICal = "ical"
+)
+
+func runExport(file string, args map[string]interface{}) error {
+	format := exportJSON
+	if value, ok := args["--format"].(string); ok {
+		format = value
+	}
+
+	if args["--ical"].(bool) {
+		format = exportICal
+	}
+
+	if format != exportJSON && format != exportCSV && format != exportICal {
+		return fmt.Errorf(
+			"--format: expected %s, %s or %s, got %q",
+			exportJSON, exportCSV, exportICal, format,
+		)
+	}
+
+	config, _, err := loadConfig(expandHome(args["--config"].(string)))
+	if err != nil {
+		return err
+	}
+
+	var since, until time.Time
+	for _, flag := range []struct {
+		name   string
+		target *time.Time
+	}{
+		{"--since", &since},
+		{"--until", &until},
+	} {
+		value, ok := args[flag.name].(string)
+		if !ok {
+			continue
+		}
+
+		date, err := time.ParseInLocation(
+			"2006-01-02", value, config.getLocation(),
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"%s: invalid date %q, expected YYYY-MM-DD", flag.name, value,
+			)
+		}
+
+		*flag.target = date
+	}
+
+	// the whole day of --until is included
+	if !until.IsZero() {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return exportDatabase(
+		file, args["-o"].(string), args["--anonymize"].(bool), format,
+		getProfileOption(args), since, until, config.Plan,
+		clock().In(config.getLocation()), config.getWeekStart(),
+	)
+}
+
+// exportDatabase writes sessions of the profile recorded in specified
+// period, zero time means the period is not limited from that side. Weeks
+// of the plan up to now are written only to calendar, they start from the
+// weekday.
+func exportDatabase(
+	file string, output string, anonymize bool, format string,
+	profile string, since, until time.Time, plan Plan, now time.Time,
+	weekStart time.Weekday,
+) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	database = database.getProfile(profile)
+
+	// pseudonyms are salted anew for every export, so they can't be matched
+	// with hashes of guessed names or with pseudonyms of other exports
+	var salt []byte
+	if anonymize {
+		salt = make([]byte, 32)
+
+		_, err = rand.Read(salt)
+		if err != nil {
+			return err
+		}
+	}
+
+	sessions := []Session{}
+	for _, session := range database.Sessions {
+		if session.Date.Before(since) {
+			continue
+		}
+
+		if !until.IsZero() && !session.Date.Before(until) {
+			continue
+		}
+
+		if anonymize {
+			session = anonymizeSession(session, salt)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	database.Sessions = sessions
+
+	var content []byte
+	switch format {
+	case exportCSV:
+		content, err = encodeCSV(database)
+	case exportICal:
+		content = encodeICal(database, plan, now, weekStart)
+	default:
+		content, err = encodeDatabase(database)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(output, content)
+}
+
+// encodeCSV flattens sessions into rows of their tests.
+func encodeCSV(database Database) ([]byte, error) {
+	buffer := bytes.Buffer{}
+
+	writer := csv.NewWriter(&buffer)
+	writer.Write([]string{
+		"date", "test", "mode", "count", "score", "duration", "practice",
+	})
+
+	for _, session := range database.Sessions {
+		for index, result := range session.Results {
+			writer.Write([]string{
+				session.Date.Format(time.RFC3339),
+				strconv.Itoa(index + 1),
+				result.getMode(),
+				strconv.Itoa(result.Count),
+				strconv.Itoa(result.Score),
+				strconv.FormatFloat(result.Duration, 'f', 3, 64),
+				strconv.FormatBool(session.Practice),
+			})
+		}
+	}
+
+	writer.Flush()
+
+	return buffer.Bytes(), writer.Error()
+}
+
+// anonymizeSession strips everything that could identify the user or the
+// exact moment of training, so exported data can be shared publicly.
+// Profiles are replaced by pseudonyms salted by the salt.
+func anonymizeSession(session Session, salt []byte) Session {
+	year, month, day := session.Date.Date()
+	session.Date = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	results := make([]Result, len(session.Results))
+	for index, result := range session.Results {
+		// notes are free text and may mention anything
+		result.Note = ""
+
+		// items may come from word lists and pairs files of the user, input
+		// is typed freely or is transcript of speech, only scores are kept
+		result.Items = nil
+		result.Input = nil
+		result.Pairs = nil
+
+		results[index] = result
+	}
+
+	session.Results = results
+
+	// tags are free text too
+	session.Tags = nil
+
+	// profiles are named after OS users, pseudonyms keep sessions of
+	// different people apart
+	session.Profile = getPseudonym(salt, session.getProfile())
+
+	if session.Environment != nil {
+		environment := *session.Environment
+		environment.Hostname = ""
+		session.Environment = &environment
+	}
+
+	return session
+}
+
+// getPseudonym returns name which doesn't reveal the original one, it's the
+// same for the same salt.
+func getPseudonym(salt []byte, name string) string {
+	hash := hmac.New(sha256.New, salt)
+	hash.Write([]byte(name))
+	return "anonymous-" + hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// writeOutput writes content into specified file or into stdout if file is
+// "-", files named *.gz or *.zst are compressed.
+func writeOutput(output string, content []byte) error {
+	if output == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	return writeCompressed(output, content)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnonymizeSession(t *testing.T) {
+	session := Session{
+		Date:    time.Date(2024, 3, 5, 18, 42, 0, 0, time.UTC),
+		Tags:    []string{"after work at the office"},
+		Profile: "alice",
+		Results: []Result{{
+			Score: 1, Count: 2, Note: "tired",
+			Items: []string{"apple", "river"},
+			Input: []string{"apple", "my", "dog", "rex"},
+			Pairs: []Pair{{Key: "rex", Value: "dog", Answer: "cat"}},
+		}},
+		Environment: &Environment{
+			Hostname: "alice-laptop",
+		},
+	}
+
+	anonymized := anonymizeSession(session, []byte("salt"))
+
+	if anonymized.Tags != nil {
+		t.Errorf("tags are not removed: %v", anonymized.Tags)
+	}
+
+	if anonymized.Results[0].Note != "" {
+		t.Errorf("note is not removed: %q", anonymized.Results[0].Note)
+	}
+
+	result := anonymized.Results[0]
+	if result.Items != nil || result.Input != nil || result.Pairs != nil {
+		t.Errorf("recalled items are not removed: %+v", result)
+	}
+
+	if result.Score != 1 || result.Count != 2 {
+		t.Errorf("score is not kept: %+v", result)
+	}
+
+	if anonymized.Environment.Hostname != "" {
+		t.Errorf("hostname is not removed: %q", anonymized.Environment.Hostname)
+	}
+
+	if !anonymized.Date.Equal(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("time of day is not removed: %s", anonymized.Date)
+	}
+
+	if session.Tags == nil || session.Results[0].Input == nil {
+		t.Errorf("original session is changed")
+	}
+}
+
+func TestAnonymizeSessionKeepsProfilesApart(t *testing.T) {
+	salt := []byte("salt")
+
+	alice := anonymizeSession(Session{Profile: "alice"}, salt).Profile
+	bob := anonymizeSession(Session{Profile: "bob"}, salt).Profile
+
+	if alice == "alice" || alice == "" {
+		t.Errorf("profile is not replaced by pseudonym: %q", alice)
+	}
+
+	if alice == bob {
+		t.Errorf("different profiles have the same pseudonym %q", alice)
+	}
+
+	again := anonymizeSession(Session{Profile: "alice"}, salt).Profile
+	if again != alice {
+		t.Errorf("pseudonym is not stable: %q and %q", alice, again)
+	}
+
+	// pseudonyms of other exports can't be linked
+	other := anonymizeSession(Session{Profile: "alice"}, []byte("other"))
+	if other.Profile == alice {
+		t.Errorf("pseudonym doesn't depend on salt: %q", alice)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// feedbackDelay is how long feedback is shown before the next test, zero
+// means until Enter is pressed
+var feedbackDelay time.Duration
+
+// showFeedback shows specified lines (like correct and entered numbers) and
+// score after the test and lets the user attach a note to the test, returns
+// the note.
+func showFeedback(lines []styledLine, score, total int) (string, error) {
+	note := ""
+	for {
+		clearScreen()
+
+		_, height := screen.Size()
+		y := height/2 - len(lines)/2 - 2
+
+		for index, line := range lines {
+			printStyled(line, y+index)
+		}
+
+		bottom := y + len(lines)
+		printCentered(fmt.Sprintf("score: %d/%d", score, total), bottom)
+
+		if note != "" {
+			printCentered("note: "+note, bottom+2)
+		}
+
+		// once the note is added, the user is reading feedback, so it stays
+		delay := feedbackDelay
+		if note != "" {
+			delay = 0
+		}
+
+		prompt := "Enter: continue, n: add note"
+		if delay > 0 {
+			prompt = fmt.Sprintf(
+				"Enter: continue, n: add note (next test in %s sec)",
+				formatFloat(delay.Seconds(), 1),
+			)
+		}
+
+		printCentered(prompt, bottom+4)
+		screen.HideCursor()
+		screen.Flush()
+
+		key, err := waitKeyTimeout(delay, 'n')
+		if err != nil {
+			return "", err
+		}
+
+		if key == ui.KeyEnter {
+			clearScreen()
+			return note, nil
+		}
+
+		// Esc cancels the note only, the session goes on
+		edited, err := readLine("note:", y)
+		switch {
+		case err == errStopped:
+			continue
+		case err != nil:
+			return "", err
+		}
+
+		note = edited
+	}
+}
+
+// waitKey waits for Enter or one of specified characters, returns
+// KeyEnter or zero if a character was pressed.
+func waitKey(chars ...rune) (ui.Key, error) {
+	return waitKeyTimeout(0, chars...)
+}
+
+// waitKeyTimeout is waitKey which presses Enter by itself after the timeout,
+// zero timeout waits forever.
+func waitKeyTimeout(timeout time.Duration, chars ...rune) (ui.Key, error) {
+	deadline := time.Now().Add(timeout)
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, screen.Interrupt)
+		defer timer.Stop()
+	}
+
+	for {
+		event := pollEvent()
+
+		// interrupt could be left by the timer of previous test
+		if event.Type == ui.EventInterrupt && timeout > 0 &&
+			!time.Now().Before(deadline) {
+			return ui.KeyEnter, nil
+		}
+
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch event.Key {
+		case ui.KeyEnter:
+			return event.Key, nil
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return 0, errAborted
+		case ui.KeyEsc:
+			return 0, errStopped
+		}
+
+		for _, char := range chars {
+			if event.Ch == char {
+				return 0, nil
+			}
+		}
+
+		if event.Ch == 'p' {
+			pauseRequested = true
+		}
+	}
+}
+
+// readLine reads arbitrary line of text under the prompt, unlike readText
+// which accepts only numbers.
+func readLine(prompt string, y int) (string, error) {
+	text := []rune{}
+	for {
+		screen.Clear(ui.ColorDefault, ui.ColorDefault)
+		printCentered(prompt, y)
+		printCentered(string(text), y+1)
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch event.Key {
+		case ui.KeySpace:
+			text = append(text, ' ')
+		case ui.KeyBackspace:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case ui.KeyEnter:
+			return strings.TrimSpace(string(text)), nil
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return "", errAborted
+		case ui.KeyEsc:
+			return "", errStopped
+		default:
+			if event.Ch != 0 {
+				text = append(text, event.Ch)
+			}
+		}
+	}
+}
+
+func joinNumbers(numbers []int) string {
+	pieces := []string{}
+	for _, number := range numbers {
+		pieces = append(pieces, strconv.Itoa(number))
+	}
+
+	return strings.Join(pieces, " ")
+}
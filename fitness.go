@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// items of the longest test recalled without mistakes which give the full
+// span component
+const fitnessSpan = 12
+
+// seconds of recall per item which give the full and zero speed component
+const (
+	fitnessFastItem = 0.5
+	fitnessSlowItem = 3.0
+)
+
+// Fitness is [fitness] section of config, weights of components of memory
+// fitness index. Every component is from 0 to 100 and the index is their
+// weighted average:
+//   - span is the longest test recalled without mistakes, 12 items or more
+//     give 100;
+//   - accuracy is average accuracy of tests;
+//   - speed is recall time per item, 0.5 seconds or less give 100 and 3
+//     seconds or more give 0;
+//   - consistency is 100 minus doubled standard deviation of accuracy of
+//     tests in percents.
+type Fitness struct {
+	Span        float64 `toml:"span"`
+	Accuracy    float64 `toml:"accuracy"`
+	Speed       float64 `toml:"speed"`
+	Consistency float64 `toml:"consistency"`
+}
+
+// weights used when none of them is set in config
+var defaultFitness = Fitness{
+	Span:        0.35,
+	Accuracy:    0.35,
+	Speed:       0.15,
+	Consistency: 0.15,
+}
+
+func (fitness Fitness) validate() error {
+	if fitness.Span < 0 || fitness.Accuracy < 0 || fitness.Speed < 0 ||
+		fitness.Consistency < 0 {
+		return fmt.Errorf("weights can't be negative")
+	}
+
+	return nil
+}
+
+func (fitness Fitness) withDefaults() Fitness {
+	if fitness == (Fitness{}) {
+		return defaultFitness
+	}
+
+	return fitness
+}
+
+// getFitnessComponents returns span, accuracy, speed and consistency of the
+// session, false if its results are pruned.
+func getFitnessComponents(session Session) ([4]float64, bool) {
+	if len(session.Results) == 0 {
+		return [4]float64{}, false
+	}
+
+	var (
+		duration   float64
+		items      int
+		accuracies []float64
+	)
+
+	for _, result := range session.Results {
+		duration += result.Duration
+		items += result.Count
+		accuracies = append(accuracies, getAccuracy(result))
+	}
+
+	span := math.Min(float64(getMaxSpan(session))/fitnessSpan, 1) * 100
+
+	speed := 0.0
+	if items > 0 {
+		speed = (fitnessSlowItem - duration/float64(items)) /
+			(fitnessSlowItem - fitnessFastItem)
+	}
+
+	mean := getSessionAccuracy(session)
+
+	var variance float64
+	for _, accuracy := range accuracies {
+		variance += (accuracy - mean) * (accuracy - mean)
+	}
+
+	deviation := math.Sqrt(variance / float64(len(accuracies)))
+
+	return [4]float64{
+		span,
+		mean * 100,
+		clampFloat(speed, 0, 1) * 100,
+		clampFloat(1-2*deviation, 0, 1) * 100,
+	}, true
+}
+
+// getFitness returns weighted average of components of the session, false
+// if its results are pruned.
+func (fitness Fitness) getFitness(session Session) (float64, bool) {
+	components, ok := getFitnessComponents(session)
+	if !ok {
+		return 0, false
+	}
+
+	fitness = fitness.withDefaults()
+
+	weights := [4]float64{
+		fitness.Span, fitness.Accuracy, fitness.Speed, fitness.Consistency,
+	}
+
+	var sum, total float64
+	for index, weight := range weights {
+		sum += components[index] * weight
+		total += weight
+	}
+
+	return sum / total, true
+}
+
+// getAverageFitness returns average index of sessions, false if there are
+// no sessions with results.
+func (fitness Fitness) getAverageFitness(sessions []Session) (float64, bool) {
+	var sum float64
+	count := 0
+	for _, session := range sessions {
+		if value, ok := fitness.getFitness(session); ok {
+			sum += value
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+
+	return sum / float64(count), true
+}
+
+// printFitness plots index of the last sessions and shows components of
+// the latest one with their weights.
+func printFitness(database Database, fitness Fitness, last int) {
+	sessions := append([]Session{}, database.Sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Date.Before(sessions[j].Date)
+	})
+
+	if len(sessions) > last {
+		sessions = sessions[len(sessions)-last:]
+	}
+
+	values := []float64{}
+	latest := Session{}
+	for _, session := range sessions {
+		if value, ok := fitness.getFitness(session); ok {
+			values = append(values, value)
+			latest = session
+		}
+	}
+
+	if len(values) == 0 {
+		fmt.Println("no tests in the last sessions")
+		return
+	}
+
+	for _, line := range renderLine(values, plotWidth, plotHeight) {
+		fmt.Println(line)
+	}
+
+	fmt.Printf(
+		"\nfitness of the last %d sessions, the latest one is %s:\n",
+		len(values), formatFloat(values[len(values)-1], 1),
+	)
+
+	fitness = fitness.withDefaults()
+	components, _ := getFitnessComponents(latest)
+	for index, component := range []struct {
+		name   string
+		weight float64
+	}{
+		{"span", fitness.Span},
+		{"accuracy", fitness.Accuracy},
+		{"speed", fitness.Speed},
+		{"consistency", fitness.Consistency},
+	} {
+		fmt.Printf(
+			"%-12s %6s, weight %s\n",
+			component.name+":", formatFloat(components[index], 1),
+			formatFloat(component.weight, 2),
+		)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// focus tracking state, terminal reports when its window gains or loses
+// focus
+var focus struct {
+	blank  bool
+	lost   time.Time
+	paused time.Duration
+	screen []ui.Cell
+}
+
+func enableFocusReporting(blank bool) {
+	focus.blank = blank
+
+	screen.EnableFocus()
+}
+
+// getPausedDuration returns total time the terminal was out of focus.
+func getPausedDuration() time.Duration {
+	return focus.paused
+}
+
+// pollEvent is PollEvent of the terminal which hides focus events and
+// pauses the application while the terminal is out of focus, content of
+// the screen is recentered when the terminal is resized. After termination
+// signal it returns Ctrl+C.
+func pollEvent() ui.Event {
+	for {
+		if isSignaled() {
+			return ui.Event{Type: ui.EventKey, Key: ui.KeyCtrlC}
+		}
+
+		event := screen.PollEvent()
+		switch event.Type {
+		case ui.EventResize:
+			recenterScreen()
+			return event
+
+		case ui.EventFocus:
+			if event.Focused {
+				onFocusGained()
+			} else {
+				onFocusLost()
+			}
+
+		default:
+			return event
+		}
+	}
+}
+
+func onFocusLost() {
+	if !focus.lost.IsZero() {
+		return
+	}
+
+	focus.lost = clock()
+
+	if focus.blank {
+		focus.screen = append([]ui.Cell{}, screen.CellBuffer()...)
+
+		_, height := screen.Size()
+
+		screen.Clear(ui.ColorDefault, ui.ColorDefault)
+		printCentered("paused", height/2)
+		screen.HideCursor()
+		screen.Flush()
+	}
+}
+
+func onFocusGained() {
+	if focus.lost.IsZero() {
+		return
+	}
+
+	focus.paused += clock().Sub(focus.lost)
+	focus.lost = time.Time{}
+
+	if focus.blank && focus.screen != nil {
+		width, _ := screen.Size()
+		for index, cell := range focus.screen {
+			screen.SetCell(
+				index%width, index/width, cell.Ch, cell.Fg, cell.Bg,
+			)
+		}
+
+		focus.screen = nil
+		screen.Flush()
+	}
+}
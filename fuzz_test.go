@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fuzz targets feed arbitrary input into parsers of user- or
+// file-controlled data, the parser may reject input, but must never panic.
+// Seeds are run by go test, inputs are mutated by go test -fuzz.
+
+func addSeeds(f *testing.F, seeds ...string) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+}
+
+func FuzzRecallInput(f *testing.F) {
+	addSeeds(f, "12 34 56", "1  2", "", " 99")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parseNumbers(data)
+	})
+}
+
+func FuzzRecalledDate(f *testing.F) {
+	addSeeds(f, "2006-01-02", "02.01.2006", "01/02/2006", "2 Jan 2006")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		for _, format := range dateFormats {
+			parseRecalledDate(data, format)
+		}
+	})
+}
+
+func FuzzTranscript(f *testing.F) {
+	addSeeds(f, "forty two, 17 and five", "twenty-one oh nine")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parseTranscript(data)
+	})
+}
+
+func FuzzLengths(f *testing.F) {
+	addSeeds(f, "5,7,9", "7", ",", " 3 , 4")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parseLengths(data)
+	})
+}
+
+func FuzzDuration(f *testing.F) {
+	addSeeds(f, "2h", "90d", "1w", "1h30m")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parseDuration(data)
+	})
+}
+
+func FuzzWhere(f *testing.F) {
+	addSeeds(f, "date<2025-01-01", "score>=80", "tag!=legacy")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		condition, err := parseWhere(data)
+		if err == nil {
+			condition.match(Session{Results: []Result{{Count: 1}}})
+		}
+	})
+}
+
+func FuzzSummaryTemplate(f *testing.F) {
+	addSeeds(f, "avg={avg_score} span={max_span}", "{{}}", "{tests")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		template, err := parseTemplate(data)
+		if err == nil {
+			template.render(getTemplateValues(
+				Session{Results: []Result{{Count: 1}}}, defaultFitness,
+			))
+		}
+	})
+}
+
+func FuzzCharset(f *testing.F) {
+	addSeeds(f, "letters", "custom:ABC", "custom:", "custom:a a")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		charset, err := parseCharset(data)
+		if err == nil {
+			charset.parseTokens(data)
+			charset.generateTokens(nil, 0, 9, 3)
+		}
+	})
+}
+
+func FuzzChallengeCode(f *testing.F) {
+	addSeeds(f, "AEBAIDQOAYAAC2", "", "aebaidqo")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		decodeChallenge(data)
+	})
+}
+
+func FuzzPairs(f *testing.F) {
+	addSeeds(f, "key\tvalue\n# comment\n\nNASA\tspace agency\n")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parsePairs(bytes.NewReader([]byte(data)), "fuzz")
+	})
+}
+
+func FuzzDatabase(f *testing.F) {
+	f.Add([]byte(
+		`{"version":2,"sessions":[{"date":"2020-01-01T12:00:00Z",` +
+			`"results":[{"score":7,"duration":3.5,"count":7}]}]}`,
+	))
+	f.Add([]byte(
+		`[{"date":"2019-01-01 12:00:00 +0000 UTC m=+1.5",` +
+			`"avgDuration":3,"totalScore":10,"results":[{"score":5}]}]`,
+	))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decodeDatabase(data)
+	})
+}
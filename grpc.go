@@ -0,0 +1,138 @@
+//go:build grpc
+// +build grpc
+
+package main
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/kovetskiy/short/enginepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcEngine is gRPC service of the engine, it's served by serve --grpc
+type grpcEngine struct {
+	enginepb.UnimplementedEngineServer
+
+	engine *engine
+}
+
+func init() {
+	serveGRPC = func(file string, args map[string]interface{}) error {
+		address := args["--listen"].(string)
+
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+
+		server := grpc.NewServer()
+		enginepb.RegisterEngineServer(server, &grpcEngine{
+			engine: newEngine(file, expandHome(args["--config"].(string))),
+		})
+
+		log.info("serving grpc engine", Fields{"address": address})
+
+		return server.Serve(listener)
+	}
+}
+
+func (service *grpcEngine) StartSession(
+	_ context.Context, request *enginepb.StartSessionRequest,
+) (*enginepb.StartSessionResponse, error) {
+	id, tests, err := service.engine.startSession(request.GetOptions())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &enginepb.StartSessionResponse{
+		SessionId: id,
+		Tests:     int32(tests),
+	}, nil
+}
+
+func (service *grpcEngine) NextTrial(
+	_ context.Context, request *enginepb.NextTrialRequest,
+) (*enginepb.Trial, error) {
+	trial, err := service.engine.nextTrial(request.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &enginepb.Trial{
+		Index:           int32(trial.Index),
+		Tests:           int32(trial.Tests),
+		Mode:            trial.Mode,
+		Items:           trial.Items,
+		ExposureSeconds: trial.Exposure.Seconds(),
+		RateSeconds:     trial.Rate.Seconds(),
+		GridSide:        int32(trial.Side),
+	}, nil
+}
+
+func (service *grpcEngine) SubmitRecall(
+	_ context.Context, request *enginepb.SubmitRecallRequest,
+) (*enginepb.Recall, error) {
+	recall, err := service.engine.submitRecall(
+		request.GetSessionId(), request.GetInput(),
+		time.Duration(request.GetStudySeconds()*float64(time.Second)),
+	)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return &enginepb.Recall{
+		Score:    int32(recall.Score),
+		Count:    int32(recall.Count),
+		Expected: recall.Expected,
+		Finished: recall.Finished,
+	}, nil
+}
+
+// GetStats returns stats of any profile without authentication, so they
+// are served to frontends on the same host only.
+func (service *grpcEngine) GetStats(
+	ctx context.Context, request *enginepb.GetStatsRequest,
+) (*enginepb.Stats, error) {
+	if !isLocalPeer(ctx) {
+		return nil, status.Error(
+			codes.PermissionDenied,
+			"stats are served to frontends on the same host only",
+		)
+	}
+
+	modes, err := service.engine.getStats(request.GetProfile())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	stats := &enginepb.Stats{}
+	for _, mode := range modes {
+		stats.Modes = append(stats.Modes, &enginepb.ModeStats{
+			Mode:         mode.Mode,
+			Sessions:     int32(mode.Sessions),
+			Tests:        int32(mode.Tests),
+			BestSpan:     int32(mode.BestSpan),
+			BestAccuracy: mode.BestAccuracy,
+		})
+	}
+
+	return stats, nil
+}
+
+// isLocalPeer tells if the request is sent from loopback address.
+func isLocalPeer(ctx context.Context) bool {
+	client, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	address, ok := client.Addr.(*net.TCPAddr)
+
+	return ok && address.IP.IsLoopback()
+}
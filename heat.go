@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// getPositionAccuracy returns accuracy at every serial position of tests of
+// specified mode, positions without history are missing from the end.
+func getPositionAccuracy(database Database, mode string) []float64 {
+	correct := []int{}
+	attempts := []int{}
+
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.getMode() != mode {
+				continue
+			}
+
+			for position := 0; position < result.Count; position++ {
+				isCorrect, ok := isCorrectAt(result, position)
+				if !ok {
+					continue
+				}
+
+				for len(attempts) <= position {
+					attempts = append(attempts, 0)
+					correct = append(correct, 0)
+				}
+
+				attempts[position]++
+				if isCorrect {
+					correct[position]++
+				}
+			}
+		}
+	}
+
+	accuracy := []float64{}
+	for position := range attempts {
+		accuracy = append(
+			accuracy, float64(correct[position])/float64(attempts[position]),
+		)
+	}
+
+	return accuracy
+}
+
+// isCorrectAt tells if item at position was recalled correctly, returns
+// false as second value if result doesn't have this information.
+func isCorrectAt(result Result, position int) (bool, bool) {
+	switch result.getMode() {
+	case modeDigits, modeReverse:
+		// numbers are compared until the first mistake, positions of reverse
+		// mode are counted in order of recall
+		return position < result.Score, true
+
+	case modeChess, modeSentence, modeDates, modeWords, modeSpatial:
+		if position >= len(result.Items) {
+			return false, false
+		}
+
+		if position >= len(result.Input) {
+			return false, true
+		}
+
+		item, input := result.Items[position], result.Input[position]
+		switch result.getMode() {
+		case modeSentence, modeWords:
+			return normalizeWord(item) == normalizeWord(input), true
+		case modeDates:
+			return isSameDate(item, input), true
+		default:
+			return item == input, true
+		}
+	}
+
+	return false, false
+}
+
+// isSameDate compares shown and recalled dates in any known formats.
+func isSameDate(shown, recalled string) bool {
+	for _, format := range dateFormats {
+		date, err := time.Parse(format, shown)
+		if err != nil {
+			continue
+		}
+
+		parsed, ok := parseRecalledDate(recalled, format)
+
+		return ok && parsed.Equal(date)
+	}
+
+	return false
+}
+
+// getHeatLine colors every item by historical accuracy at its position.
+func getHeatLine(
+	label string, items []string, separator string, accuracy []float64,
+) styledLine {
+	line := styledLine{{label, theme.text}}
+	for index, item := range items {
+		color := theme.text
+		if index < len(accuracy) {
+			color = getHeatColor(accuracy[index])
+		}
+
+		if index > 0 {
+			line = append(line, span{separator, theme.text})
+		}
+
+		line = append(line, span{item, color})
+	}
+
+	return line
+}
+
+// getCorrectLine returns line with correct items, colored by accuracy at
+// positions if --heat is specified.
+func getCorrectLine(
+	options Options, mode string, items []string, separator string,
+) styledLine {
+	if !options.Heat {
+		return styledLine{
+			{"correct: " + strings.Join(items, separator), theme.text},
+		}
+	}
+
+	return getHeatLine(
+		"correct: ", items, separator, options.PositionAccuracy[mode],
+	)
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// history is state of the history browser
+type history struct {
+	file     string
+	database Database
+
+	query      Query
+	order      string
+	descending bool
+
+	indexes  []int
+	selected int
+	offset   int
+}
+
+// runHistory shows browser of recorded sessions, which allows to sort and
+// filter sessions, see tests of the session, tag and delete sessions.
+func runHistory(file string) error {
+	release, err := acquireLock(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	history := &history{
+		file:       file,
+		database:   database,
+		order:      orderDate,
+		descending: true,
+	}
+
+	history.update()
+
+	err = screen.Init()
+	if err != nil {
+		return err
+	}
+
+	defer screen.Close()
+
+	for {
+		history.draw()
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyArrowUp || event.Ch == 'k':
+			history.move(-1)
+		case event.Key == ui.KeyArrowDown || event.Ch == 'j':
+			history.move(1)
+		case event.Key == ui.KeyPgup:
+			history.move(-history.getPageSize())
+		case event.Key == ui.KeyPgdn:
+			history.move(history.getPageSize())
+		case event.Ch == 's':
+			next := (indexOf(orders, history.order) + 1) % len(orders)
+			history.order = orders[next]
+			history.update()
+		case event.Ch == 'r':
+			history.descending = !history.descending
+			history.update()
+		case event.Ch == 'm':
+			history.cycleMode()
+		case event.Ch == 'f':
+			err = history.filterTag()
+		case event.Ch == 't':
+			err = history.tag()
+		case event.Ch == 'd':
+			err = history.delete()
+		case event.Key == ui.KeyEnter:
+			err = history.showDetails()
+		case event.Ch == 'q', event.Key == ui.KeyEsc,
+			event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return nil
+		}
+
+		if err == errAborted {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for index, known := range values {
+		if known == value {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// update runs the query again, keeping the selected row in the list.
+func (history *history) update() {
+	history.indexes = findSessions(
+		history.database, history.query, history.order, history.descending,
+	)
+
+	if history.selected >= len(history.indexes) {
+		history.selected = len(history.indexes) - 1
+	}
+
+	if history.selected < 0 {
+		history.selected = 0
+	}
+
+	history.move(0)
+}
+
+func (history *history) move(delta int) {
+	history.selected = clamp(
+		history.selected+delta, 0, len(history.indexes)-1,
+	)
+	if history.selected < 0 {
+		history.selected = 0
+	}
+
+	page := history.getPageSize()
+	if history.selected < history.offset {
+		history.offset = history.selected
+	}
+
+	if history.selected >= history.offset+page {
+		history.offset = history.selected - page + 1
+	}
+}
+
+// getPageSize returns count of sessions fitting the screen, two lines are
+// taken by header and two by help.
+func (history *history) getPageSize() int {
+	_, height := screen.Size()
+	if height < 5 {
+		return 1
+	}
+
+	return height - 4
+}
+
+func (history *history) draw() {
+	_, height := screen.Size()
+
+	screen.Clear(ui.ColorDefault, ui.ColorDefault)
+
+	direction := "ascending"
+	if history.descending {
+		direction = "descending"
+	}
+
+	mode := history.query.Mode
+	if mode == "" {
+		mode = "any"
+	}
+
+	tag := history.query.Tag
+	if tag == "" {
+		tag = "any"
+	}
+
+	printText(
+		fmt.Sprintf(
+			"%d of %d sessions, by %s %s, mode: %s, tag: %s",
+			len(history.indexes), len(history.database.Sessions),
+			history.order, direction, mode, tag,
+		),
+		0, 0,
+	)
+
+	page := history.getPageSize()
+	for row := 0; row < page; row++ {
+		position := history.offset + row
+		if position >= len(history.indexes) {
+			break
+		}
+
+		session := history.database.Sessions[history.indexes[position]]
+
+		marker := " "
+		if position == history.selected {
+			marker = ">"
+		}
+
+		printText(marker+" "+formatHistorySession(session), 0, row+2)
+	}
+
+	printText(
+		"up/down move, enter tests, s sort, r reverse, m mode, "+
+			"f filter by tag, t tag, d delete, q quit",
+		0, height-1,
+	)
+}
+
+func formatHistorySession(session Session) string {
+	line := fmt.Sprintf(
+		"%s  %-8s %3d tests %6.1f%%",
+		session.Date.Local().Format("2006-01-02 15:04"),
+		getSessionMode(session), getSessionTests(session),
+		getSessionAccuracy(session)*100,
+	)
+
+	if session.Practice {
+		line += "  practice"
+	}
+
+	if session.Aborted {
+		line += "  aborted"
+	}
+
+	if len(session.Tags) > 0 {
+		line += "  [" + strings.Join(session.Tags, ", ") + "]"
+	}
+
+	return line
+}
+
+func (history *history) cycleMode() {
+	modes := append([]string{""}, modes...)
+
+	index := indexOf(modes, history.query.Mode)
+	history.query.Mode = modes[(index+1)%len(modes)]
+	history.update()
+}
+
+func (history *history) getSelected() (int, bool) {
+	if len(history.indexes) == 0 {
+		return 0, false
+	}
+
+	return history.indexes[history.selected], true
+}
+
+func (history *history) filterTag() error {
+	_, height := screen.Size()
+
+	tag, err := readLine("show sessions with tag, empty for all:", height/2)
+	if err != nil {
+		return err
+	}
+
+	history.query.Tag = tag
+	history.update()
+
+	return nil
+}
+
+// tag adds tag to the selected session, tag prefixed with minus is removed.
+func (history *history) tag() error {
+	index, ok := history.getSelected()
+	if !ok {
+		return nil
+	}
+
+	_, height := screen.Size()
+
+	tag, err := readLine("tag to add, -tag to remove:", height/2)
+	if err != nil || tag == "" {
+		return err
+	}
+
+	session := &history.database.Sessions[index]
+	session.Tags = changeTags(session.Tags, tag)
+
+	history.update()
+
+	return saveDatabase(history.file, history.database)
+}
+
+// changeTags adds tag to tags or removes it if it's prefixed with minus.
+func changeTags(tags []string, tag string) []string {
+	remove := strings.HasPrefix(tag, "-")
+	tag = strings.TrimPrefix(tag, "-")
+
+	changed := []string{}
+	for _, known := range tags {
+		if known != tag {
+			changed = append(changed, known)
+		}
+	}
+
+	if !remove {
+		changed = append(changed, tag)
+	}
+
+	return changed
+}
+
+func (history *history) delete() error {
+	index, ok := history.getSelected()
+	if !ok {
+		return nil
+	}
+
+	_, height := screen.Size()
+
+	screen.Clear(ui.ColorDefault, ui.ColorDefault)
+	printCentered(
+		formatHistorySession(history.database.Sessions[index]), height/2-1,
+	)
+	printCentered("delete this session? y/n", height/2+1)
+
+	confirmed, err := waitConfirmation()
+	if err != nil || !confirmed {
+		return err
+	}
+
+	sessions := history.database.Sessions
+	history.database.Sessions = append(
+		sessions[:index:index], sessions[index+1:]...,
+	)
+
+	history.update()
+
+	return saveDatabase(history.file, history.database)
+}
+
+// waitConfirmation waits for y or n.
+func waitConfirmation() (bool, error) {
+	for {
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Ch == 'y':
+			return true, nil
+		case event.Ch == 'n', event.Key == ui.KeyEsc:
+			return false, nil
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return false, errAborted
+		}
+	}
+}
+
+// showDetails shows tests of the selected session until Enter, Esc or q is
+// pressed.
+func (history *history) showDetails() error {
+	index, ok := history.getSelected()
+	if !ok {
+		return nil
+	}
+
+	session := history.database.Sessions[index]
+
+	lines := []string{formatHistorySession(session), ""}
+	for number, result := range session.Results {
+		lines = append(lines, formatHistoryResult(number+1, result))
+	}
+
+	offset := 0
+	for {
+		_, height := screen.Size()
+
+		screen.Clear(ui.ColorDefault, ui.ColorDefault)
+		for row := 0; row < height-2 && offset+row < len(lines); row++ {
+			printText(lines[offset+row], 0, row)
+		}
+
+		printText("up/down scroll, enter or q back", 0, height-1)
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyArrowUp || event.Ch == 'k':
+			offset = clamp(offset-1, 0, len(lines)-1)
+		case event.Key == ui.KeyArrowDown || event.Ch == 'j':
+			offset = clamp(offset+1, 0, len(lines)-1)
+		case event.Key == ui.KeyEnter, event.Key == ui.KeyEsc,
+			event.Ch == 'q':
+			return nil
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return errAborted
+		}
+	}
+}
+
+func formatHistoryResult(number int, result Result) string {
+	line := fmt.Sprintf(
+		"%3d. %-8s %2d of %-2d %6.2f sec",
+		number, result.getMode(), result.Score, result.Count, result.Duration,
+	)
+
+	if len(result.Items) > 0 {
+		line += "  shown: " + strings.Join(result.Items, " ")
+	}
+
+	if len(result.Input) > 0 {
+		line += "  entered: " + strings.Join(result.Input, " ")
+	}
+
+	if result.Note != "" {
+		line += "  note: " + result.Note
+	}
+
+	return line
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weeks of the plan after the current one put into calendar, their count
+// of numbers is not known yet, it grows only after good weeks
+const icalPlanWeeks = 4
+
+// iCalendar lines are folded at 75 octets
+const icalLineLength = 75
+
+// encodeICal writes completed sessions and weeks of the plan as iCalendar
+// events, so training is seen in calendar apps.
+func encodeICal(
+	database Database, plan Plan, now time.Time, weekStart time.Weekday,
+) []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kovetskiy//short//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:short",
+	}
+
+	stamp := formatICalTime(now)
+
+	if plan.isDefined() {
+		for _, week := range plan.getWeeks(
+			database, now.AddDate(0, 0, 7*icalPlanWeeks), weekStart,
+		) {
+			lines = append(lines, getPlanEvent(plan, week, stamp)...)
+		}
+	}
+
+	for _, session := range database.Sessions {
+		lines = append(lines, getSessionEvent(session, stamp)...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	content := ""
+	for _, line := range lines {
+		content += foldICalLine(line) + "\r\n"
+	}
+
+	return []byte(content)
+}
+
+// getPlanEvent returns all-day event lasting the week of the plan.
+func getPlanEvent(plan Plan, week PlanWeek, stamp string) []string {
+	sessions := ""
+	if plan.SessionsPerWeek > 0 {
+		sessions = fmt.Sprintf("%d sessions of ", plan.SessionsPerWeek)
+	}
+
+	return []string{
+		"BEGIN:VEVENT",
+		"UID:plan-" + week.Start.Format("20060102") + "@short",
+		"DTSTAMP:" + stamp,
+		"DTSTART;VALUE=DATE:" + week.Start.Format("20060102"),
+		"DTEND;VALUE=DATE:" + week.Start.AddDate(0, 0, 7).Format("20060102"),
+		"SUMMARY:" + escapeICal(fmt.Sprintf(
+			"short plan: %s-n %d -c %d", sessions, week.Tests, week.Count,
+		)),
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+	}
+}
+
+// getSessionEvent returns event lasting as long as tests of the session.
+func getSessionEvent(session Session, stamp string) []string {
+	duration := 0.0
+	for _, result := range session.Results {
+		duration += result.Duration
+	}
+
+	// calendars hide too short events
+	end := session.Date.Add(time.Duration(duration * float64(time.Second)))
+	if end.Sub(session.Date) < time.Minute {
+		end = session.Date.Add(time.Minute)
+	}
+
+	summary := fmt.Sprintf(
+		"short: %s, accuracy %s%%", getSessionMode(session),
+		formatFloat(getSessionAccuracy(session)*100, 1),
+	)
+	if session.Practice {
+		summary += ", practice"
+	}
+
+	return []string{
+		"BEGIN:VEVENT",
+		"UID:session-" + session.Date.UTC().Format("20060102T150405.000000000") +
+			"@short",
+		"DTSTAMP:" + stamp,
+		"DTSTART:" + formatICalTime(session.Date),
+		"DTEND:" + formatICalTime(end),
+		"SUMMARY:" + escapeICal(summary),
+		"DESCRIPTION:" + escapeICal(fmt.Sprintf(
+			"%d tests, total score %d, %s sec per test",
+			len(session.Results), session.TotalScore,
+			formatFloat(session.AvgDuration, 2),
+		)),
+		"END:VEVENT",
+	}
+}
+
+func formatICalTime(date time.Time) string {
+	return date.UTC().Format("20060102T150405Z")
+}
+
+func escapeICal(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`,
+	).Replace(text)
+}
+
+// foldICalLine splits long line into lines starting with space, splitting
+// doesn't break multibyte characters.
+func foldICalLine(line string) string {
+	folded := ""
+	length := 0
+	for _, symbol := range line {
+		size := len(string(symbol))
+		if length+size > icalLineLength {
+			folded += "\r\n "
+			length = 1
+		}
+
+		folded += string(symbol)
+		length += size
+	}
+
+	return folded
+}
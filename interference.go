@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// counting backwards starts from a number in this range
+const (
+	minInterferenceStart = 100
+	maxInterferenceStart = 999
+	interferenceStep     = 3
+)
+
+// modes whose items are hidden before recall, so recall can be delayed by
+// interference
+var interferenceModes = []string{
+	modeDigits, modeReverse, modeChess, modeDates, modeWords, modeSpatial,
+	modeMapping, modeAssociation,
+}
+
+// Interference is distractor task solved between exposure and recall
+type Interference struct {
+	Seconds  float64 `json:"seconds"`
+	Problems int     `json:"problems"`
+	Solved   int     `json:"solved"`
+}
+
+// interfere asks to count backwards by three for the interference time
+// after items are hidden, so they can't be rehearsed before recall, like in
+// Brown-Peterson task. Returns nil if there is no interference.
+func interfere(options Options) (*Interference, error) {
+	if options.Interference == 0 {
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(options.Interference)
+
+	timer := time.AfterFunc(options.Interference, screen.Interrupt)
+	defer timer.Stop()
+
+	interference := &Interference{Seconds: options.Interference.Seconds()}
+
+	number := minInterferenceStart +
+		randomInt(maxInterferenceStart-minInterferenceStart+1)
+
+	for {
+		answer, done, err := readInterferenceAnswer(
+			fmt.Sprintf("%d - %d =", number, interferenceStep), deadline,
+		)
+		if err != nil || done {
+			return interference, err
+		}
+
+		// counting goes on from the right number after mistakes
+		number -= interferenceStep
+
+		interference.Problems++
+		if answer == strconv.Itoa(number) {
+			interference.Solved++
+		}
+	}
+}
+
+// readInterferenceAnswer reads answer to the problem, returns true if the
+// interference time is over before Enter is pressed.
+func readInterferenceAnswer(
+	problem string, deadline time.Time,
+) (string, bool, error) {
+	answer := ""
+	for {
+		_, height := screen.Size()
+
+		clearScreen()
+		printCentered(problem, height/2-1)
+		printCentered(answer, height/2)
+		printCentered("count backwards, then Enter", height-1)
+		screen.HideCursor()
+		screen.Flush()
+
+		event := pollEvent()
+
+		switch event.Type {
+		case ui.EventInterrupt:
+			// interrupt could be left by the timer of previous test
+			if !time.Now().Before(deadline) {
+				return "", true, nil
+			}
+
+		case ui.EventKey:
+			switch event.Key {
+			case ui.KeyEnter:
+				return answer, false, nil
+			case ui.KeyBackspace:
+				if len(answer) > 0 {
+					answer = answer[:len(answer)-1]
+				}
+			case ui.KeyCtrlC, ui.KeyCtrlZ:
+				return "", false, errAborted
+			case ui.KeyEsc:
+				return "", false, errStopped
+			default:
+				if event.Ch >= '0' && event.Ch <= '9' {
+					answer += string(event.Ch)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,259 @@
+//go:build !termbox
+// +build !termbox
+
+package ui
+
+import (
+	"errors"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// tcellTerminal is terminal on top of tcell, which handles resizing, wide
+// runes, focus reporting and Windows consoles.
+type tcellTerminal struct {
+	screen tcell.Screen
+
+	// back buffer, it's drawn on the screen by Flush
+	cells  []Cell
+	width  int
+	height int
+
+	cursorX int
+	cursorY int
+}
+
+// special keys, control keys have the same ASCII codes
+var tcellKeys = map[tcell.Key]Key{
+	tcell.KeyUp:         KeyArrowUp,
+	tcell.KeyDown:       KeyArrowDown,
+	tcell.KeyLeft:       KeyArrowLeft,
+	tcell.KeyRight:      KeyArrowRight,
+	tcell.KeyPgUp:       KeyPgup,
+	tcell.KeyPgDn:       KeyPgdn,
+	tcell.KeyBackspace2: KeyBackspace,
+}
+
+var errTerminalClosed = errors.New("terminal is closed")
+
+// New returns terminal on top of tcell, it's started by Init.
+func New() Terminal {
+	return &tcellTerminal{cursorX: -1, cursorY: -1}
+}
+
+func (terminal *tcellTerminal) Init() error {
+	tcellScreen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	err = tcellScreen.Init()
+	if err != nil {
+		return err
+	}
+
+	terminal.screen = tcellScreen
+	terminal.resize()
+
+	return nil
+}
+
+func (terminal *tcellTerminal) Close() {
+	if terminal.screen != nil {
+		terminal.screen.DisableFocus()
+		terminal.screen.Fini()
+		terminal.screen = nil
+	}
+}
+
+func (terminal *tcellTerminal) IsInit() bool {
+	return terminal.screen != nil
+}
+
+// Size returns size of the back buffer, it's synced with the terminal by
+// Clear and Flush.
+func (terminal *tcellTerminal) Size() (int, int) {
+	return terminal.width, terminal.height
+}
+
+func (terminal *tcellTerminal) EnableFocus() {
+	if terminal.screen != nil {
+		terminal.screen.EnableFocus()
+	}
+}
+
+func (terminal *tcellTerminal) SetCell(
+	x, y int, symbol rune, fg, bg Attribute,
+) {
+	if x < 0 || y < 0 || x >= terminal.width || y >= terminal.height {
+		return
+	}
+
+	terminal.cells[y*terminal.width+x] = Cell{Ch: symbol, Fg: fg, Bg: bg}
+}
+
+func (terminal *tcellTerminal) SetCursor(x, y int) {
+	terminal.cursorX, terminal.cursorY = x, y
+}
+
+func (terminal *tcellTerminal) HideCursor() {
+	terminal.cursorX, terminal.cursorY = -1, -1
+}
+
+func (terminal *tcellTerminal) CellBuffer() []Cell {
+	return terminal.cells
+}
+
+func (terminal *tcellTerminal) Clear(fg, bg Attribute) error {
+	terminal.resize()
+
+	for index := range terminal.cells {
+		terminal.cells[index] = Cell{Ch: ' ', Fg: fg, Bg: bg}
+	}
+
+	return nil
+}
+
+func (terminal *tcellTerminal) Flush() error {
+	if terminal.screen == nil {
+		return errTerminalClosed
+	}
+
+	terminal.resize()
+
+	for y := 0; y < terminal.height; y++ {
+		for x := 0; x < terminal.width; x++ {
+			cell := terminal.cells[y*terminal.width+x]
+
+			symbol := cell.Ch
+			if symbol == 0 {
+				symbol = ' '
+			}
+
+			terminal.screen.SetContent(
+				x, y, symbol, nil, getTcellStyle(cell.Fg, cell.Bg),
+			)
+
+			// wide rune takes the next cell too
+			if runewidth.RuneWidth(symbol) == 2 {
+				x++
+			}
+		}
+	}
+
+	if terminal.cursorX < 0 {
+		terminal.screen.HideCursor()
+	} else {
+		terminal.screen.ShowCursor(terminal.cursorX, terminal.cursorY)
+	}
+
+	terminal.screen.Show()
+
+	return nil
+}
+
+// PollEvent waits for the next event, closed terminal is reported as
+// Ctrl+C, so the application stops.
+func (terminal *tcellTerminal) PollEvent() Event {
+	for {
+		if terminal.screen == nil {
+			return Event{Type: EventKey, Key: KeyCtrlC}
+		}
+
+		switch event := terminal.screen.PollEvent().(type) {
+		case nil:
+			return Event{Type: EventKey, Key: KeyCtrlC}
+
+		case *tcell.EventKey:
+			return getTcellKey(event)
+
+		case *tcell.EventResize:
+			width, height := event.Size()
+			return Event{
+				Type: EventResize, Width: width, Height: height,
+			}
+
+		case *tcell.EventInterrupt:
+			return Event{Type: EventInterrupt}
+
+		case *tcell.EventFocus:
+			return Event{Type: EventFocus, Focused: event.Focused}
+		}
+	}
+}
+
+func (terminal *tcellTerminal) Interrupt() {
+	if terminal.screen != nil {
+		terminal.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}
+}
+
+// resize syncs size of the back buffer with the terminal, content of the
+// buffer is kept where it fits.
+func (terminal *tcellTerminal) resize() {
+	if terminal.screen == nil {
+		return
+	}
+
+	width, height := terminal.screen.Size()
+	if width == terminal.width && height == terminal.height {
+		return
+	}
+
+	cells := make([]Cell, width*height)
+	for y := 0; y < height && y < terminal.height; y++ {
+		for x := 0; x < width && x < terminal.width; x++ {
+			cells[y*width+x] = terminal.cells[y*terminal.width+x]
+		}
+	}
+
+	terminal.cells = cells
+	terminal.width, terminal.height = width, height
+}
+
+func getTcellKey(event *tcell.EventKey) Event {
+	result := Event{Type: EventKey}
+
+	switch {
+	case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+		result.Key = KeySpace
+	case event.Key() == tcell.KeyRune:
+		result.Ch = event.Rune()
+	case event.Key() < tcell.KeyRune && event.Key() != tcell.KeyBackspace2:
+		result.Key = Key(event.Key())
+	default:
+		result.Key = tcellKeys[event.Key()]
+	}
+
+	return result
+}
+
+func getTcellStyle(fg, bg Attribute) tcell.Style {
+	style := tcell.StyleDefault.
+		Foreground(getTcellColor(fg)).
+		Background(getTcellColor(bg))
+
+	if fg&AttrBold != 0 {
+		style = style.Bold(true)
+	}
+
+	if fg&AttrUnderline != 0 {
+		style = style.Underline(true)
+	}
+
+	if fg&AttrReverse != 0 {
+		style = style.Reverse(true)
+	}
+
+	return style
+}
+
+func getTcellColor(attribute Attribute) tcell.Color {
+	color := attribute & (AttrBold - 1)
+	if color == ColorDefault {
+		return tcell.ColorDefault
+	}
+
+	return tcell.PaletteColor(int(color) - 1)
+}
@@ -0,0 +1,158 @@
+//go:build termbox
+// +build termbox
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/nsf/termbox-go"
+)
+
+// termboxTerminal is terminal on top of termbox, it's built by go build
+// -tags termbox for terminals where tcell doesn't work.
+type termboxTerminal struct {
+	focus bool
+}
+
+// special keys, control keys have the same ASCII codes
+var termboxKeys = map[termbox.Key]Key{
+	termbox.KeyArrowUp:    KeyArrowUp,
+	termbox.KeyArrowDown:  KeyArrowDown,
+	termbox.KeyArrowLeft:  KeyArrowLeft,
+	termbox.KeyArrowRight: KeyArrowRight,
+	termbox.KeyPgup:       KeyPgup,
+	termbox.KeyPgdn:       KeyPgdn,
+	termbox.KeyBackspace2: KeyBackspace,
+}
+
+// New returns terminal on top of termbox, it's started by Init.
+func New() Terminal {
+	return &termboxTerminal{}
+}
+
+// Init starts termbox in 256-color mode, where the first 8 colors are the
+// same as in normal mode.
+func (terminal *termboxTerminal) Init() error {
+	err := termbox.Init()
+	if err != nil {
+		return err
+	}
+
+	termbox.SetOutputMode(termbox.Output256)
+
+	return nil
+}
+
+func (terminal *termboxTerminal) Close() {
+	if terminal.focus {
+		fmt.Print("\x1b[?1004l")
+		terminal.focus = false
+	}
+
+	termbox.Close()
+}
+
+func (terminal *termboxTerminal) IsInit() bool {
+	return termbox.IsInit
+}
+
+func (terminal *termboxTerminal) Size() (int, int) {
+	return termbox.Size()
+}
+
+// EnableFocus turns on xterm's focus reporting, terminal sends "ESC [ I"
+// when the window gains focus and "ESC [ O" when it loses it, in alt mode
+// termbox reports the sequence as Alt+[ followed by I or O.
+func (terminal *termboxTerminal) EnableFocus() {
+	terminal.focus = true
+
+	termbox.SetInputMode(termbox.InputAlt)
+	fmt.Print("\x1b[?1004h")
+}
+
+func (terminal *termboxTerminal) SetCell(
+	x, y int, symbol rune, fg, bg Attribute,
+) {
+	termbox.SetCell(
+		x, y, symbol, termbox.Attribute(fg), termbox.Attribute(bg),
+	)
+}
+
+func (terminal *termboxTerminal) SetCursor(x, y int) {
+	termbox.SetCursor(x, y)
+}
+
+func (terminal *termboxTerminal) HideCursor() {
+	termbox.HideCursor()
+}
+
+// CellBuffer returns copy of termbox cells, changes of the copy are drawn
+// on the screen by Flush.
+func (terminal *termboxTerminal) CellBuffer() []Cell {
+	cells := []Cell{}
+	for _, cell := range termbox.CellBuffer() {
+		cells = append(cells, Cell{
+			Ch: cell.Ch, Fg: Attribute(cell.Fg), Bg: Attribute(cell.Bg),
+		})
+	}
+
+	return cells
+}
+
+func (terminal *termboxTerminal) Clear(fg, bg Attribute) error {
+	return termbox.Clear(termbox.Attribute(fg), termbox.Attribute(bg))
+}
+
+func (terminal *termboxTerminal) Flush() error {
+	return termbox.Flush()
+}
+
+func (terminal *termboxTerminal) PollEvent() Event {
+	for {
+		event := termbox.PollEvent()
+
+		switch event.Type {
+		case termbox.EventKey:
+			if !terminal.focus || event.Mod&termbox.ModAlt == 0 ||
+				event.Ch != '[' {
+				return getTermboxKey(event)
+			}
+
+			next := termbox.PollEvent()
+			switch next.Ch {
+			case 'I', 'O':
+				return Event{Type: EventFocus, Focused: next.Ch == 'I'}
+			}
+
+			return getTermboxKey(next)
+
+		case termbox.EventResize:
+			return Event{
+				Type: EventResize, Width: event.Width, Height: event.Height,
+			}
+
+		case termbox.EventInterrupt:
+			return Event{Type: EventInterrupt}
+
+		case termbox.EventError:
+			return Event{Type: EventKey, Key: KeyCtrlC}
+		}
+	}
+}
+
+func (terminal *termboxTerminal) Interrupt() {
+	termbox.Interrupt()
+}
+
+func getTermboxKey(event termbox.Event) Event {
+	result := Event{Type: EventKey, Ch: event.Ch}
+
+	if key, ok := termboxKeys[event.Key]; ok {
+		result.Key = key
+	} else if event.Ch == 0 {
+		result.Key = Key(event.Key)
+	}
+
+	return result
+}
@@ -0,0 +1,110 @@
+// Package ui is terminal of short, which hides the terminal library behind
+// backend-neutral types, so tests can drive the application by fake
+// terminal.
+package ui
+
+// Terminal is screen and keyboard used by the application. tcell is used by
+// default, termbox is used by the build with -tags termbox.
+type Terminal interface {
+	Init() error
+	Close()
+	IsInit() bool
+	Size() (int, int)
+
+	// EnableFocus makes the terminal report EventFocus when its window
+	// gains or loses focus, reporting is disabled by Close
+	EnableFocus()
+
+	SetCell(x, y int, symbol rune, fg, bg Attribute)
+	SetCursor(x, y int)
+	HideCursor()
+
+	// CellBuffer returns cells of the back buffer, which are changed by
+	// SetCell only
+	CellBuffer() []Cell
+
+	Clear(fg, bg Attribute) error
+	Flush() error
+	PollEvent() Event
+	Interrupt()
+}
+
+// Key is special key of keyboard event, other keys are characters in Ch
+// of the event
+type Key uint16
+
+// control keys are their ASCII codes, Backspace is reported for both
+// codes terminals send for it
+const (
+	KeyCtrlC     Key = 0x03
+	KeyBackspace Key = 0x08
+	KeyEnter     Key = 0x0d
+	KeyCtrlZ     Key = 0x1a
+	KeyEsc       Key = 0x1b
+	KeySpace     Key = 0x20
+)
+
+const (
+	KeyArrowUp Key = 0xffff - iota
+	KeyArrowDown
+	KeyArrowLeft
+	KeyArrowRight
+	KeyPgup
+	KeyPgdn
+)
+
+// Attribute is color of cell combined with style attributes, colors are
+// numbers of 256-color palette starting from one, zero is default color of
+// the terminal
+type Attribute uint16
+
+const (
+	ColorDefault Attribute = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// style attributes are stored in bits above colors
+const (
+	AttrBold Attribute = 1 << (iota + 9)
+	AttrUnderline
+	AttrReverse
+)
+
+// Cell is character of the screen with its colors
+type Cell struct {
+	Ch rune
+	Fg Attribute
+	Bg Attribute
+}
+
+// EventType is kind of terminal event
+type EventType uint8
+
+const (
+	EventKey EventType = iota
+	EventResize
+	EventFocus
+	EventInterrupt
+)
+
+// Event is key press, resize or change of focus of the terminal, space is
+// reported as KeySpace
+type Event struct {
+	Type EventType
+	Key  Key
+	Ch   rune
+
+	// new size of resized terminal
+	Width  int
+	Height int
+
+	// whether the window gained focus
+	Focused bool
+}
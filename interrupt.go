@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// signaled is set when the process is asked to terminate during session,
+// pollEvent turns it into Ctrl+C, so completed tests are saved the same way
+var signaled int32
+
+// handleSignals makes SIGINT, SIGTERM and SIGHUP, like closed terminal,
+// interrupt session as Ctrl+C does. Returns function which restores default
+// handling of signals.
+func handleSignals() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case received := <-signals:
+			log.info("session interrupted", Fields{"signal": received.String()})
+
+			atomic.StoreInt32(&signaled, 1)
+			screen.Interrupt()
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func isSignaled() bool {
+	return atomic.LoadInt32(&signaled) == 1
+}
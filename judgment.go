@@ -0,0 +1,194 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+const (
+	// number at the position is replaced with another random number
+	alterationSubstitution = "substitution"
+
+	// number at the position is swapped with the next one
+	alterationTransposition = "transposition"
+
+	// number at the position is changed by one
+	alterationOffByOne = "off-by-one"
+)
+
+var alterations = []string{
+	alterationSubstitution, alterationTransposition, alterationOffByOne,
+}
+
+// outcome of same-different judgment test
+type Judgment struct {
+	// empty if the sequence was shown unchanged
+	Alteration string `json:"alteration,omitempty"`
+	Position   int    `json:"position,omitempty"`
+	Same       bool   `json:"same"`
+	Correct    bool   `json:"correct"`
+}
+
+// alterNumbers returns copy of numbers altered in one position by specified
+// alteration, returns false if the alteration can't be applied.
+func alterNumbers(
+	numbers []int, alteration string, position int, min, max int,
+) ([]int, bool) {
+	altered := append([]int{}, numbers...)
+
+	switch alteration {
+	case alterationSubstitution:
+		for attempt := 0; attempt < 100; attempt++ {
+			number := min + randomInt(max-min+1)
+			if number != numbers[position] {
+				altered[position] = number
+				return altered, true
+			}
+		}
+
+	case alterationTransposition:
+		next := position + 1
+		if next < len(numbers) && numbers[position] != numbers[next] {
+			altered[position], altered[next] = altered[next], altered[position]
+			return altered, true
+		}
+
+	case alterationOffByOne:
+		if numbers[position]+1 <= max {
+			altered[position]++
+			return altered, true
+		}
+
+		if numbers[position]-1 >= min {
+			altered[position]--
+			return altered, true
+		}
+	}
+
+	return nil, false
+}
+
+func runJudgmentTest(options Options, test Test) (Result, error) {
+	numbers := generateRandomNumbers(
+		seeded, options.Min, options.Max, test.Count,
+	)
+
+	judgment := Judgment{Same: randomInt(2) == 0}
+
+	probe := numbers
+	if !judgment.Same {
+		for {
+			alteration := alterations[randomInt(len(alterations))]
+			position := randomInt(len(numbers))
+
+			altered, ok := alterNumbers(
+				numbers, alteration, position, options.Min, options.Max,
+			)
+			if ok {
+				probe = altered
+				judgment.Alteration = alteration
+				judgment.Position = position
+				break
+			}
+		}
+	}
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(joinNumbers(numbers), height/2)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	clearScreen()
+	time.Sleep(options.Retention)
+
+	printCentered(joinNumbers(probe), height/2)
+	printCentered("s: same, d: different", height/2+2)
+	screen.HideCursor()
+	screen.Flush()
+
+	answer, err := waitChar('s', 'd')
+	if err != nil {
+		return Result{}, err
+	}
+
+	judgment.Correct = (answer == 's') == judgment.Same
+
+	// judgment is all or nothing, so accuracy of the result is either zero
+	// or one
+	score := 0
+	if judgment.Correct {
+		score = len(numbers)
+	}
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		lines := []string{
+			"shown:  " + joinNumbers(numbers),
+			"probe:  " + joinNumbers(probe),
+		}
+
+		if judgment.Same {
+			lines = append(lines, "sequences were the same")
+		} else {
+			lines = append(lines, judgment.Alteration+" at position "+
+				strconv.Itoa(judgment.Position+1))
+		}
+
+		note, err = showFeedback(plainLines(lines), score, len(numbers))
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    len(numbers),
+		Note:     note,
+		Mode:     modeJudgment,
+		Items:    strings.Fields(joinNumbers(numbers)),
+		Input:    strings.Fields(joinNumbers(probe)),
+		Judgment: &judgment,
+	}, nil
+}
+
+// waitChar waits until one of specified characters is pressed.
+func waitChar(chars ...rune) (rune, error) {
+	for {
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch event.Key {
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return 0, errAborted
+		case ui.KeyEsc:
+			return 0, errStopped
+		}
+
+		for _, char := range chars {
+			if event.Ch == char {
+				return char, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// direction of movement, x grows to the right and y grows down
+type direction struct {
+	x int
+	y int
+}
+
+var (
+	directionUp    = direction{0, -1}
+	directionDown  = direction{0, 1}
+	directionLeft  = direction{-1, 0}
+	directionRight = direction{1, 0}
+)
+
+// layout binds keys or characters to directions of movement
+type layout struct {
+	keys  map[ui.Key]direction
+	chars map[rune]direction
+}
+
+// layouts of movement keys, numpad works with Num Lock on, when it sends
+// digits
+var layouts = map[string]layout{
+	"arrows": {
+		keys: map[ui.Key]direction{
+			ui.KeyArrowUp:    directionUp,
+			ui.KeyArrowDown:  directionDown,
+			ui.KeyArrowLeft:  directionLeft,
+			ui.KeyArrowRight: directionRight,
+		},
+	},
+	"hjkl": {
+		chars: map[rune]direction{
+			'k': directionUp, 'j': directionDown,
+			'h': directionLeft, 'l': directionRight,
+		},
+	},
+	"wasd": {
+		chars: map[rune]direction{
+			'w': directionUp, 's': directionDown,
+			'a': directionLeft, 'd': directionRight,
+		},
+	},
+	"numpad": {
+		chars: map[rune]direction{
+			'8': directionUp, '2': directionDown,
+			'4': directionLeft, '6': directionRight,
+		},
+	},
+}
+
+// Keys is [keys] section of config, which sets movement keys of grid modes
+// and scrolling.
+type Keys struct {
+	// layouts which are all active: arrows, hjkl, wasd or numpad
+	Movement []string `toml:"movement"`
+
+	// swap left and right for mirrored grids, like for left hand
+	Mirrored bool `toml:"mirrored"`
+}
+
+// keymap is keys of config resolved to bindings
+type keymap struct {
+	layouts  []layout
+	mirrored bool
+}
+
+var keys = keymap{layouts: []layout{layouts["arrows"]}}
+
+func (config Keys) validate() error {
+	for _, name := range config.Movement {
+		if _, ok := layouts[name]; !ok {
+			return fmt.Errorf(
+				"unknown movement layout %q, expected one of: %s",
+				name, strings.Join(getLayoutNames(), ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+func getLayoutNames() []string {
+	return []string{"arrows", "hjkl", "wasd", "numpad"}
+}
+
+// loadKeys resolves keys of config, arrows are used if no layouts are set.
+func loadKeys(config Keys) keymap {
+	names := config.Movement
+	if len(names) == 0 {
+		names = []string{"arrows"}
+	}
+
+	resolved := keymap{mirrored: config.Mirrored}
+	for _, name := range names {
+		resolved.layouts = append(resolved.layouts, layouts[name])
+	}
+
+	return resolved
+}
+
+// getDirection returns direction bound to the key of event.
+func (keymap keymap) getDirection(event ui.Event) (direction, bool) {
+	for _, layout := range keymap.layouts {
+		direction, ok := layout.keys[event.Key]
+		if !ok && event.Ch != 0 {
+			direction, ok = layout.chars[event.Ch]
+		}
+
+		if ok {
+			if keymap.mirrored {
+				direction.x = -direction.x
+			}
+
+			return direction, true
+		}
+	}
+
+	return direction{}, false
+}
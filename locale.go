@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// locale which detects language from LC_ALL, LC_NUMERIC or LANG
+const localeAuto = "auto"
+
+// separators of numbers in reports
+type numberFormat struct {
+	decimal   string
+	thousands string
+}
+
+// separators by language, languages which are not listed use English
+// separators
+var numberFormats = map[string]numberFormat{
+	"en": {".", ","},
+	"de": {",", "."},
+	"es": {",", "."},
+	"it": {",", "."},
+	"nl": {",", "."},
+	"pt": {",", "."},
+	"da": {",", "."},
+	"tr": {",", "."},
+	"fr": {",", " "},
+	"ru": {",", " "},
+	"uk": {",", " "},
+	"pl": {",", " "},
+	"cs": {",", " "},
+	"sv": {",", " "},
+	"fi": {",", " "},
+	"nb": {",", " "},
+}
+
+var numbers = numberFormats["en"]
+
+// setLocale switches separators of numbers to ones of locale, empty locale
+// keeps English separators.
+func setLocale(locale string) error {
+	if locale == "" {
+		return nil
+	}
+
+	format, err := getNumberFormat(locale)
+	if err != nil {
+		return err
+	}
+
+	numbers = format
+
+	return nil
+}
+
+// getNumberFormat returns separators of locale like "de" or "de_DE.UTF-8".
+func getNumberFormat(locale string) (numberFormat, error) {
+	if locale == localeAuto {
+		locale = detectLocale()
+	}
+
+	fields := strings.FieldsFunc(locale, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == '@'
+	})
+	if len(fields) == 0 {
+		return numberFormat{}, fmt.Errorf("unknown locale %q", locale)
+	}
+
+	language := strings.ToLower(fields[0])
+	if language == "c" || language == "posix" {
+		language = "en"
+	}
+
+	format, ok := numberFormats[language]
+	if !ok {
+		return numberFormat{}, fmt.Errorf("unknown locale %q", locale)
+	}
+
+	return format, nil
+}
+
+// loadLocale sets locale of numbers from config.
+func loadLocale(file string) error {
+	config, _, err := loadConfig(file)
+	if err != nil {
+		return err
+	}
+
+	return setLocale(config.Locale)
+}
+
+func detectLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return "en"
+}
+
+// formatFloat formats number with specified count of decimal digits using
+// separators of the locale.
+func formatFloat(value float64, precision int) string {
+	text := strconv.FormatFloat(value, 'f', precision, 64)
+
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	fraction := ""
+	if index := strings.Index(text, "."); index >= 0 {
+		text, fraction = text[:index], numbers.decimal+text[index+1:]
+	}
+
+	return sign + groupThousands(text) + fraction
+}
+
+// formatInt formats integer using thousands separator of the locale.
+func formatInt(value int) string {
+	if value < 0 {
+		return "-" + groupThousands(strconv.Itoa(-value))
+	}
+
+	return groupThousands(strconv.Itoa(value))
+}
+
+func groupThousands(digits string) string {
+	for index := len(digits) - 3; index > 0; index -= 3 {
+		digits = digits[:index] + numbers.thousands + digits[index:]
+	}
+
+	return digits
+}
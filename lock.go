@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// writers of database wait for lock of another process for this time, so
+// bots and servers don't fail while the database is saved by a session
+const lockTimeout = 10 * time.Second
+
+// databaseMutex serializes writes of goroutines, like requests of API or
+// bots, lock file serializes writes of processes
+var databaseMutex sync.Mutex
+
+// getLockFile returns path to the file which holds PID of short instance
+// running a session with given database.
+func getLockFile(file string) string {
+	return file + ".lock"
+}
+
+// acquireLock creates lock file for given database, fails if another
+// instance is already running. Locks left by dead processes are removed.
+func acquireLock(file string) (release func(), err error) {
+	path := getLockFile(file)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		lock, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintln(lock, os.Getpid())
+			lock.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+
+			return func() { os.Remove(path) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		pid, err := readLock(path)
+		if err == nil && isRunning(pid) {
+			return nil, fmt.Errorf(
+				"another short instance (pid %d) is already running with %s, "+
+					"remove %s if it is not",
+				pid, file, path,
+			)
+		}
+
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("unable to acquire lock %s", path)
+}
+
+// lockDatabase locks the database for writing, lock file held by this
+// process, like by running session, is reused, lock of another process is
+// waited for.
+func lockDatabase(file string) (release func(), err error) {
+	databaseMutex.Lock()
+
+	pid, err := readLock(getLockFile(file))
+	if err == nil && pid == os.Getpid() {
+		return databaseMutex.Unlock, nil
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		releaseLock, err := acquireLock(file)
+		if err == nil {
+			return func() {
+				releaseLock()
+				databaseMutex.Unlock()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			databaseMutex.Unlock()
+			return nil, err
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func readLock(path string) (int, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(contents)))
+}
+
+func isRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// on Windows the process is opened by FindProcess, so it exists
+	if runtime.GOOS == "windows" {
+		process.Release()
+		return true
+	}
+
+	err = process.Signal(syscall.Signal(0))
+
+	return err == nil || err == syscall.EPERM
+}
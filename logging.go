@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+	levelOff   = "off"
+
+	// log file is rotated when it grows over this size, rotated files are
+	// kept as short.log.1, short.log.2 and so on
+	maxLogSize  = 1 << 20
+	maxLogFiles = 3
+)
+
+var levels = []string{levelDebug, levelInfo, levelWarn, levelError, levelOff}
+
+// Fields are structured details of log record.
+type Fields map[string]interface{}
+
+// internal diagnostic log, unlike event log it is not tied to database and
+// is meant to be attached to bug reports
+type logger struct {
+	sync.Mutex
+
+	path  string
+	level int
+}
+
+var log = &logger{level: len(levels) - 1}
+
+func isKnownLevel(level string) bool {
+	return getLevel(level) >= 0
+}
+
+func getLevel(level string) int {
+	for index, known := range levels {
+		if known == level {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// getLogFile returns path to log file, which is kept next to the config.
+func getLogFile(config string) string {
+	return filepath.Join(filepath.Dir(config), "short.log")
+}
+
+// initLogging starts writing records of specified level and above to given
+// file, rotating it if it's too large.
+func initLogging(path string, level string) {
+	log.Lock()
+	defer log.Unlock()
+
+	log.path = path
+	log.level = getLevel(level)
+
+	if log.level == getLevel(levelOff) {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't write log: %s\n", err)
+		return
+	}
+
+	rotateLog(path)
+}
+
+func rotateLog(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < maxLogSize {
+		return
+	}
+
+	for index := maxLogFiles - 1; index > 0; index-- {
+		os.Rename(
+			fmt.Sprintf("%s.%d", path, index),
+			fmt.Sprintf("%s.%d", path, index+1),
+		)
+	}
+
+	os.Rename(path, path+".1")
+}
+
+func (log *logger) debug(message string, fields Fields) {
+	log.write(levelDebug, message, fields)
+}
+
+func (log *logger) info(message string, fields Fields) {
+	log.write(levelInfo, message, fields)
+}
+
+func (log *logger) warn(message string, fields Fields) {
+	log.write(levelWarn, message, fields)
+}
+
+func (log *logger) error(message string, fields Fields) {
+	log.write(levelError, message, fields)
+}
+
+// write appends record to the log file, logging is diagnostic, so failures
+// are silently ignored.
+func (log *logger) write(level string, message string, fields Fields) {
+	log.Lock()
+	defer log.Unlock()
+
+	if getLevel(level) < log.level || log.path == "" {
+		return
+	}
+
+	record := map[string]interface{}{}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = getRedactedError(err)
+		}
+
+		// secrets are redacted like in crash reports
+		if indexOf(secretKeys, key) >= 0 {
+			value = redacted
+		}
+
+		record[key] = value
+	}
+
+	record["time"] = time.Now().Format(time.RFC3339Nano)
+	record["level"] = level
+	record["message"] = message
+	record["pid"] = os.Getpid()
+
+	content, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	fd, err := os.OpenFile(
+		log.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600,
+	)
+	if err != nil {
+		return
+	}
+
+	fd.Write(append(content, '\n'))
+	fd.Close()
+}
+
+// setupLogging initializes logging with level specified by flag, or by
+// config if flag is not given.
+func setupLogging(config string, flag interface{}) error {
+	level := levelInfo
+	if flag != nil {
+		level = flag.(string)
+		if !isKnownLevel(level) {
+			return fmt.Errorf(
+				"--log-level: unknown level %q, expected one of %v",
+				level, levels,
+			)
+		}
+	} else {
+		// invalid config is reported later by the command which uses it
+		settings, _, err := loadConfig(config)
+		if err == nil && settings.LogLevel != "" {
+			level = settings.LogLevel
+		}
+	}
+
+	initLogging(getLogFile(config), level)
+
+	return nil
+}
+
+// getRedactedError returns message of the error without URL of failed
+// request, which may carry tokens like webhook URLs.
+func getRedactedError(err error) string {
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return strings.ReplaceAll(err.Error(), urlError.URL, redacted)
+	}
+
+	return err.Error()
+}
@@ -2,31 +2,305 @@ package main
 
 import (
 	"crypto/rand"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/big"
+	mathrand "math/rand"
 	"os"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/docopt/docopt-go"
-	"github.com/nsf/termbox-go"
+	"github.com/kovetskiy/short/internal/ui"
+	"github.com/kovetskiy/short/version"
+	"github.com/mattn/go-runewidth"
 )
 
 const (
 	usage = `Short 1.0, short term memory tester.
 
 Usage:
-    ./short [options]
+    ./short [options] [--format <format>] [--dry-run]
+    ./short run [options] --script <name> [--dry-run]
+    ./short export [options] [--anonymize] [-o <file>] [--format <format>]
+                   [--since <date>] [--until <date>] [--ical]
+    ./short migrate [options] [-o <file>] <old-file>
+    ./short merge [options] [--dry-run]
+    ./short log [options] [--type <type>] [--since <date>]
+    ./short stats [options]
+    ./short stats [options] --compare-profiles <profile> <profile>
+    ./short stats [options] --exclude-anomalies [--dry-run]
+    ./short report [options] --html <file>
+    ./short summary [options] --week [--format <format>]
+    ./short streak [options]
+    ./short plan [options]
+    ./short history [options]
+    ./short edit [options] (--where <condition>)... [--add-tag <tag>]...
+                 [--remove-tag <tag>]... [--mark-practice | --mark-scored]
+                 [--dry-run]
+    ./short replay [options] --cast <file> [--gif <file>] [<number>]
+    ./short serve [options] (--dashboard | --discord | --matrix | --grpc |
+                 --api) [--listen <address>]
+    ./short queue [options] [--retry]
+    ./short tutorial [options]
+    ./short challenge create [options]
+    ./short challenge play [options] [--format <format>] <code>
+    ./short challenge verify [options] <result>
+    ./short simulate [options] [--trials <trials>]
+    ./short config init [options]
+    ./short token [options] <user>
+    ./short doctor [options]
+    ./short remind [options]
+    ./short version [--json]
+
+Commands:
+    run           run session defined by script in config.
+    export        print database as JSON, tests of sessions as CSV or
+                  sessions and plan as iCalendar.
+    migrate       convert legacy database into current format.
+    merge         add sessions from sync conflict copies of the database, left
+                  by Syncthing or Dropbox next to it, asking about every copy.
+    log           show log of application events.
+    stats         show statistics of recorded sessions, sessions far above
+                  or below median of the previous ones are listed as
+                  anomalies.
+    report        write standalone HTML page with charts of scores over time,
+                  distribution of spans and accuracy by duration of tests,
+                  it works offline.
+    summary       show digest of the current week.
+    streak        show count of consecutive days with sessions and count of
+                  tests done today, compared with --goal if it's set.
+    plan          show progressive overload plan and adherence to it.
+    history       browse recorded sessions, see their tests, tag and delete
+                  them.
+    edit          change tags or practice flag of sessions matching all
+                  --where conditions.
+    replay        export recorded session in asciinema format, <number>
+                  counts recorded sessions from the latest one, which is 1.
+    serve         serve read-only web page comparing streaks, ratings and
+                  recent bests of profiles of the database, or interactions
+                  endpoint of Discord bot from [discord] section of config,
+                  which shows numbers by /digits, deletes them after
+                  exposure and scores /answer. Results are saved with
+                  discord-<user id> profiles. Matrix bot from [matrix]
+                  section joins rooms it's invited to, answers !digits and
+                  !answer, sending results in direct rooms, and saves them
+                  with matrix-<user id> profiles.
+                  gRPC engine from enginepb/engine.proto lets other
+                  frontends run tests with options of tests and --profile
+                  and save them to the database, stats are served only to
+                  frontends on the same host, it needs build with
+                  -tags grpc. REST API serves sessions
+                  and stats of users from [api.tokens] of config, sessions
+                  of every user are saved with api-<user> profile.
+    queue         show results waiting to be sent to webhook, they are
+                  sent on the next session or with --retry.
+    tutorial      walk through sample test explaining keys, timing and
+                  scoring, it's shown before the first session.
+    challenge     create code of session with random seed and parameters
+                  taken from options, or play session by code, everyone
+                  who plays the same code gets the same tests. Result of
+                  played challenge is signed, verify checks shared result
+                  against stimuli of its code.
+    simulate      simulate sessions of bot opponent from config and show
+                  distribution of accuracy by different items and scoring
+                  strategies, -c sets count of items.
+    config init   write template of config with commented defaults of every
+                  option, options specified in command line override
+                  defaults from config.
+    token         create bearer token of REST API user, its hash is added
+                  to [api.tokens] of config.
+    doctor        check database, lock, sync conflicts, config and backups
+                  of the database taken by policy from [backups] section of
+                  config.
+    remind        send desktop notifications at times from [reminders]
+                  section of config until it's killed, days which already
+                  have a session are skipped and reminders in quiet hours
+                  are sent when they end. Start it with the desktop
+                  session or as a user service.
+    version       show version, commit and build date.
 
 Options:
-    -f <file>     use specified file as database [default: ~/.config/short-term].
-    -n <number>   show specified count of tests [default: 20].
-    -c <count>    show specified count of numbers in tests [default: 7].
-    -i <min>      use specified number as minimum value of number [default: 10]
-    -a <max>      use specified number as maximum value of number [default: 99]
+    -f <file>              use specified SQLite file as database, JSON file of
+                           previous versions is imported into it once, file
+                           named *.gz or *.zst is kept as compressed JSON
+                           [default: ~/.config/short-term].
+    -n <number>            show specified count of tests [default: 20].
+    -c <count>             show specified count of numbers in tests
+                           [default: 7].
+    -i <min>               use specified number as minimum value of number
+                           [default: 10]
+    -a <max>               use specified number as maximum value of number
+                           [default: 99]
+    --digits <width>       use numbers of specified count of digits, like 3 for
+                           100-999, instead of -i and -a.
+    --config <file>        use specified config file
+                           [default: ~/.config/short/config.toml].
+    --script <name>        run session defined by named script in config.
+    --plan                 take count of tests and numbers from progressive
+                           overload plan in config.
+    --mode <mode>          use specified test mode: digits, mapping, sentence,
+                           acronym, chess, dates, judgment, rows, spoken,
+                           reverse, words, spatial or association
+                           [default: digits].
+    --preset <name>        run single test of memory sport discipline with its
+                           official time and scoring: numbers-5min, spoken-1s or
+                           binary-5min.
+    --scoring <strategy>   score rows mode by strategy: rows (default), where
+                           complete row gets full points, row with one mistake
+                           half and others nothing, or digits, where every
+                           digit at its position gets a point. Digits, reverse
+                           and chess modes are scored by: strict (default),
+                           counting items until the first mistake, positional,
+                           counting items at their positions, set, counting
+                           items regardless of order, or levenshtein, items
+                           without edit distance to entered ones.
+    --charset <charset>    characters to memorize in digits and reverse modes:
+                           digits, letters, alnum or custom:<chars>, like
+                           custom:ABC [default: digits].
+    --rate-difficulty      rate how difficult every test felt from 1 to 5, see
+                           stats --by-difficulty.
+    --copy                 copy summary of the session to clipboard by
+                           clipboard_command from config or OSC 52 terminal
+                           sequence.
+    --profile <name>       tag sessions by specified profile, so several people
+                           can share the database, default is the OS user name.
+                           Stats, summary, plan, streak, report, export and
+                           reminders take only sessions of the profile.
+    --all-profiles         take sessions of all profiles in stats, summary,
+                           plan, streak, report, export and reminders.
+    --notify               show desktop notification with score and new bests
+                           when the session is finished.
+    --reverse              recall numbers in reverse order, same as --mode
+                           reverse.
+    --pairs <file>         use tab-separated key-value pairs from specified file
+                           in mapping and association modes instead of random
+                           names and numbers or words, or acronyms and
+                           expansions in acronym mode.
+    --corpus <file>        use sentences from specified file, one per line, in
+                           sentence mode.
+    --wordlist <file>      use words from specified file in words and association
+                           modes instead of built-in common words.
+    --scramble             shuffle words of sentences in sentence mode.
+    --retention <seconds>  blank pause before the sequence is shown again in
+                           judgment mode [default: 2].
+    -o <file>              write output to specified file, compressed if it's
+                           named *.gz or *.zst [default: -].
+    --adaptive             start with -c items and add one after every perfect
+                           recall, remove one after two failures in a row.
+    --goal <tests>         daily goal of tests, progress to it is shown after
+                           session and by streak.
+    --adjust-blocks        offer to make the next blocks of tests one item
+                           harder or easier by accuracy of the finished block,
+                           blocks are runs of tests with the same parameters,
+                           so it needs blocked schedule.
+    --expose <seconds>     hide items automatically after specified seconds, or
+                           duration with unit like 1500ms, instead of waiting
+                           for Enter.
+    --interference <s>     count backwards for specified seconds after items
+                           are hidden, before they are recalled.
+    --lengths <list>       use specified comma-separated counts of numbers
+                           instead of -c, like 5,7,9.
+    --schedule <schedule>  order tests with different counts of numbers: blocked
+                           or interleaved [default: blocked].
+    --time-scale <scale>   multiply all time limits, like exposure, judgment
+                           pause and spoken digits interval, by specified number
+                           [default: 1].
+    --seed <value>         generate items from random numbers seeded by
+                           specified integer, so sessions with the same seed
+                           and options show the same items.
+    --practice             warm up without recording results, the session
+                           isn't saved, so it doesn't change stats, streak
+                           or plan.
+    --feedback             show correct numbers after each test, allows to
+                           attach a note to the test by pressing 'n'.
+    --feedback-delay <s>   continue to the next test after feedback is shown for
+                           specified seconds, implies --feedback.
+    --heat                 same as --feedback, but also color correct items by
+                           your accuracy at their positions in previous tests,
+                           from red to green.
+    --speech               recall numbers aloud, speech is recognized by
+                           speech_command from config, which should print
+                           transcript.
+    --pause-on-blur        pause timers while terminal is out of focus, works in
+                           terminals which support focus reporting.
+    --blank-on-blur        same as --pause-on-blur, but also hide numbers while
+                           terminal is out of focus.
+    --opponent             play every test against bot, which skill is set in
+                           [opponent] section of config, 0.7 by default.
+    --record-env           record hostname, terminal, SSH and power source with
+                           the session.
+    --safe                 ignore config file and start with built-in defaults
+                           to recover from broken config.
+    --log-level <level>    write internal records of specified level and above
+                           to short.log next to the config: debug, info, warn,
+                           error or off.
+    --record               record screen of the session to replay it later.
+    --cast <file>          write recorded session in asciinema v2 format to
+                           specified file, - for stdout.
+    --gif <file>           also convert recorded session into GIF using command
+                           from gif_command in config, agg by default.
+    --where <condition>    select sessions by condition on date, score (accuracy
+                           in percents), tests, mode, tag or practice, like
+                           date<2025-01-01, score>=80 or tag=legacy.
+    --add-tag <tag>        add tag to selected sessions.
+    --remove-tag <tag>     remove tag from selected sessions.
+    --mark-practice        mark selected sessions as practice.
+    --mark-scored          mark selected sessions as scored.
+    --dry-run              only show what would be changed by edit, or print
+                           resolved options, blocks and example stimuli of
+                           session without starting it.
+    --json                 print version information as JSON.
+    --dashboard            serve dashboard of profiles.
+    --discord              serve Discord bot.
+    --matrix               serve Matrix bot.
+    --grpc                 serve gRPC engine.
+    --api                  serve REST API of users.
+    --listen <address>     listen on specified address
+                           [default: 127.0.0.1:8080].
+    --retry                send queued results now.
+    --anonymize            strip personal information and recalled items from
+                           exported data, profiles are replaced by pseudonyms
+                           which differ between exports.
+    --ical                 export completed sessions and weeks of the plan as
+                           iCalendar events.
+    --type <type>          show only events of specified type.
+    --since <date>         show only events or export only sessions since
+                           specified date (YYYY-MM-DD).
+    --until <date>         export only sessions until specified date inclusive
+                           (YYYY-MM-DD).
+    --trials <trials>      count of simulated sessions [default: 10000].
+    --tradeoff             show how accuracy depends on study time.
+    --by-schedule          compare blocked and interleaved sessions.
+    --by-script            compare sessions run by different scripts.
+    --by-alteration        show which alterations are missed in judgment mode.
+    --by-difficulty        compare rated difficulty of tests with accuracy.
+    --rating               plot rating, which is updated after every test by its
+                           difficulty and your accuracy.
+    --chart                plot average score of tests by days of the last
+                           sessions.
+    --fitness              plot memory fitness index of the last sessions, it's
+                           weighted average of span, accuracy, speed and
+                           consistency of tests, weights are set in
+                           [fitness] section of config.
+    --last <sessions>      count of the last sessions plotted by charts of
+                           stats [default: 30].
+    --exclude-anomalies    tag anomalous sessions by "anomaly", so they are
+                           excluded from stats, remove the tag by edit to
+                           include them back.
+    --compare-profiles     compare metrics and accuracy by days of two
+                           profiles side by side.
+    --html <file>          write report into file, - for stdout, *.gz and
+                           *.zst are compressed.
+    --week                 summarize the current week.
+    --format <format>      output format of summary: text, markdown or json, of
+                           export: json or csv, or template of the line printed
+                           after session, like "avg={avg_score} span={max_span}
+                           t={avg_duration}s", fields: avg_score, avg_duration,
+                           max_span, total_score, tests, accuracy, practice,
+                           mode, date, fitness.
 `
 )
 
@@ -35,34 +309,645 @@ type Result struct {
 	Score    int     `json:"score"`
 	Duration float64 `json:"duration"`
 	Count    int     `json:"count"`
+	Note     string  `json:"note,omitempty"`
+
+	// mode of the test, empty for results recorded before modes were added
+	Mode string `json:"mode,omitempty"`
+
+	// shown and entered items for modes which items are not numbers
+	Items []string `json:"items,omitempty"`
+	Input []string `json:"input,omitempty"`
+
+	// id of items in stimuli archive of SQLite database, items shown in many
+	// tests, like items of challenges, are stored once
+	Stimulus string `json:"stimulus,omitempty"`
+
+	// key-value pairs with answers of the user for mapping mode
+	Pairs []Pair `json:"pairs,omitempty"`
+
+	Judgment *Judgment `json:"judgment,omitempty"`
+
+	// distractor task solved between exposure and recall
+	Interference *Interference `json:"interference,omitempty"`
+
+	// time limit of exposure in seconds, duration of timed test is time
+	// items were actually shown
+	Exposure float64 `json:"exposure,omitempty"`
+
+	// count of items of the next test in adaptive mode
+	Span int `json:"span,omitempty"`
+
+	// score of bot opponent in the same test
+	Opponent *int `json:"opponent,omitempty"`
+
+	// scoring strategy of rows, digits, reverse and chess modes, score is in
+	// points of the strategy
+	Scoring string `json:"scoring,omitempty"`
+
+	// characters memorized instead of numbers in digits and reverse modes
+	Charset string `json:"charset,omitempty"`
+
+	// subjective difficulty rated by the user from 1 to 5, zero if not rated
+	Difficulty int `json:"difficulty,omitempty"`
+
+	// correct and entered items shown on results screen, numbers are not
+	// stored in database
+	shown   []string
+	entered []string
 }
 
+var errAborted = errors.New("aborted by user")
+
+// errStopped is returned when Esc is pressed, session is finished early and
+// its results are shown
+var errStopped = errors.New("stopped by user")
+
+// minimal size of terminal, smaller terminals can't fit feedback screen
+const (
+	minScreenWidth  = 20
+	minScreenHeight = 9
+)
+
 func main() {
-	args, _ := docopt.Parse(usage, nil, true, "1.0", false)
+	args, _ := docopt.Parse(usage, nil, true, version.Version(), false)
 
-	file := args["-f"].(string)
-	if file[:2] == "~/" {
-		file = os.Getenv("HOME") + file[1:]
+	safeMode = args["--safe"].(bool)
+
+	err := applyDefaults(args, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	var (
-		testsCount, _   = strconv.Atoi(args["-n"].(string))
-		numbersCount, _ = strconv.Atoi(args["-c"].(string))
-		minNumber, _    = strconv.Atoi(args["-i"].(string))
-		maxNumber, _    = strconv.Atoi(args["-a"].(string))
-	)
+	file := expandHome(args["-f"].(string))
+
+	err = setupLogging(expandHome(args["--config"].(string)), args["--log-level"])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	log.debug("started", Fields{"args": os.Args[1:]})
+
+	switch {
+	case args["export"].(bool):
+		err = runExport(file, args)
+
+	case args["migrate"].(bool):
+		err = migrateDatabase(args["<old-file>"].(string), args["-o"].(string))
+
+	case args["merge"].(bool):
+		err = mergeConflicts(file, args["--dry-run"].(bool))
+
+	case args["log"].(bool):
+		err = printEvents(file, args["--type"], args["--since"])
+
+	case args["stats"].(bool):
+		view := statsOverview
+		switch {
+		case args["--tradeoff"].(bool):
+			view = statsTradeoff
+		case args["--by-schedule"].(bool):
+			view = statsSchedule
+		case args["--by-script"].(bool):
+			view = statsScript
+		case args["--by-alteration"].(bool):
+			view = statsAlteration
+		case args["--rating"].(bool):
+			view = statsRating
+		case args["--by-difficulty"].(bool):
+			view = statsDifficulty
+		case args["--chart"].(bool):
+			view = statsChart
+		case args["--fitness"].(bool):
+			view = statsFitness
+		}
+
+		var last int
+		last, err = parseInt("--last", args["--last"].(string))
+		if err == nil && last <= 0 {
+			err = fmt.Errorf("--last: count of sessions should be positive")
+		}
+
+		var config Config
+		if err == nil {
+			config, _, err = loadConfig(expandHome(args["--config"].(string)))
+		}
+
+		if err == nil {
+			err = setLocale(config.Locale)
+		}
+
+		switch {
+		case err != nil:
+		case args["--exclude-anomalies"].(bool):
+			err = excludeAnomalies(
+				file, getProfileOption(args), args["--dry-run"].(bool),
+			)
+		case args["--compare-profiles"].(bool):
+			err = compareProfiles(
+				file, args["<profile>"].([]string), last,
+				config.getLocation(),
+			)
+		default:
+			err = printStats(
+				file, view, getProfileOption(args), last, config.Fitness,
+				config.getLocation(), config.getWeekStart(),
+			)
+		}
+
+	case args["report"].(bool):
+		err = writeReport(
+			file, getProfileOption(args), args["--html"].(string),
+		)
+
+	case args["summary"].(bool):
+		format, _ := args["--format"].(string)
+		if format == "" {
+			format = "text"
+		}
+
+		var config Config
+		config, _, err = loadConfig(expandHome(args["--config"].(string)))
+		if err == nil {
+			err = setLocale(config.Locale)
+		}
+
+		if err == nil {
+			err = printSummary(
+				file, format, getProfileOption(args), config.Fitness,
+				config.getLocation(), config.getWeekStart(),
+			)
+		}
+
+	case args["streak"].(bool):
+		var (
+			goal   int
+			config Config
+		)
+
+		goal, err = parseGoal(args)
+		if err == nil {
+			config, _, err = loadConfig(expandHome(args["--config"].(string)))
+		}
+
+		if err == nil {
+			err = printStreak(
+				file, getProfileOption(args), goal, config.getLocation(),
+			)
+		}
+
+	case args["plan"].(bool):
+		var config Config
+		config, _, err = loadConfig(expandHome(args["--config"].(string)))
+		if err == nil {
+			err = setLocale(config.Locale)
+		}
+
+		if err == nil {
+			err = printPlan(file, getProfileOption(args), config)
+		}
+
+	case args["history"].(bool):
+		err = runHistory(file)
+
+	case args["edit"].(bool):
+		err = runEdit(file, args)
+
+	case args["queue"].(bool):
+		err = runQueue(
+			file, expandHome(args["--config"].(string)),
+			args["--retry"].(bool),
+		)
+
+	case args["challenge"].(bool) && args["create"].(bool):
+		err = createChallenge(args)
+
+	case args["challenge"].(bool) && args["verify"].(bool):
+		err = verifyResult(args["<result>"].(string))
+
+	case args["simulate"].(bool):
+		err = runSimulation(args)
+
+	case args["config"].(bool):
+		err = initConfig(expandHome(args["--config"].(string)))
+
+	case args["token"].(bool):
+		err = createToken(
+			expandHome(args["--config"].(string)), args["<user>"].(string),
+		)
+
+	case args["doctor"].(bool):
+		err = runDoctor(file, expandHome(args["--config"].(string)))
+
+	case args["remind"].(bool):
+		var config Config
+		config, _, err = loadConfig(expandHome(args["--config"].(string)))
+		if err == nil {
+			profile := getProfileOption(args)
+			err = runReminders(
+				file, config.Reminders, profile, config.getLocation(),
+			)
+		}
+
+	case args["tutorial"].(bool):
+		err = runTutorial(expandHome(args["--config"].(string)))
+
+	case args["serve"].(bool) && args["--discord"].(bool):
+		err = serveDiscord(file, args)
+
+	case args["serve"].(bool) && args["--matrix"].(bool):
+		err = serveMatrix(file, args)
+
+	case args["serve"].(bool) && args["--grpc"].(bool):
+		err = serveGRPC(file, args)
+
+	case args["serve"].(bool) && args["--api"].(bool):
+		err = serveAPI(
+			file, expandHome(args["--config"].(string)),
+			args["--listen"].(string),
+		)
+
+	case args["serve"].(bool):
+		err = serveDashboard(
+			file, expandHome(args["--config"].(string)),
+			args["--listen"].(string),
+		)
+
+	case args["replay"].(bool):
+		err = runReplay(file, args)
+
+	case args["version"].(bool):
+		err = printVersion(args["--json"].(bool))
+
+	default:
+		err = runSession(file, args)
+
+		// the same session is run again from results screen
+		for err == errRetry {
+			err = runSession(file, args)
+		}
+	}
+
+	if err != nil {
+		log.error("command failed", Fields{"error": err})
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runSession(file string, args map[string]interface{}) (err error) {
+	options, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	// dry run only reads the database, so it doesn't need the lock
+	if !options.DryRun {
+		release, err := acquireLock(file)
+		if err != nil {
+			log.warn(
+				"database is locked", Fields{"database": file, "error": err},
+			)
+			return err
+		}
+
+		defer release()
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			err = recoverCrash(value, debug.Stack(), file, options.Config)
+		}
+	}()
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	warnConflicts(file)
+
+	// sessions of others sharing the database don't affect predictions,
+	// cooldown and bests
+	database = database.getProfile(options.Profile)
+
+	if safeMode {
+		log.info(
+			"safe mode, config is ignored", Fields{"config": options.Config},
+		)
+		fmt.Fprintf(
+			os.Stderr, "safe mode: %s is ignored, using defaults\n",
+			options.Config,
+		)
+	}
+
+	config, configHash, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+
+	if configHash != "" && !options.DryRun {
+		trackConfigChanges(file, options.Config, configHash)
+	}
 
-	err := termbox.Init()
+	err = setLocale(config.Locale)
+	if err != nil {
+		return err
+	}
+
+	// payloads of network integrations which failed last time
+	waitQueue := func() {}
+	if !options.DryRun {
+		waitQueue = flushQueueInBackground(file, config)
+		defer waitQueue()
+	}
+
+	theme, err = loadTheme(config.Theme)
+	if err != nil {
+		return err
+	}
+
+	keys = loadKeys(config.Keys)
+	feedbackDelay = options.FeedbackDelay
+
+	if options.Speech {
+		if strings.TrimSpace(config.SpeechCommand) == "" {
+			return fmt.Errorf(
+				"--speech: speech_command is not set in %s", options.Config,
+			)
+		}
+
+		options.SpeechCommand = config.SpeechCommand
+	}
+
+	options.SpeakCommand = config.SpeakCommand
+
+	if args["--opponent"].(bool) {
+		// bot plays with the same random numbers as stimuli
+		if options.Challenge != nil {
+			return fmt.Errorf("--opponent can't be used with challenge")
+		}
+
+		opponent := config.Opponent.withDefaults()
+		options.Opponent = &opponent
+	}
+
+	if options.Plan {
+		if !config.Plan.isDefined() {
+			return fmt.Errorf("--plan: no plan defined in %s", options.Config)
+		}
+
+		week := config.Plan.getCurrentWeek(
+			database, clock().In(config.getLocation()), config.getWeekStart(),
+		)
+		options.Tests = week.Tests
+		options.Count = week.Count
+		options.Lengths = []int{week.Count}
+	}
+
+	// stimuli of challenge are the same for everyone who plays its code
+	if options.Challenge != nil {
+		seedRandom(options.Challenge.Seed)
+	}
+
+	if options.Seed != nil {
+		seedRandom(*options.Seed)
+	}
+
+	tests, err := getTests(options, config)
+	if err != nil {
+		return err
+	}
+
+	for index := range tests {
+		tests[index].Exposure = scaleDuration(
+			tests[index].Exposure, options.TimeScale,
+		)
+		tests[index].Rate = scaleDuration(tests[index].Rate, options.TimeScale)
+	}
+
+	if options.Heat {
+		options.PositionAccuracy = map[string][]float64{}
+		for _, mode := range modes {
+			options.PositionAccuracy[mode] = getPositionAccuracy(database, mode)
+		}
+	}
+
+	details := map[string]interface{}{
+		"tests": len(tests),
+		"min":   options.Min,
+		"max":   options.Max,
+	}
+
+	if options.Script != "" {
+		details["script"] = options.Script
+	} else {
+		details["count"] = options.Lengths
+	}
+
+	cooldownEnd := getCooldownEnd(database, config.Cooldown.Duration)
+	practice := clock().Before(cooldownEnd)
+	if practice {
+		details["practice"] = true
+	}
+
+	if options.Untracked {
+		details["untracked"] = true
+	}
+
+	if options.DryRun {
+		printDryRun(options, config, tests, practice)
+		return nil
+	}
+
+	logEvent(file, eventSessionStarted, details)
+
+	restoreSignals := handleSignals()
+	defer restoreSignals()
+
+	var recorder *recorder
+	if options.Record {
+		recorder = startRecording()
+		defer stopRecording(recorder)
+	}
+
+	err = screen.Init()
 	if err != nil {
 		panic(err)
 	}
 
+	if options.PauseOnBlur {
+		enableFocusReporting(options.BlankOnBlur)
+	}
+
 	clearScreen()
 
+	err = waitScreenSize()
+
+	hidden := getHiddenRules(config)
+
+	// tutorial can be skipped by Ctrl+C, which doesn't abort the session
+	if err == nil && isFirstRun(file, options.Config) {
+		err = showTutorial()
+		if err == errAborted || err == errStopped {
+			err = nil
+		}
+
+		markTutorialShown(options.Config)
+
+		// tutorial has already explained digits
+		hidden[modeDigits] = true
+	}
+
+	if err == nil {
+		err = showNewRules(database, options, tests, hidden)
+	}
+
+	prediction, ok := predict(database, tests[0].Mode, tests[0].Count)
+	if err == nil && ok && isUniform(tests) {
+		err = showPrediction(prediction)
+	}
+
+	if err == errAborted || err == errStopped {
+		screen.Close()
+
+		logEvent(file, eventSessionAborted, map[string]interface{}{
+			"completed": 0,
+		})
+
+		return nil
+	}
+
+	var match *Match
+	if options.Opponent != nil {
+		match = &Match{
+			Skill:  options.Opponent.Skill,
+			Spread: options.Opponent.Spread,
+		}
+	}
+
+	var span *adaptiveSpan
+	if options.Adaptive {
+		span = &adaptiveSpan{count: options.Count}
+	}
+
+	// pause requested during the previous session isn't taken
+	pauseRequested = false
+
 	results := []Result{}
-	for i := 0; i < testsCount; i++ {
-		result := runTest(minNumber, maxNumber, numbersCount)
+
+	// difficulty of the next blocks is changed by the user between blocks
+	var (
+		blockEnds   map[int]bool
+		blockStart  int
+		change      int
+		adjustments []Adjustment
+	)
+
+	if options.AdjustBlocks {
+		blockEnds = getBlockEnds(tests)
+	}
+
+	// stopped sessions are saved with completed tests, interrupted ones
+	// are saved without showing results
+	stopped, interrupted := false, false
+	for index, test := range tests {
+		if span != nil {
+			test.Count = span.count
+		}
+
+		// blocks with fewer items keep at least one after easier choices
+		test.Count += change
+		if test.Count < 1 {
+			test.Count = 1
+		}
+
+		var result Result
+
+		// breaks are taken between tests, so durations don't include them
+		err := config.Breaks.takeDue(len(results), len(tests))
+		if err == nil && pauseRequested {
+			err = takePause()
+		}
+
+		if err == nil {
+			result, err = runTest(options, test)
+		}
+
+		if test.Exposure > 0 {
+			result.Exposure = test.Exposure.Seconds()
+		}
+
+		if err == nil && span != nil {
+			result.Span = span.update(result)
+		}
+
+		if err == nil && match != nil {
+			err = playOpponent(options.Opponent, match, &result)
+		}
+
+		if err == nil && options.RateDifficulty {
+			err = rateDifficulty(&result)
+		}
+
+		// the test being run when Esc or Ctrl+C is pressed is not recorded
+		if (err == errStopped || err == errAborted) && len(results) > 0 {
+			stopped = true
+			interrupted = err == errAborted
+			break
+		}
+
+		if err == errAborted || err == errStopped {
+			screen.Close()
+
+			logEvent(file, eventSessionAborted, map[string]interface{}{
+				"completed": len(results),
+			})
+
+			return nil
+		}
+
+		if err != nil {
+			screen.Close()
+
+			logEvent(file, eventSessionAborted, map[string]interface{}{
+				"completed": len(results),
+				"error":     err.Error(),
+			})
+
+			return err
+		}
+
 		results = append(results, result)
+
+		if !blockEnds[index] {
+			continue
+		}
+
+		adjustment, err := askAdjustment(
+			results[blockStart:], len(results), tests[index+1].Count+change,
+		)
+		if err == errStopped || err == errAborted {
+			stopped = true
+			interrupted = err == errAborted
+			break
+		}
+
+		if err != nil {
+			screen.Close()
+
+			logEvent(file, eventSessionAborted, map[string]interface{}{
+				"completed": len(results),
+				"error":     err.Error(),
+			})
+
+			return err
+		}
+
+		change += adjustment.Change
+		adjustments = append(adjustments, adjustment)
+		blockStart = len(results)
 	}
 
 	var (
@@ -78,124 +963,487 @@ func main() {
 	avgDuration := sumDuration / float64(len(results))
 	avgScore := float64(sumScore) / float64(len(results))
 
-	termbox.Close()
+	session := Session{
+		Date:        clock(),
+		AvgDuration: avgDuration,
+		TotalScore:  sumScore,
+		Results:     results,
+	}
 
-	fmt.Printf("Score: %.2f (%.2f sec)\n", avgScore, avgDuration)
+	if len(options.Lengths) > 1 && options.Script == "" {
+		session.Schedule = options.Schedule
+	}
 
-	saveResults(file, results, sumScore, avgDuration)
-}
+	session.Script = options.Script
+	session.Planned = options.Plan
+	session.Practice = practice
+	session.Match = match
+	session.Profile = options.Profile
 
-func saveResults(
-	file string, results []Result, totalScore int, avgDuration float64,
-) {
-	type DatabaseItem struct {
-		Date        string   `json:"date"`
-		AvgDuration float64  `json:"avg_duration"`
-		TotalScore  int      `json:"total_score"`
-		Results     []Result `json:"results"`
+	if options.TimeScale != 1 {
+		session.TimeScale = options.TimeScale
+	}
+
+	if options.Preset != nil {
+		session.Preset = options.Preset.Name
+	}
+
+	if options.Challenge != nil {
+		session.Challenge = options.Challenge.encode()
+	}
+
+	session.Seed = options.Seed
+	session.Aborted = stopped
+	session.Adjustments = adjustments
+
+	if options.RecordEnvironment {
+		environment := getEnvironment()
+		session.Environment = &environment
+	}
+
+	copySummary := func() error {
+		return copyToClipboard(
+			getClipboardSummary(session, options.Template, config.Fitness),
+			config.ClipboardCommand,
+		)
+	}
+
+	retry := false
+	if !interrupted {
+		retry, err = showResults(results, copySummary)
+		if err != nil {
+			return err
+		}
+	}
+
+	screen.Close()
+
+	// scripts and prompt widgets get only the line of their template
+	if options.Template != nil {
+		fmt.Println(options.Template.render(
+			getTemplateValues(session, config.Fitness),
+		))
+
+		if options.Untracked {
+			fmt.Fprintln(os.Stderr, "Practice run, results are not recorded")
+		}
+	} else {
+		fmt.Printf(
+			"Score: %s (%s sec)\n",
+			formatFloat(avgScore, 2), formatFloat(avgDuration, 2),
+		)
+
+		if index, ok := config.Fitness.getFitness(session); ok {
+			fmt.Printf("Fitness: %s\n", formatFloat(index, 1))
+		}
+
+		if span != nil {
+			fmt.Printf("Span: %d\n", span.count)
+		}
+
+		// warm-up isn't recorded, so it doesn't extend the streak
+		recorded := database
+		if !options.Untracked {
+			recorded.Sessions = append(
+				append([]Session{}, database.Sessions...), session,
+			)
+		}
+
+		now := session.Date.In(config.getLocation())
+		fmt.Printf(
+			"Streak: %s, today %s\n", formatStreak(recorded, now),
+			formatGoal(recorded, options.Goal, now),
+		)
+
+		if match != nil {
+			fmt.Printf("Match: %s\n", match)
+		}
+
+		if stopped {
+			fmt.Printf(
+				"Stopped after %d of %d tests\n", len(results), len(tests),
+			)
+		}
+
+		switch {
+		case options.Untracked:
+			fmt.Println("Practice run, results are not recorded")
+		case practice:
+			fmt.Printf(
+				"Practice session, next scored session at %s\n",
+				cooldownEnd.Format("15:04"),
+			)
+		}
+	}
+
+	// warm-up leaves no trace in the database, backups and integrations
+	if options.Untracked {
+		logEvent(file, eventSessionFinished, map[string]interface{}{
+			"completed": len(results),
+			"untracked": true,
+		})
+
+		if options.Copy {
+			err = copySummary()
+			if err != nil {
+				return err
+			}
+		}
+
+		if retry {
+			return errRetry
+		}
+
+		return nil
 	}
 
-	fd, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0600)
+	report, err := saveResults(file, session, config.Retention)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	for _, line := range report {
+		fmt.Println(line)
 	}
-	defer fd.Close()
-	content, err := ioutil.ReadAll(fd)
+
+	// failed backup doesn't fail the session, doctor reports stale backups
+	err = backupDatabase(file, config.Backups, clock())
 	if err != nil {
-		panic(err)
+		log.warn("can't back up database", Fields{"error": err})
 	}
 
-	database := []DatabaseItem{}
-	json.Unmarshal(content, &database)
+	if options.Copy {
+		err = copySummary()
+		if err != nil {
+			return err
+		}
+	}
 
-	database = append(database, DatabaseItem{
-		Date:        time.Now().String(),
-		AvgDuration: avgDuration,
-		TotalScore:  totalScore,
-		Results:     results,
+	if options.Challenge != nil {
+		signed, err := writeSignedResult(file, options.Config, session)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "signed result: %s\n", signed)
+	}
+
+	if recorder != nil {
+		recorder.recording.Session = session.Date
+
+		err = saveRecording(file, recorder.recording)
+		if err != nil {
+			return err
+		}
+	}
+
+	logEvent(file, eventSessionFinished, map[string]interface{}{
+		"completed": len(results),
+		"score":     sumScore,
+		"aborted":   stopped,
 	})
 
-	content, err = json.Marshal(database)
-	if err != nil {
-		panic(err)
+	// results are saved already, so failed webhook doesn't fail the session
+	waitQueue()
+	if config.Webhook.URL != "" {
+		err = postResults(file, config.Webhook, session, config.getLocation())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	if options.Notify {
+		err = notifySession(database, session)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	if retry {
+		return errRetry
+	}
+
+	return nil
+}
+
+func runEdit(file string, args map[string]interface{}) error {
+	query := Query{}
+	for _, expression := range args["--where"].([]string) {
+		condition, err := parseWhere(expression)
+		if err != nil {
+			return err
+		}
+
+		query.Where = append(query.Where, condition)
+	}
+
+	edit := Edit{
+		AddTags:    args["--add-tag"].([]string),
+		RemoveTags: args["--remove-tag"].([]string),
 	}
 
-	fd.WriteAt(content, 0)
+	switch {
+	case args["--mark-practice"].(bool):
+		practice := true
+		edit.Practice = &practice
+	case args["--mark-scored"].(bool):
+		practice := false
+		edit.Practice = &practice
+	}
+
+	return editSessions(file, query, edit, args["--dry-run"].(bool))
 }
 
-func runTest(minNumber, maxNumber, numbersCount int) Result {
-	validNumbers := generateRandomNumbers(
-		minNumber, maxNumber, numbersCount,
+func runReplay(file string, args map[string]interface{}) error {
+	config, _, err := loadConfig(expandHome(args["--config"].(string)))
+	if err != nil {
+		return err
+	}
+
+	number, ok := args["<number>"].(string)
+	if !ok {
+		number = "1"
+	}
+
+	gif, _ := args["--gif"].(string)
+
+	return replaySession(
+		file, number, args["--cast"].(string), gif, config.GifCommand,
 	)
+}
+
+// saveResults appends session to the database, pruning data according to
+// retention policy, returns report of what was pruned.
+func saveResults(
+	file string, session Session, retention Retention,
+) ([]string, error) {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return nil, err
+	}
+
+	database.Sessions = append(database.Sessions, session)
+
+	report, err := applyRetention(file, &database, retention, clock())
+	if err != nil {
+		return nil, err
+	}
+
+	// other sessions are rewritten only if retention changed them
+	if len(report) == 0 {
+		return nil, appendSession(file, session)
+	}
+
+	return report, saveDatabase(file, database)
+}
 
-	numberStrings := []string{}
-	for _, number := range validNumbers {
-		numberStrings = append(numberStrings, strconv.Itoa(number))
+func runTest(options Options, test Test) (Result, error) {
+	switch test.Mode {
+	case modeMapping:
+		return runMappingTest(options, test)
+	case modeSentence:
+		return runSentenceTest(options, test)
+	case modeWords:
+		return runWordsTest(options, test)
+	case modeAcronym:
+		return runAcronymTest(options, test)
+	case modeChess:
+		return runChessTest(options, test)
+	case modeDates:
+		return runDatesTest(options, test)
+	case modeJudgment:
+		return runJudgmentTest(options, test)
+	case modeRows:
+		return runRowsTest(options, test)
+	case modeSpoken:
+		return runSpokenTest(options, test)
+	case modeSpatial:
+		return runSpatialTest(options, test)
+	case modeAssociation:
+		return runAssociationTest(options, test)
+	case modeReverse:
+		return runDigitsTest(options, test)
 	}
 
-	wholeTest := strings.Join(numberStrings, " ")
+	return runDigitsTest(options, test)
+}
+
+func runDigitsTest(options Options, test Test) (Result, error) {
+	validTokens := options.Charset.generateTokens(
+		seeded, options.Min, options.Max, test.Count,
+	)
+
+	wholeTest := strings.Join(validTokens, " ")
 
-	width, height := termbox.Size()
+	_, height := screen.Size()
 
-	timeStart := time.Now()
+	timeStart := clock()
+	pausedStart := getPausedDuration()
 
-	x := width/2 - len(wholeTest)/2
 	y := height / 2
 
-	termbox.SetCursor(x, y)
+	printCentered(wholeTest, y)
 
-	for _, symbol := range wholeTest {
-		x += 1
-		termbox.SetCell(
-			x, y, symbol, termbox.ColorDefault, termbox.ColorDefault,
-		)
-	}
+	screen.HideCursor()
+	screen.Flush()
 
-	termbox.HideCursor()
-	termbox.Flush()
+	err := waitTimeout(test.Exposure) //wait for input 'Enter'
+	if err != nil {
+		return Result{}, err
+	}
 
-	wait() //wait for input 'Enter'
+	timeFinish := clock()
 
-	timeFinish := time.Now()
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
 
 	clearScreen()
 
-	termbox.SetCursor(x-len(wholeTest)+1, y)
-	termbox.Flush()
-	userNumbers := getNumbers(x-len(wholeTest), y)
+	x, y := getInputPosition(wholeTest)
+
+	userTokens, transcript, err := recallTokens(options, x, y)
+	if err != nil {
+		return Result{}, err
+	}
 
 	clearScreen()
 
-	score := compare(validNumbers, userNumbers)
-	duration := timeFinish.Sub(timeStart).Seconds()
+	// tokens of reverse mode are recalled from the last one
+	mode := modeDigits
+	expected := validTokens
+	if test.Mode == modeReverse {
+		mode = modeReverse
+		expected = reverseTokens(validTokens)
+	}
+
+	scoring := options.getScoring(mode)
+	score := scoreSequence(scoring, expected, userTokens)
+	// time when terminal was out of focus doesn't count
+	paused := getPausedDuration() - pausedStart
+	duration := timeFinish.Sub(timeStart).Seconds() - paused.Seconds()
+
+	var note string
+	if options.Feedback {
+		shown, entered := alignTokens(expected, userTokens)
+
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, mode, shown, " "),
+				getDiffLine(
+					"entered: ", entered, " ", isSameAt(expected, userTokens),
+				),
+			},
+			score, len(expected),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{
+		Interference: interference,
+		Score:        score,
+		Duration:     duration,
+		Count:        test.Count,
+		Note:         note,
+		Mode:         mode,
+		Scoring:      scoring,
+		Input:        strings.Fields(transcript),
+	}
+
+	// numbers are not kept, but characters are cheap to keep, items of
+	// challenges are kept to sign them
+	if !options.Charset.isDigits() || options.Challenge != nil {
+		result.Items = validTokens
+		result.Input = userTokens
+	}
 
-	return Result{
-		score, duration, numbersCount,
+	if !options.Charset.isDigits() {
+		result.Charset = options.Charset.Name
 	}
+
+	result.shown = expected
+	result.entered = userTokens
+
+	return result, nil
 }
 
-func generateRandomNumbers(min, max, count int) []int {
-	numbers := []int{}
-	for i := 0; i < count; i++ {
-		bigNumber, _ := rand.Int(rand.Reader, big.NewInt(int64(max)))
-		number := int(bigNumber.Int64())
-		if number < min {
-			i--
-			continue
+// recallTokens reads tokens typed by the user or numbers recognized from
+// speech, returns transcript of speech. If speech command fails, tokens are
+// typed.
+func recallTokens(options Options, x, y int) ([]string, string, error) {
+	if options.Speech {
+		numbers, transcript, err := recallBySpeech(options.SpeechCommand, y)
+		if err == nil || err == errAborted || err == errStopped {
+			return strings.Fields(joinNumbers(numbers)), transcript, err
 		}
 
-		numbers = append(numbers, number)
+		log.warn("speech recognition failed", Fields{"error": err})
+
+		clearScreen()
+		printCentered(err.Error(), 0)
 	}
 
-	return numbers
+	screen.SetCursor(x+1, y)
+	screen.Flush()
+
+	text, err := readText(x, y, options.Charset.accept)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return options.Charset.parseTokens(text), "", nil
 }
 
-func getNumbers(x, y int) []int {
+// generateRandomNumbers returns numbers drawn uniformly from [min, max],
+// both bounds are included.
+func generateRandomNumbers(
+	source *mathrand.Rand, min, max, count int,
+) []int {
 	numbers := []int{}
-	text := readText(x, y)
+	for i := 0; i < count; i++ {
+		numbers = append(numbers, min+randomIntFrom(source, max-min+1))
+	}
+
+	return numbers
+}
+
+// randomInt returns uniformly distributed random number in [0, max),
+// numbers are reproducible while challenge is played.
+func randomInt(max int) int {
+	return randomIntFrom(seeded, max)
+}
+
+// randomIntFrom returns random number in [0, max) from the seeded source,
+// numbers are drawn from crypto/rand if the source is nil.
+func randomIntFrom(source *mathrand.Rand, max int) int {
+	if source != nil {
+		return source.Intn(max)
+	}
 
-	pieces := strings.Split(text, " ")
-	for _, piece := range pieces {
+	number, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		panic(err)
+	}
+
+	return int(number.Int64())
+}
+
+// shuffle randomly permutes items, like math/rand.Shuffle, but uses
+// randomInt.
+func shuffle(count int, swap func(i, j int)) {
+	for i := count - 1; i > 0; i-- {
+		swap(i, randomInt(i+1))
+	}
+}
+
+// parseNumbers parses space-separated numbers entered by the user, pieces
+// which are not numbers count as zeros.
+func parseNumbers(text string) []int {
+	numbers := []int{}
+	for _, piece := range strings.Split(text, " ") {
 		number, _ := strconv.Atoi(piece)
 		numbers = append(numbers, number)
 	}
@@ -203,39 +1451,72 @@ func getNumbers(x, y int) []int {
 	return numbers
 }
 
-func readText(x, y int) string {
+// readText reads text consisting of accepted characters and spaces.
+func readText(x, y int, accept func(rune) bool) (string, error) {
+	width, height := screen.Size()
+
 	text := ""
 	for {
-		event := termbox.PollEvent()
-		if event.Type != termbox.EventKey {
+		event := pollEvent()
+		if event.Type == ui.EventResize {
+			// the screen is recentered, input follows the text around it
+			newWidth, newHeight := screen.Size()
+
+			x += getCenterShift(width, newWidth)
+			y += getCenterShift(height, newHeight)
+			width, height = newWidth, newHeight
+
+			printInput(text, x, y)
+			continue
+		}
+
+		if event.Type != ui.EventKey {
 			continue
 		}
 
-		if event.Ch >= '0' && event.Ch <= '9' {
+		if event.Ch != 0 && accept(event.Ch) {
 			text += string(event.Ch)
 		}
 
 		switch event.Key {
-		case termbox.KeySpace:
+		case ui.KeySpace:
 			text += " "
-		case termbox.KeyBackspace2:
+		case ui.KeyBackspace:
 			if len(text) == 0 {
 				break
 			}
 			text = text[0 : len(text)-1]
 			clearScreen()
-			printText(text, x, y)
-		case termbox.KeyEnter:
-			return text
-		case termbox.KeyCtrlC, termbox.KeyCtrlZ:
-			termbox.Close()
-			os.Exit(0)
+			printInput(text, x, y)
+		case ui.KeyEnter:
+			return text, nil
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return "", errAborted
+		case ui.KeyEsc:
+			return "", errStopped
 		}
 
-		printText(text, x, y)
+		printInput(text, x, y)
 	}
 }
 
+// printInput prints text entered by the user, on narrow screen only the end
+// of text is shown after "<".
+func printInput(text string, x, y int) {
+	width, _ := screen.Size()
+
+	visible := width - x - 2
+	if len(text) > visible && visible > 1 {
+		text = "<" + text[len(text)-visible+1:]
+	}
+
+	printText(text, x, y)
+}
+
+func isDigit(symbol rune) bool {
+	return symbol >= '0' && symbol <= '9'
+}
+
 func compare(validNumbers, inputNumbers []int) (score int) {
 	length := len(inputNumbers)
 	if len(validNumbers) < length {
@@ -254,41 +1535,170 @@ func compare(validNumbers, inputNumbers []int) (score int) {
 }
 
 func clearScreen() {
-	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
-	err := termbox.Flush()
+	screen.Clear(ui.ColorDefault, ui.ColorDefault)
+	err := screen.Flush()
 	if err != nil {
 		panic(err)
 	}
 }
 
 // just wait for any user input (like 'Press Enter to continue')
-func wait() {
+func wait() error {
+	for {
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		switch {
+		case event.Key == ui.KeyEnter:
+			return nil
+		case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+			return errAborted
+		case event.Key == ui.KeyEsc:
+			return errStopped
+		case event.Ch == 'p':
+			pauseRequested = true
+		}
+	}
+}
+
+// waitScreenSize asks to enlarge the terminal while it's smaller than
+// minimal size required to draw tests and feedback.
+func waitScreenSize() error {
 	for {
-		event := termbox.PollEvent()
-		if event.Type != termbox.EventKey {
+		width, height := screen.Size()
+		if width >= minScreenWidth && height >= minScreenHeight {
+			return nil
+		}
+
+		clearScreen()
+		printCentered(
+			fmt.Sprintf(
+				"terminal is too small: %dx%d, enlarge it to %dx%d",
+				width, height, minScreenWidth, minScreenHeight,
+			),
+			0,
+		)
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
 			continue
 		}
 
 		switch event.Key {
-		case termbox.KeyEnter:
-			return
-		case termbox.KeyCtrlC, termbox.KeyCtrlZ:
-			termbox.Close()
-			os.Exit(0)
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return errAborted
+		case ui.KeyEsc:
+			return errStopped
+		}
+	}
+}
+
+// printCentered prints text in the center of line, text which doesn't fit
+// the screen is wrapped to the following lines.
+func printCentered(text string, y int) {
+	width, _ := screen.Size()
+	for index, line := range wrapText(text, width-2) {
+		printText(line, getCenteredX(line, width), y+index)
+	}
+}
+
+// getCenteredX returns column text is printed from, wide runes take two
+// columns.
+func getCenteredX(text string, width int) int {
+	x := width/2 - runewidth.StringWidth(text)/2
+	if x < 0 {
+		return 0
+	}
+
+	return x
+}
+
+// wrapText splits text into lines not longer than width, breaking lines at
+// spaces if possible.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	lines := []string{}
+	line := []rune{}
+	for _, word := range strings.Split(text, " ") {
+		runes := []rune(word)
+		if len(line) > 0 && len(line)+1+len(runes) > width {
+			lines = append(lines, string(line))
+			line = []rune{}
+		}
+
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+
+		line = append(line, runes...)
+		for len(line) > width {
+			lines = append(lines, string(line[:width]))
+			line = line[width:]
+		}
+	}
+
+	return append(lines, string(line))
+}
+
+// waitTimeout is wait which gives up after specified duration, zero duration
+// means no timeout.
+func waitTimeout(timeout time.Duration) error {
+	if timeout == 0 {
+		return wait()
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	timer := time.AfterFunc(timeout, screen.Interrupt)
+	defer timer.Stop()
+
+	for {
+		event := pollEvent()
+
+		switch event.Type {
+		case ui.EventInterrupt:
+			// interrupt could be left by the timer of previous test
+			if !time.Now().Before(deadline) {
+				return nil
+			}
+
+		case ui.EventKey:
+			switch event.Key {
+			case ui.KeyEnter:
+				return nil
+			case ui.KeyCtrlC, ui.KeyCtrlZ:
+				return errAborted
+			case ui.KeyEsc:
+				return errStopped
+			}
+
+			if event.Ch == 'p' {
+				pauseRequested = true
+			}
 		}
 	}
 }
 
 func printText(text string, x, y int) {
-	termbox.SetCursor(x, y)
+	screen.SetCursor(x, y)
 
 	for _, symbol := range text {
 		x += 1
-		termbox.SetCell(
-			x, y, symbol, termbox.ColorDefault, termbox.ColorDefault,
+		screen.SetCell(
+			x, y, symbol, ui.ColorDefault, ui.ColorDefault,
 		)
+
+		// wide rune takes the next cell too, the terminal skips it
+		if runewidth.RuneWidth(symbol) == 2 {
+			x++
+		}
 	}
 
-	termbox.SetCursor(x+1, y)
-	termbox.Flush()
+	screen.SetCursor(x+1, y)
+	screen.Flush()
 }
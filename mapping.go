@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// names used as keys when no pairs file is given
+var mappingNames = []string{
+	"Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi",
+	"Ivan", "Judy", "Mallory", "Niaj", "Olivia", "Peggy", "Rupert", "Sybil",
+	"Trent", "Victor", "Walter", "Xavier", "Yolanda", "Zoe", "Oscar", "Linda",
+}
+
+// key-value pair with the answer given by the user
+type Pair struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Answer string `json:"answer"`
+}
+
+func (pair Pair) isCorrect() bool {
+	return strings.EqualFold(
+		strings.TrimSpace(pair.Answer), strings.TrimSpace(pair.Value),
+	)
+}
+
+// loadPairs reads tab-separated key-value pairs from specified file.
+func loadPairs(file string) ([]Pair, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	return parsePairs(fd, file)
+}
+
+// parsePairs parses tab-separated pairs, name is used in error messages.
+func parsePairs(reader io.Reader, name string) ([]Pair, error) {
+	pairs := []Pair{}
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := trimLine(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, "\t", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf(
+				"%s:%d: expected key and value separated by tab", name, line,
+			)
+		}
+
+		pairs = append(pairs, Pair{
+			Key:   strings.TrimSpace(fields[0]),
+			Value: strings.TrimSpace(fields[1]),
+		})
+	}
+
+	return pairs, scanner.Err()
+}
+
+// getMappingPairs returns specified count of pairs picked randomly from
+// given pairs or generated from built-in names and random numbers.
+func getMappingPairs(options Options, count int) []Pair {
+	if len(options.Pairs) == 0 {
+		numbers := generateRandomNumbers(
+			seeded, options.Min, options.Max, count,
+		)
+
+		pairs := []Pair{}
+		for index, name := range pickRandom(mappingNames, count) {
+			pairs = append(pairs, Pair{
+				Key:   name,
+				Value: strconv.Itoa(numbers[index]),
+			})
+		}
+
+		return pairs
+	}
+
+	keys := []string{}
+	values := map[string]string{}
+	for _, pair := range options.Pairs {
+		keys = append(keys, pair.Key)
+		values[pair.Key] = pair.Value
+	}
+
+	pairs := []Pair{}
+	for _, key := range pickRandom(keys, count) {
+		pairs = append(pairs, Pair{Key: key, Value: values[key]})
+	}
+
+	return pairs
+}
+
+// pickRandom returns specified count of distinct items in random order.
+func pickRandom(items []string, count int) []string {
+	items = append([]string{}, items...)
+	shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	if count > len(items) {
+		count = len(items)
+	}
+
+	return items[:count]
+}
+
+func runMappingTest(options Options, test Test) (Result, error) {
+	pairs := getMappingPairs(options, test.Count)
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	clearScreen()
+	printLines(formatPairs(pairs, false))
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := recallPairs(options, modeMapping, pairs, duration)
+	result.Interference = interference
+
+	return result, err
+}
+
+// recallPairs asks values of pairs by their keys in random order, every
+// correct value is scored.
+func recallPairs(
+	options Options, mode string, pairs []Pair, duration float64,
+) (Result, error) {
+	_, height := screen.Size()
+
+	var err error
+
+	score := 0
+	for _, index := range pickRandomIndexes(len(pairs)) {
+		pairs[index].Answer, err = readLine(pairs[index].Key+":", height/2)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if pairs[index].isCorrect() {
+			score++
+		}
+	}
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			plainLines(formatPairs(pairs, true)), score, len(pairs),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    len(pairs),
+		Note:     note,
+		Mode:     mode,
+		Pairs:    pairs,
+	}, nil
+}
+
+// formatPairs formats pairs as table, optionally with answers of the user
+// next to values.
+func formatPairs(pairs []Pair, answers bool) []string {
+	width := 0
+	for _, pair := range pairs {
+		if len([]rune(pair.Key)) > width {
+			width = len([]rune(pair.Key))
+		}
+	}
+
+	lines := []string{}
+	for _, pair := range pairs {
+		line := fmt.Sprintf("%-*s  %s", width, pair.Key, pair.Value)
+		if answers {
+			mark := "+"
+			if !pair.isCorrect() {
+				mark = "- " + pair.Answer
+			}
+
+			line += "  " + mark
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func pickRandomIndexes(count int) []int {
+	indexes := []int{}
+	for i := 0; i < count; i++ {
+		indexes = append(indexes, i)
+	}
+
+	shuffle(len(indexes), func(i, j int) {
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	})
+
+	return indexes
+}
+
+// printLines prints lines as left-aligned block in the center of screen.
+func printLines(lines []string) {
+	width, height := screen.Size()
+
+	blockWidth := 0
+	for _, line := range lines {
+		if len([]rune(line)) > blockWidth {
+			blockWidth = len([]rune(line))
+		}
+	}
+
+	x := width/2 - blockWidth/2
+	if x < 0 {
+		x = 0
+	}
+
+	y := height/2 - len(lines)/2
+	if y < 0 {
+		y = 0
+	}
+	for index, line := range lines {
+		printText(line, x, y+index)
+	}
+}
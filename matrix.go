@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	matrixTimeout = 10 * time.Second
+
+	// sync requests wait for new events up to this time
+	matrixSyncTimeout = 30 * time.Second
+
+	// failed sync is retried after this delay, the homeserver may restart
+	matrixRetryDelay = 5 * time.Second
+
+	// results of Matrix users are saved under profiles with this prefix
+	matrixProfilePrefix = "matrix-"
+)
+
+// Matrix is [matrix] section of config, the bot is account logged in on the
+// homeserver, it joins rooms it's invited to and reads commands there
+type Matrix struct {
+	// URL of the homeserver, like https://matrix.org
+	Homeserver string `toml:"homeserver"`
+
+	// id of the bot account, its own messages are ignored
+	UserID string `toml:"user_id"`
+
+	AccessToken string `toml:"access_token"`
+}
+
+func (matrix Matrix) validate() error {
+	if matrix.Homeserver == "" {
+		return nil
+	}
+
+	homeserver, err := url.Parse(matrix.Homeserver)
+	if err != nil ||
+		(homeserver.Scheme != "http" && homeserver.Scheme != "https") {
+		return fmt.Errorf("homeserver should be http or https URL")
+	}
+
+	return nil
+}
+
+type matrixSync struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+type matrixEvent struct {
+	Type    string `json:"type"`
+	Sender  string `json:"sender"`
+	EventID string `json:"event_id"`
+	Content struct {
+		MsgType string `json:"msgtype"`
+		Body    string `json:"body"`
+	} `json:"content"`
+}
+
+// matrixBot delivers commands written in rooms by Matrix users to the chat
+// bot, !digits shows items which are redacted after exposure and !answer
+// scores recalled items
+type matrixBot struct {
+	config Matrix
+	chat   *chatBot
+
+	// rooms created for private replies by users
+	direct map[string]string
+}
+
+// serveMatrix runs the bot until it's interrupted, messages written before
+// the start are not answered.
+func serveMatrix(file string, args map[string]interface{}) error {
+	options, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+
+	matrix := config.Matrix
+	if matrix.Homeserver == "" || matrix.UserID == "" ||
+		matrix.AccessToken == "" {
+		return fmt.Errorf(
+			"homeserver, user_id and access_token are not set "+
+				"in [matrix] of %s",
+			options.Config,
+		)
+	}
+
+	bot := &matrixBot{
+		config: matrix,
+		chat:   newChatBot(file, options, matrixProfilePrefix, "!digits"),
+		direct: map[string]string{},
+	}
+
+	log.info("serving matrix bot", Fields{
+		"homeserver": matrix.Homeserver, "user": matrix.UserID,
+	})
+
+	since, err := bot.sync("", 0)
+	if err != nil {
+		return err
+	}
+
+	for {
+		next, err := bot.sync(since, matrixSyncTimeout)
+		if err != nil {
+			log.warn("can't sync with matrix homeserver", Fields{"error": err})
+			time.Sleep(matrixRetryDelay)
+			continue
+		}
+
+		since = next
+	}
+}
+
+// sync receives events since the batch and handles them, events of the
+// first sync are history and they are skipped.
+func (bot *matrixBot) sync(
+	since string, timeout time.Duration,
+) (string, error) {
+	query := url.Values{}
+	query.Set("timeout", strconv.Itoa(int(timeout/time.Millisecond)))
+	if since != "" {
+		query.Set("since", since)
+	}
+
+	var response matrixSync
+	err := bot.config.call(
+		http.MethodGet, "/sync?"+query.Encode(), nil, &response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	for room := range response.Rooms.Invite {
+		err := bot.config.call(
+			http.MethodPost, "/join/"+url.PathEscape(room), struct{}{}, nil,
+		)
+		if err != nil {
+			log.warn("can't join matrix room", Fields{
+				"room": room, "error": err,
+			})
+		}
+	}
+
+	if since == "" {
+		return response.NextBatch, nil
+	}
+
+	for room, joined := range response.Rooms.Join {
+		for _, event := range joined.Timeline.Events {
+			bot.handle(room, event)
+		}
+	}
+
+	return response.NextBatch, nil
+}
+
+// handle runs command written in the message and replies to the room.
+func (bot *matrixBot) handle(room string, event matrixEvent) {
+	if event.Type != "m.room.message" || event.Sender == bot.config.UserID ||
+		event.Content.MsgType != "m.text" {
+		return
+	}
+
+	fields := strings.Fields(event.Content.Body)
+	if len(fields) == 0 {
+		return
+	}
+
+	switch fields[0] {
+	case "!digits":
+		bot.start(room, event.Sender)
+
+	case "!answer":
+		content, err := bot.chat.answer(
+			event.Sender, strings.Join(fields[1:], " "),
+		)
+		if err != nil {
+			log.error("can't save matrix result", Fields{
+				"user": event.Sender, "error": err,
+			})
+
+			content = "can't save result: " + err.Error()
+		}
+
+		bot.replyPrivately(room, event.Sender, content)
+	}
+}
+
+// replyPrivately sends text to the room if only the user and the bot are in
+// it, otherwise to direct room with the user, so others in shared rooms
+// don't see correct items.
+func (bot *matrixBot) replyPrivately(room string, user string, text string) {
+	if bot.isDirect(room) {
+		bot.reply(room, text)
+		return
+	}
+
+	direct, err := bot.getDirectRoom(user)
+	if err != nil {
+		log.warn("can't create direct matrix room", Fields{
+			"user": user, "error": err,
+		})
+
+		bot.reply(room, user+", can't send result in direct message")
+		return
+	}
+
+	bot.reply(direct, text)
+	bot.reply(room, user+", result is sent in direct message")
+}
+
+// isDirect returns true if there are no other members in the room except
+// the bot and one user.
+func (bot *matrixBot) isDirect(room string) bool {
+	var response struct {
+		Joined map[string]json.RawMessage `json:"joined"`
+	}
+
+	err := bot.config.call(
+		http.MethodGet, "/rooms/"+url.PathEscape(room)+"/joined_members",
+		nil, &response,
+	)
+	if err != nil {
+		log.warn("can't get members of matrix room", Fields{
+			"room": room, "error": err,
+		})
+
+		return false
+	}
+
+	return len(response.Joined) <= 2
+}
+
+// getDirectRoom returns room of the bot and the user, it's created and
+// the user is invited on the first private reply.
+func (bot *matrixBot) getDirectRoom(user string) (string, error) {
+	if room, ok := bot.direct[user]; ok {
+		return room, nil
+	}
+
+	var response struct {
+		RoomID string `json:"room_id"`
+	}
+
+	err := bot.config.call(
+		http.MethodPost, "/createRoom",
+		map[string]interface{}{
+			"is_direct": true,
+			"invite":    []string{user},
+			"preset":    "trusted_private_chat",
+		},
+		&response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	bot.direct[user] = response.RoomID
+
+	return response.RoomID, nil
+}
+
+// start sends items to the room, the message with them is redacted after
+// exposure, answers are better written in direct rooms with the bot, result
+// with correct items is always sent there.
+func (bot *matrixBot) start(room string, user string) {
+	items := bot.chat.start(user)
+
+	id := bot.reply(room, fmt.Sprintf(
+		"%s, memorize in %s sec, then recall with !answer:\n%s",
+		user, bot.chat.getExposureSeconds(), strings.Join(items, " "),
+	))
+	if id == "" {
+		return
+	}
+
+	time.AfterFunc(bot.chat.options.Exposure, func() {
+		err := bot.config.call(
+			http.MethodPut,
+			"/rooms/"+url.PathEscape(room)+"/redact/"+url.PathEscape(id)+
+				"/"+getMatrixTransaction(),
+			map[string]string{"reason": "exposure is over"}, nil,
+		)
+		if err != nil {
+			log.warn("can't redact matrix message", Fields{"error": err})
+		}
+	})
+}
+
+// reply sends text message to the room and returns id of its event.
+func (bot *matrixBot) reply(room string, text string) string {
+	var response struct {
+		EventID string `json:"event_id"`
+	}
+
+	err := bot.config.call(
+		http.MethodPut,
+		"/rooms/"+url.PathEscape(room)+"/send/m.room.message/"+
+			getMatrixTransaction(),
+		map[string]string{"msgtype": "m.text", "body": text}, &response,
+	)
+	if err != nil {
+		log.warn("can't send matrix message", Fields{
+			"room": room, "error": err,
+		})
+	}
+
+	return response.EventID
+}
+
+// call requests client-server API, body and result are encoded as JSON.
+func (matrix Matrix) call(
+	method string, path string, body interface{}, result interface{},
+) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest(
+		method,
+		strings.TrimSuffix(matrix.Homeserver, "/")+"/_matrix/client/v3"+path,
+		bytes.NewReader(payload),
+	)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+matrix.AccessToken)
+
+	client := http.Client{Timeout: matrixSyncTimeout + matrixTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf(
+			"matrix: %s %s: %s: %s",
+			method, strings.SplitN(path, "?", 2)[0], response.Status,
+			strings.TrimSpace(string(content)),
+		)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(content, result)
+}
+
+// getMatrixTransaction returns unique id of request, the homeserver doesn't
+// repeat requests with the same id.
+func getMatrixTransaction() string {
+	return "short" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
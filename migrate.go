@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// layout of dates produced by time.Time.String(), legacy databases stored
+// dates this way
+const legacyDateLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// count of numbers assumed for legacy results that don't have count
+const legacyDefaultCount = 7
+
+// session as stored in legacy database, which was a plain array of sessions
+type legacySession struct {
+	Date        string   `json:"date"`
+	AvgDuration *float64 `json:"avg_duration"`
+	TotalScore  *int     `json:"total_score"`
+	Results     []Result `json:"results"`
+}
+
+func isLegacyDatabase(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("["))
+}
+
+func decodeLegacyDatabase(content []byte) (Database, []string, error) {
+	legacy := []legacySession{}
+	err := json.Unmarshal(content, &legacy)
+	if err != nil {
+		return Database{}, nil, err
+	}
+
+	var (
+		database = newDatabase()
+		notes    = []string{}
+	)
+
+	for index, item := range legacy {
+		session, sessionNotes := convertLegacySession(item)
+
+		for _, note := range sessionNotes {
+			notes = append(notes, fmt.Sprintf("session #%d: %s", index+1, note))
+		}
+
+		database.Sessions = append(database.Sessions, session)
+	}
+
+	return database, notes, nil
+}
+
+func convertLegacySession(item legacySession) (Session, []string) {
+	notes := []string{}
+
+	session := Session{Results: item.Results}
+	if session.Results == nil {
+		session.Results = []Result{}
+		notes = append(notes, "no results, defaulted to empty list")
+	}
+
+	date, err := parseLegacyDate(item.Date)
+	if err != nil {
+		notes = append(
+			notes,
+			fmt.Sprintf("can't parse date %q, defaulted to zero date", item.Date),
+		)
+	} else {
+		session.Date = date
+	}
+
+	var sumScore int
+	var sumDuration float64
+	for index, result := range session.Results {
+		if result.Count == 0 {
+			session.Results[index].Count = legacyDefaultCount
+			notes = append(notes, fmt.Sprintf(
+				"result #%d has no count, defaulted to %d",
+				index+1, legacyDefaultCount,
+			))
+		}
+
+		sumScore += result.Score
+		sumDuration += result.Duration
+	}
+
+	if item.TotalScore != nil {
+		session.TotalScore = *item.TotalScore
+	} else {
+		session.TotalScore = sumScore
+		notes = append(notes, "total score inferred from results")
+	}
+
+	if item.AvgDuration != nil {
+		session.AvgDuration = *item.AvgDuration
+	} else if len(session.Results) > 0 {
+		session.AvgDuration = sumDuration / float64(len(session.Results))
+		notes = append(notes, "average duration inferred from results")
+	}
+
+	return session, notes
+}
+
+func parseLegacyDate(date string) (time.Time, error) {
+	// time.Now().String() appends monotonic clock reading, like
+	// "m=+0.000012345", it can't be parsed back
+	if index := strings.Index(date, " m="); index > 0 {
+		date = date[:index]
+	}
+
+	return time.Parse(legacyDateLayout, date)
+}
+
+// migrateDatabase converts legacy database into current schema and writes it
+// into output, reporting what was inferred or defaulted into stderr.
+func migrateDatabase(file string, output string) error {
+	fd, err := openCompressed(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	reader := bufio.NewReader(fd)
+
+	first, err := peekDatabase(reader)
+	if err != nil {
+		return err
+	}
+
+	if first != '[' {
+		fmt.Fprintf(
+			os.Stderr, "%s is not in legacy format, nothing to migrate\n", file,
+		)
+		return nil
+	}
+
+	database, notes, err := decodeDatabaseStream(reader)
+	if err != nil {
+		return fmt.Errorf("can't decode legacy database %s: %s", file, err)
+	}
+
+	fmt.Fprintf(
+		os.Stderr, "%s: legacy format detected, %d sessions found\n",
+		file, len(database.Sessions),
+	)
+	for _, note := range notes {
+		fmt.Fprintln(os.Stderr, note)
+	}
+
+	content, err := encodeDatabase(database)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(output, content)
+}
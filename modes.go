@@ -0,0 +1,72 @@
+package main
+
+const (
+	// numbers are shown and should be recalled in order
+	modeDigits = "digits"
+
+	// table of key-value pairs is shown, then values are asked by keys
+	modeMapping = "mapping"
+
+	// sentence is shown and its words should be recalled in order
+	modeSentence = "sentence"
+
+	// acronyms and expansions from pairs file are asked in both directions
+	modeAcronym = "acronym"
+
+	// chess board coordinates are shown and should be recalled in order
+	modeChess = "chess"
+
+	// dates in random format are shown and should be recalled in order
+	modeDates = "dates"
+
+	// numbers are shown, then shown again either unchanged or altered in one
+	// position, the user judges whether they are the same
+	modeJudgment = "judgment"
+
+	// numbers are shown and should be recalled in reverse order, like in
+	// backward digit span test
+	modeReverse = "reverse"
+
+	// digits are shown as rows, which are recalled one by one
+	modeRows = "rows"
+
+	// digits are shown or spoken one by one at fixed rate
+	modeSpoken = "spoken"
+
+	// pairs of words are shown, then one word of every pair is shown and
+	// the other one should be recalled
+	modeAssociation = "association"
+
+	// cells of grid are lit one by one and should be selected in order,
+	// like in Corsi block-tapping test
+	modeSpatial = "spatial"
+
+	// random words are shown and should be recalled in order
+	modeWords = "words"
+)
+
+var modes = []string{
+	modeDigits, modeMapping, modeSentence, modeAcronym, modeChess, modeDates,
+	modeJudgment, modeRows, modeSpoken, modeReverse, modeWords, modeSpatial,
+	modeAssociation,
+}
+
+func isKnownMode(mode string) bool {
+	for _, known := range modes {
+		if mode == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getMode returns mode of the result, results recorded before modes were
+// added are digits.
+func (result Result) getMode() string {
+	if result.Mode == "" {
+		return modeDigits
+	}
+
+	return result.Mode
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// notifier builds command which shows desktop notification on the system
+type notifier func(title, message string) *exec.Cmd
+
+var notifiers = map[string]notifier{
+	"linux": func(title, message string) *exec.Cmd {
+		return exec.Command("notify-send", "--app-name=short", title, message)
+	},
+	"darwin": func(title, message string) *exec.Cmd {
+		return exec.Command(
+			"osascript", "-e",
+			fmt.Sprintf(
+				"display notification %s with title %s",
+				quoteAppleScript(message), quoteAppleScript(title),
+			),
+		)
+	},
+	"windows": func(title, message string) *exec.Cmd {
+		return exec.Command(
+			"powershell", "-NoProfile", "-Command",
+			fmt.Sprintf(
+				"Add-Type -AssemblyName System.Windows.Forms; "+
+					"$icon = New-Object System.Windows.Forms.NotifyIcon; "+
+					"$icon.Icon = [System.Drawing.SystemIcons]::Information; "+
+					"$icon.Visible = $true; "+
+					"$icon.ShowBalloonTip(10000, %s, %s, 'Info'); "+
+					"Start-Sleep -Seconds 10; $icon.Dispose()",
+				quotePowerShell(title), quotePowerShell(message),
+			),
+		)
+	},
+}
+
+// notifySession shows desktop notification with score of the session and
+// new bests, which is seen when the session was started by script or timer.
+func notifySession(database Database, session Session) error {
+	lines := []string{
+		fmt.Sprintf(
+			"%s tests, accuracy %s%%, %s sec per test",
+			formatInt(len(session.Results)),
+			formatFloat(getSessionAccuracy(session)*100, 1),
+			formatFloat(session.AvgDuration, 2),
+		),
+	}
+
+	lines = append(lines, getNewBests(database, session)...)
+
+	return notify(
+		"short: "+getSessionMode(session)+" session finished",
+		strings.Join(lines, "\n"),
+	)
+}
+
+// getNewBests returns descriptions of records of the mode broken by the
+// session, practice sessions don't break records.
+func getNewBests(database Database, session Session) []string {
+	if session.Practice {
+		return nil
+	}
+
+	mode := getSessionMode(session)
+
+	var (
+		bestSpan     int
+		bestAccuracy float64
+		found        bool
+	)
+
+	for _, previous := range database.Sessions {
+		if previous.Practice || getSessionMode(previous) != mode {
+			continue
+		}
+
+		found = true
+
+		if span := getMaxSpan(previous); span > bestSpan {
+			bestSpan = span
+		}
+
+		if accuracy := getSessionAccuracy(previous); accuracy > bestAccuracy {
+			bestAccuracy = accuracy
+		}
+	}
+
+	// the first session of the mode beats nothing
+	if !found {
+		return nil
+	}
+
+	bests := []string{}
+	if span := getMaxSpan(session); span > bestSpan {
+		bests = append(bests, fmt.Sprintf(
+			"new best span: %d, was %d", span, bestSpan,
+		))
+	}
+
+	if accuracy := getSessionAccuracy(session); accuracy > bestAccuracy {
+		bests = append(bests, fmt.Sprintf(
+			"new best accuracy: %s%%, was %s%%",
+			formatFloat(accuracy*100, 1), formatFloat(bestAccuracy*100, 1),
+		))
+	}
+
+	return bests
+}
+
+func notify(title, message string) error {
+	notifier, ok := notifiers[runtime.GOOS]
+	if !ok {
+		notifier = notifiers["linux"]
+	}
+
+	// notification may stay until it's closed, short exits without it
+	err := notifier(title, message).Start()
+	if err != nil {
+		return fmt.Errorf("can't show notification: %s", err)
+	}
+
+	return nil
+}
+
+func quoteAppleScript(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+func quotePowerShell(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// rules of every mode: what is shown, how to answer and how it's scored
+var modeRules = map[string][]string{
+	modeDigits: {
+		"Numbers are shown until you press Enter.",
+		"Type them back in the same order separated by spaces.",
+		"Numbers recalled in order until the first mistake are scored.",
+	},
+	modeReverse: {
+		"Numbers are shown until you press Enter.",
+		"Type them back from the last one to the first one.",
+		"Numbers recalled in order until the first mistake are scored.",
+	},
+	modeMapping: {
+		"Table of names and numbers is shown until you press Enter.",
+		"Then names are asked in random order, type the number of each.",
+		"Every correct number is scored.",
+	},
+	modeSentence: {
+		"Sentence is shown until you press Enter.",
+		"Type it back word by word.",
+		"Words at their positions are scored, case is ignored.",
+	},
+	modeWords: {
+		"Random words are shown until you press Enter.",
+		"Type them back in the same order separated by spaces.",
+		"Words at their positions are scored, case is ignored.",
+	},
+	modeAcronym: {
+		"Acronym or its expansion from the pairs file is asked.",
+		"Type the other one, correct answer is shown after mistakes.",
+		"Every correct answer is scored.",
+	},
+	modeChess: {
+		"Chess board coordinates are shown until you press Enter.",
+		"Type them back in the same order separated by spaces.",
+		"Squares recalled in order until the first mistake are scored.",
+	},
+	modeDates: {
+		"Dates are shown until you press Enter.",
+		"Type them back in the same order in the shown format.",
+		"Dates at their positions are scored.",
+	},
+	modeJudgment: {
+		"Numbers are shown, then shown again, maybe altered in one place.",
+		"Press s if they are the same, d if they are different.",
+		"Correct judgment scores all numbers, wrong one scores nothing.",
+	},
+	modeRows: {
+		"Rows of digits are shown, arrows scroll them, Enter hides them.",
+		"Type the rows one by one, empty row ends the recall.",
+		"Rows are scored by --scoring strategy.",
+	},
+	modeSpoken: {
+		"Digits are spoken one by one at fixed rate.",
+		"Type them back in the same order.",
+		"Digits recalled in order until the first mistake are scored.",
+	},
+	modeSpatial: {
+		"Cells of the grid light up one by one.",
+		"Move the cursor and press Enter on the cells in the same order.",
+		"Cells selected in order until the first mistake are scored.",
+	},
+	modeAssociation: {
+		"Pairs of words are shown until you press Enter.",
+		"One word of every pair is shown, type the other one.",
+		"Every correct word is scored, case is ignored.",
+	},
+}
+
+// nouns of scored items of modes whose scoring is chosen by --scoring
+var scoredItems = map[string]string{
+	modeDigits:  "Numbers recalled",
+	modeReverse: "Numbers recalled",
+	modeChess:   "Squares recalled",
+	modeSpatial: "Cells selected",
+}
+
+// rules of scoring strategies, rules of sequence strategies are formatted
+// with scored items of the mode
+var scoringRules = map[string]string{
+	scoringStrict:      "%s in order until the first mistake are scored.",
+	scoringPositional:  "%s at their positions are scored.",
+	scoringSet:         "%s are scored regardless of their positions.",
+	scoringLevenshtein: "%s are scored, missed or extra one costs a point.",
+	scoringRows:        "Full rows score fully, rows with one mistake half.",
+	scoringDigits:      "Every digit at its position is scored.",
+}
+
+// hidden_rules key of the config lists modes whose rules are not shown
+var hiddenRulesPattern = regexp.MustCompile(
+	`(?m)^hidden_rules\s*=\s*\[[^\]]*\]\n?`,
+)
+
+// getModeRules returns rules of the mode, showing and scoring of items
+// follow --expose and --scoring of the test.
+func getModeRules(options Options, test Test) []string {
+	rules := append([]string{}, modeRules[test.Mode]...)
+	if len(rules) < 3 {
+		return rules
+	}
+
+	if test.Exposure > 0 {
+		seconds := formatFloat(test.Exposure.Seconds(), 1)
+
+		rules[0] = strings.NewReplacer(
+			"until you press Enter", "for "+seconds+" seconds",
+			"Enter hides them", "they are hidden after "+seconds+" seconds",
+		).Replace(rules[0])
+	}
+
+	scoring := scoringRules[options.getScoring(test.Mode)]
+	switch {
+	case test.Mode == modeRows:
+		rules[2] = scoring
+	case scoredItems[test.Mode] != "":
+		rules[2] = fmt.Sprintf(scoring, scoredItems[test.Mode])
+	}
+
+	return rules
+}
+
+// getHiddenRules returns modes whose rules are not shown anymore.
+func getHiddenRules(config Config) map[string]bool {
+	hidden := map[string]bool{}
+	for _, mode := range config.HiddenRules {
+		hidden[mode] = true
+	}
+
+	return hidden
+}
+
+// hideRules adds the mode to hidden_rules of the config, the key is written
+// at the top of the file, so it's not inside any table, other lines are
+// kept as they are.
+func hideRules(file string, mode string) {
+	err := writeHiddenRules(file, mode)
+	if err != nil {
+		log.warn("can't hide rules of mode", Fields{
+			"mode":  mode,
+			"error": err,
+		})
+	}
+}
+
+func writeHiddenRules(file string, mode string) error {
+	// config is ignored in safe mode, so hidden modes are unknown
+	if safeMode {
+		return nil
+	}
+
+	config, _, err := loadConfig(file)
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	hidden := getHiddenRules(config)
+	hidden[mode] = true
+
+	names := []string{}
+	for _, known := range modes {
+		if hidden[known] {
+			names = append(names, strconv.Quote(known))
+		}
+	}
+
+	line := "hidden_rules = [" + strings.Join(names, ", ") + "]\n"
+	content = append(
+		[]byte(line), hiddenRulesPattern.ReplaceAll(content, nil)...,
+	)
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(file, content)
+}
+
+// hasResults tells if the mode was already played.
+func hasResults(database Database, mode string) bool {
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.getMode() == mode {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// showRules shows rules of the mode before its first test, the user may
+// hide them for good by pressing d.
+func showRules(options Options, test Test) error {
+	clearScreen()
+	drawOverlay(append(
+		[]string{"Mode: " + test.Mode, ""},
+		getModeRules(options, test)...,
+	)...)
+
+	_, height := screen.Size()
+	printCentered("Enter: start, d: don't show again", height/2+2)
+	screen.Flush()
+
+	key, err := waitKey('d')
+	if err != nil {
+		return err
+	}
+
+	if key != ui.KeyEnter {
+		hideRules(options.Config, test.Mode)
+	}
+
+	clearScreen()
+
+	return nil
+}
+
+// showNewRules shows rules of modes of the session, which were never played
+// and not hidden by the user.
+func showNewRules(
+	database Database, options Options, tests []Test, hidden map[string]bool,
+) error {
+	for _, test := range tests {
+		if hidden[test.Mode] || hasResults(database, test.Mode) {
+			continue
+		}
+
+		// every mode is explained once per session
+		hidden[test.Mode] = true
+
+		err := showRules(options, test)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// skill of bot opponent when config doesn't specify it
+const (
+	defaultOpponentSkill  = 0.7
+	defaultOpponentSpread = 0.15
+)
+
+// Opponent is [opponent] section of config, accuracy of the bot in every
+// trial is sampled from normal distribution with mean skill and standard
+// deviation spread.
+type Opponent struct {
+	Skill  float64 `toml:"skill"`
+	Spread float64 `toml:"spread"`
+}
+
+// Match is record of session played against the bot
+type Match struct {
+	Skill  float64 `json:"skill"`
+	Spread float64 `json:"spread"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+func (opponent Opponent) withDefaults() Opponent {
+	if opponent.Skill == 0 {
+		opponent.Skill = defaultOpponentSkill
+	}
+
+	if opponent.Spread == 0 {
+		opponent.Spread = defaultOpponentSpread
+	}
+
+	return opponent
+}
+
+func (opponent Opponent) validate() error {
+	switch {
+	case opponent.Skill < 0 || opponent.Skill > 1:
+		return fmt.Errorf("skill should be between 0 and 1")
+	case opponent.Spread < 0:
+		return fmt.Errorf("spread can't be negative")
+	}
+
+	return nil
+}
+
+// play samples score of the bot for the test of specified count of items.
+func (opponent Opponent) play(count int) int {
+	accuracy := opponent.Skill + opponent.Spread*sampleNormal()
+
+	return int(math.Round(clampFloat(accuracy, 0, 1) * float64(count)))
+}
+
+// sampleNormal returns standard normally distributed number, Box-Muller
+// transform of uniform numbers from randomInt.
+func sampleNormal() float64 {
+	const precision = 1 << 30
+
+	first := float64(randomInt(precision)+1) / (precision + 1)
+	second := float64(randomInt(precision)+1) / (precision + 1)
+
+	return math.Sqrt(-2*math.Log(first)) * math.Cos(2*math.Pi*second)
+}
+
+// record counts trial in the match, returns outcome for the user.
+func (match *Match) record(score, opponent int) string {
+	switch {
+	case score > opponent:
+		match.Wins++
+		return "you won"
+	case score < opponent:
+		match.Losses++
+		return "bot won"
+	default:
+		match.Draws++
+		return "draw"
+	}
+}
+
+func (match Match) String() string {
+	return fmt.Sprintf(
+		"won %d, lost %d, draw %d against bot of skill %.0f%%",
+		match.Wins, match.Losses, match.Draws, match.Skill*100,
+	)
+}
+
+// playOpponent samples score of the bot for the result, records outcome in
+// the match and shows it.
+func playOpponent(opponent *Opponent, match *Match, result *Result) error {
+	score := opponent.play(result.Count)
+	result.Opponent = &score
+
+	outcome := match.record(result.Score, score)
+
+	return showTrialOutcome(*match, outcome, *result)
+}
+
+// showTrialOutcome shows scores of the user and the bot after the test.
+func showTrialOutcome(match Match, outcome string, result Result) error {
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(
+		fmt.Sprintf(
+			"you: %d/%d, bot: %d/%d, %s",
+			result.Score, result.Count, *result.Opponent, result.Count,
+			outcome,
+		),
+		height/2-1,
+	)
+	printCentered(
+		fmt.Sprintf(
+			"match: %d-%d, draws: %d", match.Wins, match.Losses, match.Draws,
+		),
+		height/2+1,
+	)
+	printCentered("Enter: continue", height/2+3)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := wait()
+	if err != nil {
+		return err
+	}
+
+	clearScreen()
+
+	return nil
+}
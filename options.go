@@ -0,0 +1,569 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parameters of test session
+type Options struct {
+	Database    string
+	Config      string
+	Script      string
+	Plan        bool
+	Tests       int
+	Count       int
+	Min         int
+	Max         int
+	Lengths     []int
+	Mode        string
+	Pairs       []Pair
+	Corpus      []string
+	Words       []string
+	Scramble    bool
+	Retention   time.Duration
+	Schedule    string
+	Feedback    bool
+	PauseOnBlur bool
+	BlankOnBlur bool
+
+	RecordEnvironment bool
+	Record            bool
+
+	// feedback is skipped after the delay, zero means until Enter
+	FeedbackDelay time.Duration
+
+	// color correct items in feedback by accuracy at their positions, which
+	// is calculated for every mode at the start of session
+	Heat             bool
+	PositionAccuracy map[string][]float64
+
+	// recall numbers aloud using speech_command from config
+	Speech        bool
+	SpeechCommand string
+
+	// memory sport discipline the session is run by
+	Preset *Preset
+
+	// command which pronounces digits in spoken mode
+	SpeakCommand string
+
+	// strategy of scoring rows in rows mode
+	Scoring string
+
+	// template of the line printed after session instead of score
+	Template lineTemplate
+
+	// time items are shown for, zero means until Enter is pressed
+	Exposure time.Duration
+
+	// time of distractor task between exposure and recall
+	Interference time.Duration
+
+	// multiplier of all time limits
+	TimeScale float64
+
+	// change count of items by results like digit span test
+	Adaptive bool
+
+	// daily count of tests shown after the session, zero if not set
+	Goal int
+
+	// ask whether the next blocks should be harder or easier
+	AdjustBlocks bool
+
+	// only print parameters of the session without running it
+	DryRun bool
+
+	// run the session as warm-up without recording its results
+	Untracked bool
+
+	// ask subjective difficulty of every test
+	RateDifficulty bool
+
+	// copy summary of the session to clipboard
+	Copy bool
+
+	// show desktop notification when the session is finished
+	Notify bool
+
+	// person playing the session, only their sessions are taken into
+	// account and the session is tagged by it
+	Profile string
+
+	// bot played against in every test, skill is taken from config
+	Opponent *Opponent
+
+	// characters memorized in digits and reverse modes
+	Charset Charset
+
+	// seed and parameters of session played by challenge code
+	Challenge *Challenge
+
+	// seed of random numbers items are generated from, crypto/rand is used
+	// if it's not set
+	Seed *int64
+}
+
+func parseOptions(args map[string]interface{}) (Options, error) {
+	var (
+		options Options
+		err     error
+	)
+
+	for _, flag := range []struct {
+		name   string
+		target *int
+	}{
+		{"-n", &options.Tests},
+		{"-c", &options.Count},
+		{"-i", &options.Min},
+		{"-a", &options.Max},
+	} {
+		*flag.target, err = parseInt(flag.name, args[flag.name].(string))
+		if err != nil {
+			return Options{}, err
+		}
+	}
+
+	if value, ok := args["--digits"].(string); ok {
+		options.Min, options.Max, err = getDigitsRange(value)
+		if err != nil {
+			return Options{}, err
+		}
+	}
+
+	options.Database = expandHome(args["-f"].(string))
+	options.Config = expandHome(args["--config"].(string))
+	options.Script, _ = args["--script"].(string)
+	options.Plan = args["--plan"].(bool)
+
+	options.Goal, err = parseGoal(args)
+	if err != nil {
+		return Options{}, err
+	}
+
+	options.Lengths = []int{options.Count}
+	if lengths, ok := args["--lengths"].(string); ok {
+		options.Lengths, err = parseLengths(lengths)
+		if err != nil {
+			return Options{}, err
+		}
+	}
+
+	options.Schedule = args["--schedule"].(string)
+
+	options.Mode = args["--mode"].(string)
+	if args["--reverse"].(bool) {
+		if options.Mode != modeDigits {
+			return Options{}, fmt.Errorf(
+				"--reverse: only digits can be recalled in reverse order",
+			)
+		}
+
+		options.Mode = modeReverse
+	}
+	options.Scoring, _ = args["--scoring"].(string)
+
+	options.Charset, err = parseCharset(args["--charset"].(string))
+	if err != nil {
+		return Options{}, err
+	}
+
+	if format, ok := args["--format"].(string); ok {
+		options.Template, err = parseTemplate(format)
+		if err != nil {
+			return Options{}, fmt.Errorf("--format: %s", err)
+		}
+	}
+	if name, ok := args["--preset"].(string); ok {
+		options.Preset, err = getPreset(name)
+		if err != nil {
+			return Options{}, err
+		}
+
+		options.Mode = options.Preset.Mode
+		options.Tests = 1
+		options.Count = options.Preset.Count
+		options.Lengths = []int{options.Count}
+	}
+
+	if file, ok := args["--pairs"].(string); ok {
+		options.Pairs, err = loadPairs(expandHome(file))
+		if err != nil {
+			return Options{}, fmt.Errorf("--pairs: %s", err)
+		}
+	}
+
+	if file, ok := args["--corpus"].(string); ok {
+		options.Corpus, err = loadCorpus(expandHome(file))
+		if err != nil {
+			return Options{}, fmt.Errorf("--corpus: %s", err)
+		}
+
+		if len(options.Corpus) == 0 {
+			return Options{}, fmt.Errorf("--corpus: no sentences found")
+		}
+	}
+
+	if file, ok := args["--wordlist"].(string); ok {
+		options.Words, err = loadWords(expandHome(file))
+		if err != nil {
+			return Options{}, fmt.Errorf("--wordlist: %s", err)
+		}
+
+		if len(options.Words) == 0 {
+			return Options{}, fmt.Errorf("--wordlist: no words found")
+		}
+	}
+
+	options.Scramble = args["--scramble"].(bool)
+
+	retention, err := strconv.ParseFloat(args["--retention"].(string), 64)
+	if err != nil || retention < 0 {
+		return Options{}, fmt.Errorf(
+			"--retention: %q is not a valid count of seconds",
+			args["--retention"],
+		)
+	}
+
+	options.Retention = time.Duration(retention * float64(time.Second))
+
+	if value, ok := args["--expose"].(string); ok {
+		options.Exposure, err = parseSeconds("--expose", value)
+		if err != nil {
+			return Options{}, err
+		}
+	}
+
+	options.TimeScale, err = strconv.ParseFloat(
+		args["--time-scale"].(string), 64,
+	)
+	if err != nil || options.TimeScale <= 0 {
+		return Options{}, fmt.Errorf(
+			"--time-scale: %q is not a positive number", args["--time-scale"],
+		)
+	}
+
+	options.Retention = scaleDuration(options.Retention, options.TimeScale)
+
+	if value, ok := args["--seed"].(string); ok {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return Options{}, fmt.Errorf(
+				"--seed: %q is not a valid integer", value,
+			)
+		}
+
+		options.Seed = &seed
+	}
+
+	if value, ok := args["--interference"].(string); ok {
+		options.Interference, err = parseSeconds("--interference", value)
+		if err != nil {
+			return Options{}, err
+		}
+
+		options.Interference = scaleDuration(
+			options.Interference, options.TimeScale,
+		)
+	}
+
+	options.Heat = args["--heat"].(bool)
+	options.Feedback = args["--feedback"].(bool) || options.Heat
+
+	if value, ok := args["--feedback-delay"].(string); ok {
+		options.FeedbackDelay, err = parseSeconds("--feedback-delay", value)
+		if err != nil {
+			return Options{}, err
+		}
+
+		options.FeedbackDelay = scaleDuration(
+			options.FeedbackDelay, options.TimeScale,
+		)
+
+		options.Feedback = true
+	}
+
+	options.BlankOnBlur = args["--blank-on-blur"].(bool)
+	options.PauseOnBlur = args["--pause-on-blur"].(bool) || options.BlankOnBlur
+	options.RecordEnvironment = args["--record-env"].(bool)
+	options.Record = args["--record"].(bool)
+	options.Speech = args["--speech"].(bool)
+	options.DryRun = args["--dry-run"].(bool)
+	options.Untracked = args["--practice"].(bool)
+	options.Adaptive = args["--adaptive"].(bool)
+	options.AdjustBlocks = args["--adjust-blocks"].(bool)
+	options.RateDifficulty = args["--rate-difficulty"].(bool)
+	options.Copy = args["--copy"].(bool)
+	options.Notify = args["--notify"].(bool)
+
+	options.Profile, _ = args["--profile"].(string)
+	if options.Profile == "" {
+		options.Profile = getDefaultProfileName()
+	}
+
+	if code, ok := args["<code>"].(string); ok {
+		challenge, err := decodeChallenge(code)
+		if err != nil {
+			return Options{}, err
+		}
+
+		err = challenge.apply(&options)
+		if err != nil {
+			return Options{}, err
+		}
+
+		options.Challenge = &challenge
+	}
+
+	err = options.validate()
+	if err != nil {
+		return Options{}, err
+	}
+
+	return options, nil
+}
+
+func (options Options) validate() error {
+	switch {
+	case options.Tests <= 0:
+		return fmt.Errorf(
+			"-n: count of tests should be positive, got %d", options.Tests,
+		)
+	case options.Count <= 0:
+		return fmt.Errorf(
+			"-c: count of numbers should be positive, got %d", options.Count,
+		)
+	case options.Min < 0:
+		return fmt.Errorf(
+			"-i: minimum value can't be negative, got %d", options.Min,
+		)
+	case int64(options.Max) > maxNumber:
+		return fmt.Errorf(
+			"-a: maximum value can't be greater than %d, got %d",
+			maxNumber, options.Max,
+		)
+	case options.PauseOnBlur && runtime.GOOS == "windows":
+		// Windows console reports focus with console events, which are not
+		// passed to the application as focus changes
+		return fmt.Errorf("--pause-on-blur is not supported on Windows")
+	case options.Schedule != scheduleBlocked &&
+		options.Schedule != scheduleInterleaved:
+		return fmt.Errorf(
+			"--schedule: expected %s or %s, got %q",
+			scheduleBlocked, scheduleInterleaved, options.Schedule,
+		)
+	case !isKnownMode(options.Mode):
+		return fmt.Errorf(
+			"--mode: unknown mode %q, expected one of: %s",
+			options.Mode, strings.Join(modes, ", "),
+		)
+	case options.Mode == modeMapping && options.Pairs == nil &&
+		options.Count > len(mappingNames):
+		return fmt.Errorf(
+			"-c: only %d built-in names available for mapping mode, "+
+				"use --pairs to specify more",
+			len(mappingNames),
+		)
+	case options.Mode == modeWords && options.Words == nil &&
+		options.Count > len(builtinWords):
+		return fmt.Errorf(
+			"-c: only %d built-in words available for words mode, "+
+				"use --wordlist to specify more",
+			len(builtinWords),
+		)
+	case options.Mode == modeWords && options.Words != nil &&
+		options.Count > len(options.Words):
+		return fmt.Errorf(
+			"-c: only %d different words found in word list",
+			len(options.Words),
+		)
+	case options.Mode == modeAssociation && options.Pairs == nil &&
+		options.Count*2 > len(getWordList(options)):
+		return fmt.Errorf(
+			"-c: only %d pairs of words available for association mode",
+			len(getWordList(options))/2,
+		)
+	case options.Mode == modeAssociation && options.Pairs != nil &&
+		options.Count > len(options.Pairs):
+		return fmt.Errorf(
+			"-c: only %d pairs found in pairs file", len(options.Pairs),
+		)
+	case options.Mode == modeMapping && options.Pairs != nil &&
+		options.Count > len(options.Pairs):
+		return fmt.Errorf(
+			"-c: only %d pairs found in pairs file", len(options.Pairs),
+		)
+	case options.Scoring != "" && !isKnownScoring(options.Scoring):
+		return fmt.Errorf(
+			"--scoring: unknown strategy %q, expected one of: %s",
+			options.Scoring, strings.Join(scorings, ", "),
+		)
+	case options.Mode == modeRows && indexOf(rowsScorings, options.Scoring) < 0 &&
+		options.Scoring != "":
+		return fmt.Errorf(
+			"--scoring: rows mode is scored by: %s",
+			strings.Join(rowsScorings, ", "),
+		)
+	case safeMode && (options.Plan || options.Script != ""):
+		return fmt.Errorf(
+			"--script and --plan are defined in config, which is ignored " +
+				"by --safe",
+		)
+	case options.Preset != nil && options.Adaptive:
+		return fmt.Errorf("--adaptive can't be used with --preset")
+	case options.AdjustBlocks && (options.Adaptive || options.Preset != nil):
+		return fmt.Errorf(
+			"--adjust-blocks can't be used with --adaptive or --preset",
+		)
+	case options.AdjustBlocks && options.Schedule == scheduleInterleaved &&
+		options.Script == "":
+		// every interleaved test would be a block of its own
+		return fmt.Errorf(
+			"--adjust-blocks can't be used with interleaved schedule",
+		)
+	case options.Preset != nil && (options.Plan || options.Script != ""):
+		return fmt.Errorf("--preset can't be used with --plan or --script")
+	case !options.Charset.isDigits() &&
+		options.Mode != modeDigits && options.Mode != modeReverse:
+		return fmt.Errorf(
+			"--charset: only digits and reverse modes can use other charsets",
+		)
+	case !options.Charset.isDigits() && options.Speech:
+		return fmt.Errorf("--charset: only digits can be recalled by speech")
+	case options.Challenge != nil && (options.Adaptive || options.AdjustBlocks ||
+		options.Preset != nil || options.Plan || options.Script != ""):
+		return fmt.Errorf(
+			"challenge can't be used with --adaptive, --adjust-blocks, " +
+				"--preset, --plan or --script",
+		)
+	case options.Challenge != nil && options.Seed != nil:
+		return fmt.Errorf("--seed can't be used with challenge, it has own seed")
+	case options.Challenge != nil && options.TimeScale != 1:
+		return fmt.Errorf("--time-scale can't be used with challenge")
+	case options.Challenge != nil && (options.Pairs != nil ||
+		options.Corpus != nil || options.Words != nil):
+		return fmt.Errorf(
+			"challenge can't use --pairs, --corpus or --wordlist, files " +
+				"are not shared by its code",
+		)
+	case options.Interference > 0 &&
+		indexOf(interferenceModes, options.Mode) < 0:
+		return fmt.Errorf(
+			"--interference: items of %s mode are not hidden before recall",
+			options.Mode,
+		)
+	case options.Mode == modeAcronym && options.Pairs == nil:
+		return fmt.Errorf("--pairs: acronym mode requires pairs file")
+	case options.Mode == modeJudgment && options.Min == options.Max:
+		return fmt.Errorf(
+			"-i and -a: judgment mode needs range of different numbers to " +
+				"alter them",
+		)
+	case options.Min > options.Max:
+		return fmt.Errorf(
+			"-i and -a: minimum value (%d) can't be greater than maximum "+
+				"value (%d)",
+			options.Min, options.Max,
+		)
+	}
+
+	return nil
+}
+
+// parseSeconds parses seconds like 2.5 or duration with unit like 1500ms.
+func parseSeconds(flag string, value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			return 0, fmt.Errorf(
+				"%s: %q is not a valid count of seconds or duration",
+				flag, value,
+			)
+		}
+
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("%s: duration should be positive", flag)
+	}
+
+	return duration, nil
+}
+
+func scaleDuration(duration time.Duration, scale float64) time.Duration {
+	return time.Duration(float64(duration) * scale)
+}
+
+// numbers of --digits should fit into int on 64-bit platforms
+const maxDigits = 18
+
+// the largest number of maxDigits width, so size of range of numbers
+// doesn't overflow
+const maxNumber int64 = 1e18 - 1
+
+// getDigitsRange returns range of numbers of the width, one digit numbers
+// start from zero.
+func getDigitsRange(value string) (int, int, error) {
+	width, err := parseInt("--digits", value)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if width < 1 || width > maxDigits {
+		return 0, 0, fmt.Errorf(
+			"--digits: width should be between 1 and %d, got %d",
+			maxDigits, width,
+		)
+	}
+
+	max := 1
+	for i := 0; i < width; i++ {
+		max *= 10
+	}
+
+	if width == 1 {
+		return 0, max - 1, nil
+	}
+
+	return max / 10, max - 1, nil
+}
+
+func parseInt(flag string, value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a valid number", flag, value)
+	}
+
+	return number, nil
+}
+
+// expandHome replaces leading ~/ with home directory of the user, on
+// Windows ~/.config/ is replaced with the roaming application data directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+
+	if runtime.GOOS == "windows" && strings.HasPrefix(path, "~/.config/") {
+		dir, err := os.UserConfigDir()
+		if err == nil {
+			return filepath.Join(dir, filepath.FromSlash(path[10:]))
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, filepath.FromSlash(path[2:]))
+}
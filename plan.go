@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// progressive overload plan, session parameters grow week over week
+type Plan struct {
+	Start time.Time `toml:"start"`
+	Tests int       `toml:"tests"`
+	Count int       `toml:"count"`
+
+	// tests are increased by tests_step every tests_every weeks
+	TestsStep  int `toml:"tests_step"`
+	TestsEvery int `toml:"tests_every"`
+
+	// count of numbers is increased by count_step every count_every weeks,
+	// but only if accuracy of the previous week was at least min_accuracy
+	CountStep   int     `toml:"count_step"`
+	CountEvery  int     `toml:"count_every"`
+	MinAccuracy float64 `toml:"min_accuracy"`
+
+	// sessions per week the user commits to, used to report adherence
+	SessionsPerWeek int `toml:"sessions_per_week"`
+}
+
+type PlanWeek struct {
+	Start    time.Time
+	Tests    int
+	Count    int
+	Sessions int
+	Accuracy float64
+}
+
+func (plan Plan) isDefined() bool {
+	return !plan.Start.IsZero()
+}
+
+func (plan Plan) validate() error {
+	switch {
+	case plan.Tests <= 0:
+		return fmt.Errorf("tests should be positive")
+	case plan.Count <= 0:
+		return fmt.Errorf("count should be positive")
+	case plan.TestsStep < 0 || plan.CountStep < 0:
+		return fmt.Errorf("steps can't be negative")
+	case plan.TestsStep > 0 && plan.TestsEvery <= 0:
+		return fmt.Errorf("tests_every should be positive")
+	case plan.CountStep > 0 && plan.CountEvery <= 0:
+		return fmt.Errorf("count_every should be positive")
+	case plan.MinAccuracy < 0 || plan.MinAccuracy > 1:
+		return fmt.Errorf("min_accuracy should be between 0 and 1")
+	}
+
+	return nil
+}
+
+// getWeeks returns parameters and results of every week of the plan up to
+// the week of specified date, weeks start from the weekday.
+func (plan Plan) getWeeks(
+	database Database, now time.Time, weekStart time.Weekday,
+) []PlanWeek {
+	start := getWeek(plan.Start.In(now.Location()), weekStart)
+
+	weeks := []PlanWeek{}
+	for date := start; !date.After(now); date = date.AddDate(0, 0, 7) {
+		week := PlanWeek{Start: date, Tests: plan.Tests, Count: plan.Count}
+
+		index := len(weeks)
+		if index > 0 {
+			previous := weeks[index-1]
+			week.Count = previous.Count
+
+			if plan.TestsStep > 0 {
+				week.Tests = plan.Tests + plan.TestsStep*(index/plan.TestsEvery)
+			}
+
+			if plan.CountStep > 0 && index%plan.CountEvery == 0 &&
+				previous.Sessions > 0 &&
+				previous.Accuracy >= plan.MinAccuracy {
+				week.Count += plan.CountStep
+			}
+		}
+
+		week.Sessions, week.Accuracy = getPlannedResults(
+			database, date, date.AddDate(0, 0, 7),
+		)
+
+		weeks = append(weeks, week)
+	}
+
+	return weeks
+}
+
+// getCurrentWeek returns parameters of the plan for the week of specified
+// date.
+func (plan Plan) getCurrentWeek(
+	database Database, now time.Time, weekStart time.Weekday,
+) PlanWeek {
+	weeks := plan.getWeeks(database, now, weekStart)
+	if len(weeks) == 0 {
+		// plan starts in the future
+		return PlanWeek{Start: plan.Start, Tests: plan.Tests, Count: plan.Count}
+	}
+
+	return weeks[len(weeks)-1]
+}
+
+func getPlannedResults(
+	database Database, from, to time.Time,
+) (int, float64) {
+	var (
+		sessions int
+		tests    int
+		accuracy float64
+	)
+
+	for _, session := range database.Sessions {
+		if !session.Planned {
+			continue
+		}
+
+		if session.Date.Before(from) || !session.Date.Before(to) {
+			continue
+		}
+
+		sessions++
+		for _, result := range session.Results {
+			tests++
+			accuracy += getAccuracy(result)
+		}
+	}
+
+	if tests > 0 {
+		accuracy /= float64(tests)
+	}
+
+	return sessions, accuracy
+}
+
+// printPlan shows weeks of the plan and adherence of the profile to them,
+// empty profile means all profiles.
+func printPlan(file string, profile string, config Config) error {
+	if !config.Plan.isDefined() {
+		return fmt.Errorf("no plan defined in config")
+	}
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	database = database.getProfile(profile)
+
+	weeks := config.Plan.getWeeks(
+		database, clock().In(config.getLocation()), config.getWeekStart(),
+	)
+
+	adhered := 0
+	for index, week := range weeks {
+		status := ""
+		if config.Plan.SessionsPerWeek > 0 {
+			status = fmt.Sprintf(
+				"%s/%s sessions", formatInt(week.Sessions),
+				formatInt(config.Plan.SessionsPerWeek),
+			)
+
+			if week.Sessions >= config.Plan.SessionsPerWeek {
+				adhered++
+			}
+		} else {
+			status = formatInt(week.Sessions) + " sessions"
+		}
+
+		current := ""
+		if index == len(weeks)-1 {
+			current = " <- current"
+		}
+
+		fmt.Printf(
+			"%s  -n %-3d -c %-3d %-14s %5s%%%s\n",
+			week.Start.Format("2006-01-02"), week.Tests, week.Count,
+			status, formatFloat(week.Accuracy*100, 1), current,
+		)
+	}
+
+	if config.Plan.SessionsPerWeek > 0 && len(weeks) > 0 {
+		// current week is not over yet, so it's not counted as missed
+		total := len(weeks) - 1
+		if weeks[len(weeks)-1].Sessions >= config.Plan.SessionsPerWeek {
+			total++
+		}
+
+		if total > 0 {
+			fmt.Printf(
+				"\nadherence: %s/%s weeks (%s%%)\n",
+				formatInt(adhered), formatInt(total),
+				formatFloat(float64(adhered)/float64(total)*100, 0),
+			)
+		}
+	}
+
+	return nil
+}
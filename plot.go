@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type point struct {
+	X float64
+	Y float64
+}
+
+// characters used for scatter plot cells, by count of points in the cell
+var scatterDensity = []rune{' ', '.', ':', '*', '#'}
+
+// renderScatter renders points into text grid of specified size, points are
+// binned into grid cells and each cell shows how many points fell into it.
+// Y axis is fixed to [0, 1], X axis is scaled to maximum X value.
+func renderScatter(points []point, width, height int, xLabel string) []string {
+	maxX := 0.0
+	for _, point := range points {
+		maxX = math.Max(maxX, point.X)
+	}
+
+	if maxX == 0 {
+		maxX = 1
+	}
+
+	grid := make([][]int, height)
+	for row := range grid {
+		grid[row] = make([]int, width)
+	}
+
+	for _, point := range points {
+		column := int(point.X / maxX * float64(width-1))
+		row := int((1 - point.Y) * float64(height-1))
+		grid[clamp(row, 0, height-1)][clamp(column, 0, width-1)]++
+	}
+
+	lines := []string{}
+	for row, cells := range grid {
+		label := "    "
+		switch row {
+		case 0:
+			label = "100%"
+		case height / 2:
+			label = " 50%"
+		case height - 1:
+			label = "  0%"
+		}
+
+		line := []rune{}
+		for _, count := range cells {
+			line = append(line, scatterDensity[clamp(count, 0, len(scatterDensity)-1)])
+		}
+
+		lines = append(lines, label+" |"+string(line))
+	}
+
+	lines = append(lines, "     +"+strings.Repeat("-", width))
+
+	maxLabel := fmt.Sprintf("%.1f %s", maxX, xLabel)
+	lines = append(
+		lines,
+		"      0"+strings.Repeat(" ", clamp(width-len(maxLabel)-1, 1, width))+maxLabel,
+	)
+
+	return lines
+}
+
+// renderBar renders horizontal bar for value in [0, 1].
+func renderBar(value float64, width int) string {
+	return strings.Repeat("#", int(math.Round(value*float64(width))))
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
+// renderLine renders values as line chart of specified size, Y axis is
+// scaled from minimal to maximal value, X axis is index of value.
+func renderLine(values []float64, width, height int) []string {
+	minY, maxY := values[0], values[0]
+	for _, value := range values {
+		minY = math.Min(minY, value)
+		maxY = math.Max(maxY, value)
+	}
+
+	if maxY == minY {
+		maxY = minY + 1
+	}
+
+	grid := make([][]rune, height)
+	for row := range grid {
+		grid[row] = []rune(strings.Repeat(" ", width))
+	}
+
+	for index, value := range values {
+		column := 0
+		if len(values) > 1 {
+			column = index * (width - 1) / (len(values) - 1)
+		}
+
+		row := int(math.Round(
+			(maxY - value) / (maxY - minY) * float64(height-1),
+		))
+		grid[clamp(row, 0, height-1)][clamp(column, 0, width-1)] = '*'
+	}
+
+	lines := []string{}
+	for row, cells := range grid {
+		label := strings.Repeat(" ", 6)
+		switch row {
+		case 0:
+			label = fmt.Sprintf("%6.0f", maxY)
+		case height - 1:
+			label = fmt.Sprintf("%6.0f", minY)
+		}
+
+		lines = append(lines, label+" |"+string(cells))
+	}
+
+	return append(lines, "       +"+strings.Repeat("-", width))
+}
+
+// blocks of bar chart cells by eighths of the cell filled
+var barBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// renderBars renders values as vertical bars of specified height, Y axis
+// starts from zero, bars are split by gaps if they fit into the width.
+func renderBars(values []float64, width, height int) []string {
+	maxY := 0.0
+	for _, value := range values {
+		maxY = math.Max(maxY, value)
+	}
+
+	if maxY == 0 {
+		maxY = 1
+	}
+
+	return renderScaledBars(values, maxY, width, height)
+}
+
+// renderScaledBars renders bars with fixed top of Y axis, so charts of
+// different values can be compared.
+func renderScaledBars(
+	values []float64, maxY float64, width, height int,
+) []string {
+	gap := ""
+	if len(values)*2 <= width {
+		gap = " "
+	}
+
+	lines := []string{}
+	for row := 0; row < height; row++ {
+		label := strings.Repeat(" ", 6)
+		switch row {
+		case 0:
+			label = fmt.Sprintf("%6.2f", maxY)
+		case height - 1:
+			label = fmt.Sprintf("%6.2f", 0.0)
+		}
+
+		// eighths of the bar below the top of this row
+		bottom := (height - row - 1) * 8
+
+		line := []rune{}
+		for _, value := range values {
+			eighths := int(math.Round(value / maxY * float64(height*8)))
+			line = append(
+				line, barBlocks[clamp(eighths-bottom, 0, len(barBlocks)-1)],
+			)
+			line = append(line, []rune(gap)...)
+		}
+
+		lines = append(lines, label+" |"+string(line))
+	}
+
+	return append(
+		lines,
+		"       +"+strings.Repeat("-", len(values)*(1+len(gap))),
+	)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// count of latest results of every count of numbers used for prediction
+	predictionWindow = 50
+
+	// accuracy range where training is productive, difficulty outside of it
+	// is too easy or too hard
+	tooEasyAccuracy = 0.95
+	tooHardAccuracy = 0.4
+	targetAccuracy  = 0.75
+)
+
+type Prediction struct {
+	Count    int
+	Accuracy float64
+
+	// recommended count of numbers or zero if chosen count is fine
+	Recommended int
+}
+
+func (prediction Prediction) Score() float64 {
+	return prediction.Accuracy * float64(prediction.Count)
+}
+
+// predict estimates accuracy for specified mode and count of numbers from
+// history, returns false if there is not enough data.
+func predict(database Database, mode string, count int) (Prediction, bool) {
+	accuracies := getAccuracyByCount(database, mode)
+	if len(accuracies) == 0 {
+		return Prediction{}, false
+	}
+
+	accuracy, ok := interpolateAccuracy(accuracies, count)
+	if !ok {
+		return Prediction{}, false
+	}
+
+	prediction := Prediction{Count: count, Accuracy: accuracy}
+
+	switch {
+	case accuracy > tooEasyAccuracy:
+		prediction.Recommended = recommendCount(accuracies, count, true)
+	case accuracy < tooHardAccuracy && count > 1:
+		prediction.Recommended = recommendCount(accuracies, count, false)
+	}
+
+	return prediction, true
+}
+
+func getAccuracyByCount(database Database, mode string) map[int]float64 {
+	results := map[int][]Result{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.Count > 0 && result.getMode() == mode {
+				results[result.Count] = append(results[result.Count], result)
+			}
+		}
+	}
+
+	accuracies := map[int]float64{}
+	for count, items := range results {
+		if len(items) > predictionWindow {
+			items = items[len(items)-predictionWindow:]
+		}
+
+		sum := 0.0
+		for _, result := range items {
+			sum += getAccuracy(result)
+		}
+
+		accuracies[count] = sum / float64(len(items))
+	}
+
+	return accuracies
+}
+
+// interpolateAccuracy returns known accuracy for the count or interpolates
+// it linearly between nearest known counts.
+func interpolateAccuracy(accuracies map[int]float64, count int) (float64, bool) {
+	if accuracy, ok := accuracies[count]; ok {
+		return accuracy, true
+	}
+
+	lower, upper := 0, 0
+	for known := range accuracies {
+		if known < count && known > lower {
+			lower = known
+		}
+
+		if known > count && (upper == 0 || known < upper) {
+			upper = known
+		}
+	}
+
+	if lower == 0 || upper == 0 {
+		return 0, false
+	}
+
+	ratio := float64(count-lower) / float64(upper-lower)
+	return accuracies[lower] + (accuracies[upper]-accuracies[lower])*ratio, true
+}
+
+// recommendCount returns count of numbers in the direction of making
+// training harder or easier which accuracy is closest to target, or just the
+// next count in that direction if there is no productive count in history.
+func recommendCount(accuracies map[int]float64, count int, harder bool) int {
+	step := -1
+	if harder {
+		step = 1
+	}
+
+	best := 0
+	for known, accuracy := range accuracies {
+		if (known-count)*step <= 0 {
+			continue
+		}
+
+		if accuracy < tooHardAccuracy || accuracy > tooEasyAccuracy {
+			continue
+		}
+
+		if best == 0 || math.Abs(accuracy-targetAccuracy) <
+			math.Abs(accuracies[best]-targetAccuracy) {
+			best = known
+		}
+	}
+
+	if best == 0 {
+		return count + step
+	}
+
+	return best
+}
+
+// showPrediction shows expected score and waits for Enter.
+func showPrediction(prediction Prediction) error {
+	_, height := screen.Size()
+	y := height/2 - 1
+
+	clearScreen()
+
+	printCentered(fmt.Sprintf(
+		"expected score: %.1f/%d (%.0f%%)",
+		prediction.Score(), prediction.Count, prediction.Accuracy*100,
+	), y)
+
+	if prediction.Recommended != 0 {
+		difficulty := "too hard"
+		if prediction.Accuracy > tooEasyAccuracy {
+			difficulty = "too easy"
+		}
+
+		printCentered(fmt.Sprintf(
+			"%d numbers look %s for you, try -c %d",
+			prediction.Count, difficulty, prediction.Recommended,
+		), y+1)
+	}
+
+	printCentered("Press Enter to start", y+3)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := wait()
+	clearScreen()
+
+	return err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Preset is memory sport discipline, session of preset is a single test
+// scored by official rules of the discipline.
+type Preset struct {
+	Name        string
+	Description string
+
+	Mode     string
+	Count    int
+	Exposure time.Duration
+
+	// digits per row in rows mode
+	RowLength int
+	Binary    bool
+
+	// interval between digits in spoken mode
+	Rate time.Duration
+}
+
+var presets = []Preset{
+	{
+		Name:        "numbers-5min",
+		Description: "5-minute numbers, 10 rows of 40 digits, row scoring",
+		Mode:        modeRows,
+		Count:       400,
+		Exposure:    5 * time.Minute,
+		RowLength:   40,
+	},
+	{
+		Name:        "spoken-1s",
+		Description: "spoken numbers, 100 digits at 1 per second",
+		Mode:        modeSpoken,
+		Count:       100,
+		Rate:        time.Second,
+	},
+	{
+		Name:        "binary-5min",
+		Description: "5-minute binary, 25 rows of 30 digits, row scoring",
+		Mode:        modeRows,
+		Count:       750,
+		Exposure:    5 * time.Minute,
+		RowLength:   30,
+		Binary:      true,
+	},
+}
+
+func getPreset(name string) (*Preset, error) {
+	known := []string{}
+	for index, preset := range presets {
+		if preset.Name == name {
+			return &presets[index], nil
+		}
+
+		known = append(known, preset.Name+": "+preset.Description)
+	}
+
+	return nil, fmt.Errorf(
+		"--preset: unknown preset %q, expected one of:\n%s",
+		name, strings.Join(known, "\n"),
+	)
+}
+
+// getTest returns the only test of preset session.
+func (preset Preset) getTest() Test {
+	return Test{
+		Mode:      preset.Mode,
+		Count:     preset.Count,
+		Exposure:  preset.Exposure,
+		RowLength: preset.RowLength,
+		Binary:    preset.Binary,
+		Rate:      preset.Rate,
+	}
+}
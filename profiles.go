@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os/user"
+	"sort"
+	"strings"
+)
+
+// getDefaultProfileName returns name of profile of the default database,
+// which is name of the OS user.
+func getDefaultProfileName() string {
+	current, err := user.Current()
+	if err != nil || current.Username == "" {
+		return "default"
+	}
+
+	// Windows user names are prefixed by domain
+	name := current.Username
+	if index := strings.LastIndex(name, `\`); index >= 0 {
+		name = name[index+1:]
+	}
+
+	return name
+}
+
+// getProfileOption returns profile whose sessions are shown, it's set by
+// --profile, the OS user by default. Empty name with --all-profiles means
+// all profiles.
+func getProfileOption(args map[string]interface{}) string {
+	if all, _ := args["--all-profiles"].(bool); all {
+		return ""
+	}
+
+	profile, _ := args["--profile"].(string)
+	if profile == "" {
+		return getDefaultProfileName()
+	}
+
+	return profile
+}
+
+// getProfile returns name of profile the session was played by.
+func (session Session) getProfile() string {
+	if session.Profile == "" {
+		return getDefaultProfileName()
+	}
+
+	return session.Profile
+}
+
+// getProfile returns database with sessions of specified profile only, empty
+// name means all profiles.
+func (database Database) getProfile(name string) Database {
+	if name == "" {
+		return database
+	}
+
+	sessions := []Session{}
+	for _, session := range database.Sessions {
+		if session.getProfile() == name {
+			sessions = append(sessions, session)
+		}
+	}
+
+	database.Sessions = sessions
+
+	return database
+}
+
+// getProfileNames returns names of profiles which played sessions of the
+// database, sorted by name.
+func getProfileNames(database Database) []string {
+	known := map[string]bool{}
+	names := []string{}
+	for _, session := range database.Sessions {
+		name := session.getProfile()
+		if !known[name] {
+			known[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
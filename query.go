@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	orderDate  = "date"
+	orderMode  = "mode"
+	orderScore = "score"
+
+	// mode of sessions which tests are of different modes
+	modeMixed = "mixed"
+)
+
+var orders = []string{orderDate, orderMode, orderScore}
+
+// Query selects sessions of database, zero fields match any session.
+type Query struct {
+	Mode  string
+	Tag   string
+	Since time.Time
+	Until time.Time
+
+	// conditions of --where
+	Where []condition
+}
+
+func (query Query) match(session Session) bool {
+	if query.Mode != "" && getSessionMode(session) != query.Mode {
+		return false
+	}
+
+	if query.Tag != "" && !session.hasTag(query.Tag) {
+		return false
+	}
+
+	if !query.Since.IsZero() && session.Date.Before(query.Since) {
+		return false
+	}
+
+	if !query.Until.IsZero() && !session.Date.Before(query.Until) {
+		return false
+	}
+
+	for _, condition := range query.Where {
+		if !condition.match(session) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findSessions returns indexes of sessions matching the query, ordered by
+// specified field.
+func findSessions(
+	database Database, query Query, order string, descending bool,
+) []int {
+	indexes := []int{}
+	for index, session := range database.Sessions {
+		if query.match(session) {
+			indexes = append(indexes, index)
+		}
+	}
+
+	less := func(a, b Session) bool {
+		switch order {
+		case orderMode:
+			return getSessionMode(a) < getSessionMode(b)
+		case orderScore:
+			return getSessionAccuracy(a) < getSessionAccuracy(b)
+		default:
+			return a.Date.Before(b.Date)
+		}
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a := database.Sessions[indexes[i]]
+		b := database.Sessions[indexes[j]]
+		if descending {
+			return less(b, a)
+		}
+
+		return less(a, b)
+	})
+
+	return indexes
+}
+
+// getSessionMode returns mode of all tests of the session or "mixed".
+func getSessionMode(session Session) string {
+	if session.Pruned != nil {
+		return session.Pruned.Mode
+	}
+
+	mode := ""
+	for _, result := range session.Results {
+		switch {
+		case mode == "":
+			mode = result.getMode()
+		case mode != result.getMode():
+			return modeMixed
+		}
+	}
+
+	return mode
+}
+
+// getMaxSpan returns count of items in the longest test recalled without
+// mistakes.
+func getMaxSpan(session Session) int {
+	span := 0
+	for _, result := range session.Results {
+		if result.Score == result.Count && result.Count > span {
+			span = result.Count
+		}
+	}
+
+	return span
+}
+
+// getSessionAccuracy returns average accuracy of tests of the session.
+func getSessionAccuracy(session Session) float64 {
+	if session.Pruned != nil {
+		return session.Pruned.Accuracy
+	}
+
+	if len(session.Results) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, result := range session.Results {
+		sum += getAccuracy(result)
+	}
+
+	return sum / float64(len(session.Results))
+}
+
+// getSessionTests returns count of tests of the session, including pruned.
+func getSessionTests(session Session) int {
+	if session.Pruned != nil {
+		return session.Pruned.Tests
+	}
+
+	return len(session.Results)
+}
+
+func (session Session) hasTag(tag string) bool {
+	for _, sessionTag := range session.Tags {
+		if sessionTag == tag {
+			return true
+		}
+	}
+
+	return false
+}
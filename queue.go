@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// kinds of queued payloads
+const queueWebhook = "webhook"
+
+// queued payloads are dropped after attempts of [webhook] or this age, so
+// payloads which can't be delivered aren't sent forever
+const maxQueueAge = 7 * 24 * time.Hour
+
+// queuedPayload is request of network integration which failed, it's sent
+// again on the next run
+type queuedPayload struct {
+	Kind     string          `json:"kind"`
+	URL      string          `json:"url"`
+	Delivery string          `json:"delivery"`
+	Body     json.RawMessage `json:"body"`
+	Queued   time.Time       `json:"queued"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error,omitempty"`
+}
+
+func getQueueFile(database string) string {
+	return database + ".queue"
+}
+
+func loadQueue(file string) ([]queuedPayload, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []queuedPayload{}, nil
+		}
+
+		return nil, err
+	}
+
+	queue := []queuedPayload{}
+	err = json.Unmarshal(content, &queue)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode queue %s: %s", file, err)
+	}
+
+	return queue, nil
+}
+
+func saveQueue(file string, queue []queuedPayload) error {
+	if len(queue) == 0 {
+		err := os.Remove(file)
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	content, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(file, content)
+}
+
+// enqueue adds payload to the queue of the database.
+func enqueue(database string, payload queuedPayload) error {
+	file := getQueueFile(database)
+
+	queue, err := loadQueue(file)
+	if err != nil {
+		return err
+	}
+
+	return saveQueue(file, append(queue, payload))
+}
+
+// flushQueue tries to send every queued payload once, payloads which are
+// not sent are kept in the queue unless the receiver rejected them or they
+// are too old. Returns count of sent payloads.
+func flushQueue(database string, config Config) (int, error) {
+	file := getQueueFile(database)
+
+	queue, err := loadQueue(file)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := []queuedPayload{}
+	for _, payload := range queue {
+		err := sendQueued(payload, config)
+		if err != nil {
+			payload.Attempts++
+			payload.Error = err.Error()
+
+			if isRejected(err) ||
+				payload.Attempts >= config.Webhook.getAttempts() ||
+				time.Since(payload.Queued) > maxQueueAge {
+				log.warn("dropped queued payload", Fields{
+					"kind": payload.Kind, "delivery": payload.Delivery,
+					"attempts": payload.Attempts, "error": err,
+				})
+				continue
+			}
+
+			remaining = append(remaining, payload)
+			continue
+		}
+
+		log.info("sent queued payload", Fields{
+			"kind": payload.Kind, "delivery": payload.Delivery,
+		})
+	}
+
+	return len(queue) - len(remaining), saveQueue(file, remaining)
+}
+
+// flushQueueInBackground flushes the queue while the session runs, so
+// unreachable receivers don't delay its start. Returned function waits
+// until it's done, the queue is written by one of them at a time.
+func flushQueueInBackground(database string, config Config) func() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, err := flushQueue(database, config)
+		if err != nil {
+			log.warn("can't flush queue", Fields{"error": err})
+		}
+	}()
+
+	return func() {
+		<-done
+	}
+}
+
+func sendQueued(payload queuedPayload, config Config) error {
+	switch payload.Kind {
+	case queueWebhook:
+		// secret is not stored in the queue, it's taken from config
+		webhook := config.Webhook
+		webhook.URL = payload.URL
+
+		return sendWebhook(webhook, payload.Delivery, payload.Body)
+	}
+
+	return fmt.Errorf("unknown kind of payload %q", payload.Kind)
+}
+
+// runQueue prints payloads waiting in the queue and optionally sends them.
+func runQueue(database string, config string, retry bool) error {
+	release, err := acquireLock(database)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	if retry {
+		settings, _, err := loadConfig(config)
+		if err != nil {
+			return err
+		}
+
+		sent, err := flushQueue(database, settings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("sent: %d\n", sent)
+	}
+
+	queue, err := loadQueue(getQueueFile(database))
+	if err != nil {
+		return err
+	}
+
+	if len(queue) == 0 {
+		fmt.Println("queue is empty")
+		return nil
+	}
+
+	for _, payload := range queue {
+		fmt.Printf(
+			"%s  %-8s %s  delivery %s, %d attempts\n",
+			payload.Queued.Format("2006-01-02 15:04"), payload.Kind,
+			payload.URL, payload.Delivery, payload.Attempts,
+		)
+
+		if payload.Error != "" {
+			fmt.Printf("    %s\n", payload.Error)
+		}
+	}
+
+	return nil
+}
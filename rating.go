@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// rating of the user before the first test
+	initialRating = 1000.0
+
+	// maximal change of rating after a single test
+	ratingFactor = 32.0
+
+	// study time per item which doesn't change difficulty, shorter time
+	// makes test harder
+	referenceStudyTime = 1.0
+)
+
+// rating of test with zero items for every mode and increase of rating by
+// every item, items of different modes are remembered with different effort
+var (
+	modeDifficulty = map[string]float64{
+		modeDigits:      600,
+		modeMapping:     650,
+		modeSentence:    500,
+		modeAcronym:     600,
+		modeChess:       650,
+		modeDates:       700,
+		modeJudgment:    500,
+		modeRows:        400,
+		modeSpoken:      600,
+		modeReverse:     700,
+		modeWords:       550,
+		modeSpatial:     600,
+		modeAssociation: 650,
+	}
+
+	itemDifficulty = map[string]float64{
+		modeDigits:      60,
+		modeMapping:     80,
+		modeSentence:    30,
+		modeAcronym:     80,
+		modeChess:       70,
+		modeDates:       90,
+		modeJudgment:    40,
+		modeRows:        2,
+		modeSpoken:      40,
+		modeReverse:     70,
+		modeWords:       60,
+		modeSpatial:     90,
+		modeAssociation: 100,
+	}
+)
+
+// ratingPoint is rating of the user after the session
+type ratingPoint struct {
+	Date   time.Time
+	Rating float64
+}
+
+// getDifficulty returns rating of the test, which grows with count of items
+// and shorter study time.
+func getDifficulty(result Result) float64 {
+	mode := result.getMode()
+
+	difficulty := modeDifficulty[mode] +
+		itemDifficulty[mode]*float64(result.Count)
+
+	if result.Count > 0 && result.Duration > 0 {
+		perItem := result.Duration / float64(result.Count)
+		difficulty += clampFloat(
+			100*math.Log2(referenceStudyTime/perItem), -200, 200,
+		)
+	}
+
+	return difficulty
+}
+
+// updateRating updates rating by Elo formula, where accuracy of the test is
+// outcome of the game against the test.
+func updateRating(rating float64, result Result) float64 {
+	if result.Count == 0 {
+		return rating
+	}
+
+	accuracy := clampFloat(float64(result.Score)/float64(result.Count), 0, 1)
+	expected := 1 / (1 + math.Pow(10, (getDifficulty(result)-rating)/400))
+
+	return rating + ratingFactor*(accuracy-expected)
+}
+
+// getRatings replays all tests in order and returns rating after every
+// session, sessions which results are pruned don't change rating.
+func getRatings(database Database) []ratingPoint {
+	points := []ratingPoint{}
+
+	rating := initialRating
+	for _, index := range findSessions(database, Query{}, orderDate, false) {
+		session := database.Sessions[index]
+		for _, result := range session.Results {
+			rating = updateRating(rating, result)
+		}
+
+		points = append(points, ratingPoint{session.Date, rating})
+	}
+
+	return points
+}
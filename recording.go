@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// recorded screen contents of the session
+type Recording struct {
+	// date of the recorded session
+	Session time.Time `json:"session"`
+	Width   int       `json:"width"`
+	Height  int       `json:"height"`
+	Frames  []Frame   `json:"frames"`
+}
+
+type Frame struct {
+	// seconds since the start of the session
+	Time  float64  `json:"time"`
+	Lines []string `json:"lines"`
+}
+
+// recorder is terminal which remembers every flushed screen.
+type recorder struct {
+	ui.Terminal
+
+	start     time.Time
+	recording Recording
+}
+
+// getRecordingsFile returns recordings of the database, they may be
+// compressed in place by gzip or zstd.
+func getRecordingsFile(database string) string {
+	return findCompressed(database + ".recordings")
+}
+
+// startRecording wraps the current terminal with recorder.
+func startRecording() *recorder {
+	recorder := &recorder{Terminal: screen}
+	screen = recorder
+
+	return recorder
+}
+
+// stopRecording restores the wrapped terminal.
+func stopRecording(recorder *recorder) {
+	screen = recorder.Terminal
+}
+
+func (recorder *recorder) Init() error {
+	err := recorder.Terminal.Init()
+	if err != nil {
+		return err
+	}
+
+	recorder.start = clock()
+	recorder.recording.Width, recorder.recording.Height = recorder.Size()
+
+	return nil
+}
+
+func (recorder *recorder) Flush() error {
+	err := recorder.Terminal.Flush()
+	if err != nil {
+		return err
+	}
+
+	width, _ := recorder.Size()
+	lines := getScreenLines(recorder.CellBuffer(), width)
+
+	frames := recorder.recording.Frames
+	if len(frames) > 0 && isSameScreen(frames[len(frames)-1].Lines, lines) {
+		return nil
+	}
+
+	recorder.recording.Frames = append(frames, Frame{
+		Time:  clock().Sub(recorder.start).Seconds(),
+		Lines: lines,
+	})
+
+	return nil
+}
+
+func getScreenLines(cells []ui.Cell, width int) []string {
+	lines := []string{}
+	for start := 0; start+width <= len(cells) && width > 0; start += width {
+		line := []rune{}
+		for _, cell := range cells[start : start+width] {
+			if cell.Ch == 0 {
+				cell.Ch = ' '
+			}
+
+			line = append(line, cell.Ch)
+		}
+
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+
+	return lines
+}
+
+func isSameScreen(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// saveRecording appends recording of the finished session to the
+// recordings of the database.
+func saveRecording(database string, recording Recording) error {
+	content, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+
+	fd, err := appendCompressed(getRecordingsFile(database))
+	if err != nil {
+		return err
+	}
+
+	_, err = fd.Write(append(content, '\n'))
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func readRecordings(database string) ([]Recording, error) {
+	file := getRecordingsFile(database)
+
+	fd, err := openCompressed(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Recording{}, nil
+		}
+
+		return nil, err
+	}
+	defer fd.Close()
+
+	recordings := []Recording{}
+
+	reader := bufio.NewReader(fd)
+	for line := 1; ; line++ {
+		content, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(content))) > 0 {
+			var recording Recording
+			decodeErr := json.Unmarshal(content, &recording)
+			if decodeErr != nil {
+				return nil, fmt.Errorf("%s:%d: %s", file, line, decodeErr)
+			}
+
+			recordings = append(recordings, recording)
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return recordings, nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reminders daemon wakes up at least this often, so it isn't late after the
+// computer sleeps
+const reminderCheckInterval = time.Minute
+
+// Reminders is [reminders] section of config, schedule of notifications
+// sent by short remind
+type Reminders struct {
+	// times of day like "19:00" reminders are sent at
+	Daily []string `toml:"daily"`
+
+	// times of weekdays which replace daily times, like saturday = ["11:00"],
+	// empty list disables reminders of the day
+	Days map[string][]string `toml:"days"`
+
+	// range of time like "22:00-08:00" without reminders, reminder which
+	// falls into it is sent when it ends
+	QuietHours string `toml:"quiet_hours"`
+
+	// remind even if there is a session today already
+	Always bool `toml:"always"`
+}
+
+func (reminders Reminders) validate() error {
+	for _, value := range reminders.Daily {
+		_, err := parseClock(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	for day, values := range reminders.Days {
+		_, err := parseWeekday(day)
+		if err != nil {
+			return fmt.Errorf("days: unknown weekday %q", day)
+		}
+
+		for _, value := range values {
+			_, err := parseClock(value)
+			if err != nil {
+				return fmt.Errorf("days: %s: %s", day, err)
+			}
+		}
+	}
+
+	if reminders.QuietHours != "" {
+		_, _, err := reminders.getQuietHours()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseClock parses time of day like "07:30" as duration since midnight.
+func parseClock(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour +
+		time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// atClock returns time of the day by duration since midnight, so it's the
+// same time of day on days when clocks are changed.
+func atClock(day time.Time, offset time.Duration) time.Time {
+	year, month, date := day.Date()
+
+	return time.Date(
+		year, month, date, int(offset/time.Hour),
+		int(offset%time.Hour/time.Minute), 0, 0, day.Location(),
+	)
+}
+
+// getQuietHours returns start and end of quiet hours since midnight, quiet
+// hours end on the next day if the end is before the start.
+func (reminders Reminders) getQuietHours() (
+	time.Duration, time.Duration, error,
+) {
+	bounds := strings.Split(reminders.QuietHours, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf(
+			"invalid quiet_hours %q, expected HH:MM-HH:MM",
+			reminders.QuietHours,
+		)
+	}
+
+	start, err := parseClock(bounds[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("quiet_hours: %s", err)
+	}
+
+	end, err := parseClock(bounds[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("quiet_hours: %s", err)
+	}
+
+	return start, end, nil
+}
+
+// getTimes returns times of reminders of the day since midnight.
+func (reminders Reminders) getTimes(day time.Weekday) []time.Duration {
+	values := reminders.Daily
+	for name, times := range reminders.Days {
+		if strings.EqualFold(name, day.String()) {
+			values = times
+		}
+	}
+
+	times := []time.Duration{}
+	for _, value := range values {
+		offset, _ := parseClock(value)
+		times = append(times, offset)
+	}
+
+	return times
+}
+
+// getQuietEnd returns when quiet hours covering the date end, or the date
+// itself if it isn't in quiet hours.
+func (reminders Reminders) getQuietEnd(date time.Time) time.Time {
+	if reminders.QuietHours == "" {
+		return date
+	}
+
+	start, end, _ := reminders.getQuietHours()
+
+	day := getDay(date)
+	offset := time.Duration(date.Hour())*time.Hour +
+		time.Duration(date.Minute())*time.Minute
+
+	switch {
+	case start <= end && offset >= start && offset < end:
+		return atClock(day, end)
+	case start > end && offset >= start:
+		return atClock(day.AddDate(0, 0, 1), end)
+	case start > end && offset < end:
+		return atClock(day, end)
+	}
+
+	return date
+}
+
+// getNext returns time of the next reminder after now, reminders in quiet
+// hours are moved to their end. Returns false if there are no reminders.
+func (reminders Reminders) getNext(now time.Time) (time.Time, bool) {
+	var next time.Time
+
+	today := getDay(now)
+	for days := 0; days <= 7; days++ {
+		day := today.AddDate(0, 0, days)
+		for _, offset := range reminders.getTimes(day.Weekday()) {
+			date := reminders.getQuietEnd(atClock(day, offset))
+			if date.After(now) && (next.IsZero() || date.Before(next)) {
+				next = date
+			}
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// runReminders sends desktop notifications by schedule until it's killed,
+// days which already have a session of the profile are skipped. Times of
+// reminders are taken in the location.
+func runReminders(
+	file string, reminders Reminders, profile string, location *time.Location,
+) error {
+	next, ok := reminders.getNext(clock().In(location))
+	if !ok {
+		return fmt.Errorf("no reminders are set in [reminders] of config")
+	}
+
+	for {
+		log.info("next reminder", Fields{"time": next})
+		fmt.Printf("next reminder at %s\n", next.Format("2006-01-02 15:04"))
+
+		for clock().Before(next) {
+			wait := time.Until(next)
+			if wait > reminderCheckInterval {
+				wait = reminderCheckInterval
+			}
+
+			time.Sleep(wait)
+		}
+
+		err := remind(file, reminders, profile, clock().In(location))
+		if err != nil {
+			log.warn("can't send reminder", Fields{"error": err})
+		}
+
+		next, _ = reminders.getNext(clock().In(location))
+	}
+}
+
+func remind(
+	file string, reminders Reminders, profile string, now time.Time,
+) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	streak, today := getStreak(database.getProfile(profile), now)
+	if today && !reminders.Always {
+		log.info("reminder skipped, practiced today", Fields{"streak": streak})
+		return nil
+	}
+
+	message := "Time for a short session."
+	if streak > 0 {
+		message = fmt.Sprintf(
+			"Time for a short session, keep your %d days streak.", streak,
+		)
+	}
+
+	return notify("short", message)
+}
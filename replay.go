@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// default command to convert asciinema cast into GIF, invoked with paths of
+// the cast and the GIF
+const defaultGifCommand = "agg"
+
+// replaySession writes recording of session in asciinema v2 format, number
+// counts recorded sessions from the latest one.
+func replaySession(
+	file string, number string, cast string, gif string, command string,
+) error {
+	recordings, err := readRecordings(file)
+	if err != nil {
+		return err
+	}
+
+	if len(recordings) == 0 {
+		return fmt.Errorf(
+			"no recorded sessions in %s, use --record to record sessions", file,
+		)
+	}
+
+	index, err := strconv.Atoi(number)
+	if err != nil || index < 1 || index > len(recordings) {
+		return fmt.Errorf(
+			"<number> should be between 1 and %d", len(recordings),
+		)
+	}
+
+	recording := recordings[len(recordings)-index]
+
+	content, err := encodeCast(recording)
+	if err != nil {
+		return err
+	}
+
+	if gif == "" {
+		return writeOutput(cast, content)
+	}
+
+	if cast == "-" {
+		return fmt.Errorf("--gif requires --cast to be a file")
+	}
+
+	err = writeOutput(cast, content)
+	if err != nil {
+		return err
+	}
+
+	if command == "" {
+		command = defaultGifCommand
+	}
+
+	args := append(strings.Fields(command), cast, gif)
+
+	converter := exec.Command(args[0], args[1:]...)
+	converter.Stdout = os.Stderr
+	converter.Stderr = os.Stderr
+
+	err = converter.Run()
+	if err != nil {
+		return fmt.Errorf("can't convert %s into GIF: %s", cast, err)
+	}
+
+	return nil
+}
+
+// encodeCast encodes recording in asciinema v2 format, every frame redraws
+// the whole screen.
+func encodeCast(recording Recording) ([]byte, error) {
+	header, err := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     recording.Width,
+		"height":    recording.Height,
+		"timestamp": recording.Session.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{string(header)}
+	for _, frame := range recording.Frames {
+		output := "\x1b[H\x1b[2J"
+		for y, line := range frame.Lines {
+			if line != "" {
+				output += fmt.Sprintf("\x1b[%d;1H%s", y+1, line)
+			}
+		}
+
+		event, err := json.Marshal([]interface{}{frame.Time, "o", output})
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, string(event))
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
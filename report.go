@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+)
+
+// reportPoint is point of chart of the report, label is shown on hover
+type reportPoint struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Label string  `json:"label"`
+}
+
+// reportCharts is data of charts embedded into the report
+type reportCharts struct {
+	// average score of tests of every session by its date in milliseconds
+	Scores []reportPoint `json:"scores"`
+
+	// count of sessions by the longest test recalled without mistakes
+	Spans []reportPoint `json:"spans"`
+
+	// accuracy of every test in percents by its duration in seconds
+	Tests []reportPoint `json:"tests"`
+}
+
+// reportTemplate is standalone page, charts are drawn by the embedded
+// script as SVG, so the report works offline.
+var reportTemplate = template.Must(template.New("report").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>short report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h2 { margin-top: 2em; font-weight: normal; }
+svg { font-size: 11px; }
+.axis { stroke: #999; }
+.line { fill: none; stroke: #36c; stroke-width: 1.5; }
+.point, .bar { fill: #36c; }
+.scatter { fill: #36c; fill-opacity: 0.3; }
+</style>
+</head>
+<body>
+<h1>short</h1>
+<p>
+{{.Sessions}} sessions and {{.Tests}} tests
+{{if .Profile}}of {{.Profile}} {{end}}from {{.Since}} to {{.Until}}, generated {{.Generated}}.
+</p>
+<h2>average score of tests by sessions</h2>
+<div id="scores"></div>
+<h2>sessions by the longest test recalled without mistakes</h2>
+<div id="spans"></div>
+<h2>accuracy of tests by their duration</h2>
+<div id="tests"></div>
+<script>
+var data = {{.Charts}};
+
+var svgNS = "http://www.w3.org/2000/svg";
+
+function element(parent, name, attributes, text) {
+	var node = document.createElementNS(svgNS, name);
+	for (var key in attributes) {
+		node.setAttribute(key, attributes[key]);
+	}
+	if (text !== undefined) {
+		node.textContent = text;
+	}
+	parent.appendChild(node);
+	return node;
+}
+
+function formatNumber(value) {
+	return String(Math.round(value * 100) / 100);
+}
+
+function formatDate(value) {
+	return new Date(value).toISOString().slice(0, 10);
+}
+
+// chart draws line, bars or scatter of points into element by id.
+function chart(id, type, points, formatX, unitY) {
+	var width = 720, height = 280;
+	var left = 50, right = 20, top = 10, bottom = 30;
+
+	var svg = element(document.getElementById(id), "svg", {
+		width: width, height: height,
+		viewBox: "0 0 " + width + " " + height
+	});
+
+	if (points.length == 0) {
+		element(svg, "text", {x: left, y: height / 2}, "no data");
+		return;
+	}
+
+	var xs = points.map(function(point) { return point.x; });
+	var ys = points.map(function(point) { return point.y; });
+
+	var minX = Math.min.apply(null, xs), maxX = Math.max.apply(null, xs);
+	var maxY = Math.max.apply(null, ys);
+	if (type == "bars") {
+		minX -= 0.5;
+		maxX += 0.5;
+	}
+	if (minX == maxX) {
+		minX -= 1;
+		maxX += 1;
+	}
+	if (maxY <= 0) {
+		maxY = 1;
+	}
+
+	function scaleX(x) {
+		return left + (x - minX) / (maxX - minX) * (width - left - right);
+	}
+
+	function scaleY(y) {
+		return height - bottom - y / maxY * (height - top - bottom);
+	}
+
+	element(svg, "line", {
+		"class": "axis", x1: left, y1: top, x2: left, y2: height - bottom
+	});
+	element(svg, "line", {
+		"class": "axis", x1: left, y1: height - bottom,
+		x2: width - right, y2: height - bottom
+	});
+	element(svg, "text", {
+		x: left - 5, y: top + 10, "text-anchor": "end"
+	}, formatNumber(maxY) + unitY);
+	element(svg, "text", {
+		x: left - 5, y: height - bottom, "text-anchor": "end"
+	}, "0" + unitY);
+
+	if (type == "bars") {
+		var barWidth = (scaleX(1) - scaleX(0)) * 0.8;
+		points.forEach(function(point) {
+			var bar = element(svg, "rect", {
+				"class": "bar", x: scaleX(point.x) - barWidth / 2,
+				y: scaleY(point.y), width: barWidth,
+				height: height - bottom - scaleY(point.y)
+			});
+			element(bar, "title", {}, point.label);
+			element(svg, "text", {
+				x: scaleX(point.x), y: height - bottom + 15,
+				"text-anchor": "middle"
+			}, formatX(point.x));
+		});
+		return;
+	}
+
+	element(svg, "text", {
+		x: left, y: height - bottom + 15
+	}, formatX(minX));
+	element(svg, "text", {
+		x: width - right, y: height - bottom + 15, "text-anchor": "end"
+	}, formatX(maxX));
+
+	if (type == "line") {
+		element(svg, "polyline", {
+			"class": "line",
+			points: points.map(function(point) {
+				return scaleX(point.x) + "," + scaleY(point.y);
+			}).join(" ")
+		});
+	}
+
+	points.forEach(function(point) {
+		var circle = element(svg, "circle", {
+			"class": type == "line" ? "point" : "scatter",
+			cx: scaleX(point.x), cy: scaleY(point.y),
+			r: type == "line" ? 2.5 : 4
+		});
+		element(circle, "title", {}, point.label);
+	});
+}
+
+chart("scores", "line", data.scores, formatDate, "");
+chart("spans", "bars", data.spans, formatNumber, "");
+chart("tests", "scatter", data.tests, function(value) {
+	return formatNumber(value) + "s";
+}, "%");
+</script>
+</body>
+</html>
+`))
+
+// writeReport writes standalone HTML page with charts of scored sessions
+// of the profile into output, - is stdout.
+func writeReport(file string, profile string, output string) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	database = database.getProfile(profile).getScored()
+
+	if len(database.Sessions) == 0 {
+		return fmt.Errorf("no sessions recorded yet")
+	}
+
+	tests := 0
+	for _, session := range database.Sessions {
+		tests += getSessionTests(session)
+	}
+
+	sessions := database.Sessions
+
+	buffer := bytes.Buffer{}
+	err = reportTemplate.Execute(&buffer, map[string]interface{}{
+		"Profile":   profile,
+		"Sessions":  len(sessions),
+		"Tests":     tests,
+		"Since":     sessions[0].Date.Format("2006-01-02"),
+		"Until":     sessions[len(sessions)-1].Date.Format("2006-01-02"),
+		"Generated": time.Now().Format("2006-01-02 15:04"),
+		"Charts":    getReportCharts(database),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(output, buffer.Bytes())
+}
+
+// getReportCharts collects points of charts, sessions whose results are
+// pruned by retention policy have no scores and durations.
+func getReportCharts(database Database) reportCharts {
+	charts := reportCharts{
+		Scores: []reportPoint{},
+		Spans:  []reportPoint{},
+		Tests:  []reportPoint{},
+	}
+
+	spans := map[int]int{}
+	for _, session := range database.Sessions {
+		if len(session.Results) == 0 {
+			continue
+		}
+
+		score := 0
+		for _, result := range session.Results {
+			score += result.Score
+
+			accuracy := getAccuracy(result) * 100
+			charts.Tests = append(charts.Tests, reportPoint{
+				X: result.Duration,
+				Y: accuracy,
+				Label: fmt.Sprintf(
+					"%s: %d of %d in %.1fs",
+					session.Date.Format("2006-01-02 15:04"),
+					result.Score, result.Count, result.Duration,
+				),
+			})
+		}
+
+		average := float64(score) / float64(len(session.Results))
+		charts.Scores = append(charts.Scores, reportPoint{
+			X: float64(session.Date.UnixNano() / int64(time.Millisecond)),
+			Y: average,
+			Label: fmt.Sprintf(
+				"%s: %.2f", session.Date.Format("2006-01-02 15:04"), average,
+			),
+		})
+
+		spans[getMaxSpan(session)]++
+	}
+
+	counts := []int{}
+	for span := range spans {
+		counts = append(counts, span)
+	}
+
+	sort.Ints(counts)
+
+	for _, span := range counts {
+		charts.Spans = append(charts.Spans, reportPoint{
+			X:     float64(span),
+			Y:     float64(spans[span]),
+			Label: fmt.Sprintf("span %d: %d sessions", span, spans[span]),
+		})
+	}
+
+	return charts
+}
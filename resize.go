@@ -0,0 +1,51 @@
+package main
+
+import "github.com/kovetskiy/short/internal/ui"
+
+// recenterScreen moves content of the screen to the center of the resized
+// terminal, so numbers shown during the test stay in the middle.
+func recenterScreen() {
+	width, height := screen.Size()
+	if width == 0 {
+		return
+	}
+
+	cells := append([]ui.Cell{}, screen.CellBuffer()...)
+
+	// terminal syncs the size of the buffer with the window on clear
+	screen.Clear(ui.ColorDefault, ui.ColorDefault)
+
+	newWidth, newHeight := screen.Size()
+
+	dx := getCenterShift(width, newWidth)
+	dy := getCenterShift(height, newHeight)
+
+	for index, cell := range cells {
+		if isBlankCell(cell) {
+			continue
+		}
+
+		screen.SetCell(
+			index%width+dx, index/width+dy, cell.Ch, cell.Fg, cell.Bg,
+		)
+	}
+
+	screen.Flush()
+}
+
+// getCenterShift returns how far content centered in the old size moves
+// to be centered in the new one.
+func getCenterShift(size, newSize int) int {
+	return newSize/2 - size/2
+}
+
+func isBlankCell(cell ui.Cell) bool {
+	return (cell.Ch == 0 || cell.Ch == ' ') && cell.Bg == ui.ColorDefault
+}
+
+// getInputPosition returns position of input which replaces the text
+// centered on the screen, the terminal could be resized while it was shown.
+func getInputPosition(text string) (int, int) {
+	width, height := screen.Size()
+	return getCenteredX(text, width), height / 2
+}
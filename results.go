@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// errRetry is returned by session when the user wants to run it again
+var errRetry = errors.New("retry requested by user")
+
+// view is full-screen state drawn after every key, views are not aware of
+// tests, so they can be shown by any part of the application
+type view interface {
+	draw()
+
+	// handle reacts to the key, returns true when the view is closed
+	handle(event ui.Event) (bool, error)
+}
+
+// runView draws the view and passes keys to it until it's closed.
+func runView(view view) error {
+	for {
+		view.draw()
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		done, err := view.handle(event)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// resultsView lists tests of finished session, the selected test is shown
+// with correct and entered items
+type resultsView struct {
+	results  []Result
+	selected int
+	retry    bool
+
+	// copies summary of the session to clipboard
+	copy   func() error
+	status string
+}
+
+// showResults shows results of the session, returns true if the user wants
+// to run the session again.
+func showResults(results []Result, copy func() error) (bool, error) {
+	view := &resultsView{results: results, copy: copy}
+
+	err := runView(view)
+
+	return view.retry, err
+}
+
+func (view *resultsView) draw() {
+	width, height := screen.Size()
+
+	clearScreen()
+
+	score, total, duration := 0, 0, 0.0
+	for _, result := range view.results {
+		score += result.Score
+		total += result.Count
+		duration += result.Duration
+	}
+
+	printText(
+		fmt.Sprintf(
+			"score %d of %d, %s sec per test",
+			score, total,
+			formatFloat(duration/float64(len(view.results)), 2),
+		),
+		0, 0,
+	)
+
+	// rows under the list are taken by the selected test and the prompt
+	page := clamp(height-7, 1, len(view.results))
+	offset := clamp(view.selected-page+1, 0, len(view.results)-page)
+
+	for row := 0; row < page; row++ {
+		index := offset + row
+
+		marker := " "
+		if index == view.selected {
+			marker = ">"
+		}
+
+		result := view.results[index]
+		printText(
+			fmt.Sprintf(
+				"%s %3d. %-8s %3d of %-3d %6s sec",
+				marker, index+1, result.getMode(), result.Score, result.Count,
+				formatFloat(result.Duration, 2),
+			),
+			0, row+2,
+		)
+	}
+
+	shown, entered := getRecalledItems(view.results[view.selected])
+	if len(shown) > 0 {
+		printStyled(
+			styledLine{{"correct: " + strings.Join(shown, " "), theme.text}},
+			height-4,
+		)
+		printStyled(
+			getDiffLine("entered: ", entered, " ", isSameAt(shown, entered)),
+			height-3,
+		)
+	}
+
+	if view.status != "" {
+		printCentered(view.status, height-2)
+	}
+
+	prompt := "up/down: select, r: retry, c: copy, q: quit"
+	printText(prompt, clamp((width-len(prompt))/2, 0, width), height-1)
+
+	screen.HideCursor()
+	screen.Flush()
+}
+
+func (view *resultsView) handle(event ui.Event) (bool, error) {
+	if direction, ok := keys.getDirection(event); ok {
+		view.selected = clamp(
+			view.selected+direction.y, 0, len(view.results)-1,
+		)
+
+		return false, nil
+	}
+
+	switch {
+	case event.Ch == 'c':
+		view.status = "summary is copied to clipboard"
+
+		// clipboard is a convenience, so its errors don't end the session
+		err := view.copy()
+		if err != nil {
+			view.status = err.Error()
+		}
+	case event.Ch == 'r':
+		view.retry = true
+		return true, nil
+	case event.Ch == 'q', event.Key == ui.KeyEnter,
+		event.Key == ui.KeyEsc:
+		return true, nil
+	case event.Key == ui.KeyCtrlC, event.Key == ui.KeyCtrlZ:
+		// tests are finished already, so the session is saved anyway
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// getRecalledItems returns shown and entered items of the test, items of
+// mapping mode are names with numbers.
+func getRecalledItems(result Result) ([]string, []string) {
+	if result.shown != nil {
+		return result.shown, result.entered
+	}
+
+	if len(result.Pairs) > 0 {
+		shown, entered := []string{}, []string{}
+		for _, pair := range result.Pairs {
+			shown = append(shown, pair.Key+"="+pair.Value)
+			entered = append(entered, pair.Key+"="+pair.Answer)
+		}
+
+		return shown, entered
+	}
+
+	return result.Items, result.Input
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const eventDataPruned = "data_pruned"
+
+// Retention defines how long data is kept, zero keeps data forever, session
+// aggregates are always kept.
+type Retention struct {
+	// screen recordings of sessions
+	Recordings Duration `toml:"recordings"`
+
+	// results of separate tests, sessions keep their mode, count of tests
+	// and accuracy
+	Trials Duration `toml:"trials"`
+
+	// application events
+	Events Duration `toml:"events"`
+}
+
+// pruneTrials removes results of sessions started before the given time,
+// returns count of pruned sessions.
+func pruneTrials(database *Database, before time.Time) int {
+	pruned := 0
+	for index := range database.Sessions {
+		session := &database.Sessions[index]
+		if !session.Date.Before(before) || len(session.Results) == 0 {
+			continue
+		}
+
+		session.Pruned = &Aggregate{
+			Mode:     getSessionMode(*session),
+			Tests:    getSessionTests(*session),
+			Accuracy: getSessionAccuracy(*session),
+		}
+		session.Results = nil
+
+		pruned++
+	}
+
+	return pruned
+}
+
+// pruneRecordings removes recordings of sessions started before the given
+// time, returns count of removed recordings.
+func pruneRecordings(database string, before time.Time) (int, error) {
+	recordings, err := readRecordings(database)
+	if err != nil {
+		return 0, err
+	}
+
+	content := []byte{}
+	for _, recording := range recordings {
+		if recording.Session.Before(before) {
+			continue
+		}
+
+		line, err := json.Marshal(recording)
+		if err != nil {
+			return 0, err
+		}
+
+		content = append(append(content, line...), '\n')
+	}
+
+	pruned := len(recordings) - countLines(content)
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	return pruned, writeFile(getRecordingsFile(database), content)
+}
+
+// pruneEvents removes events which happened before the given time, returns
+// count of removed events.
+func pruneEvents(database string, before time.Time) (int, error) {
+	events, err := readEvents(getEventsFile(database))
+	if err != nil {
+		return 0, err
+	}
+
+	content := []byte{}
+	for _, event := range events {
+		if event.Date.Before(before) {
+			continue
+		}
+
+		line, err := json.Marshal(event)
+		if err != nil {
+			return 0, err
+		}
+
+		content = append(append(content, line...), '\n')
+	}
+
+	pruned := len(events) - countLines(content)
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	return pruned, writeFile(getEventsFile(database), content)
+}
+
+func countLines(content []byte) int {
+	count := 0
+	for _, symbol := range content {
+		if symbol == '\n' {
+			count++
+		}
+	}
+
+	return count
+}
+
+// applyRetention prunes data of the database older than the retention
+// allows, the database itself is changed in memory and should be saved,
+// recordings and events are rewritten in place. Returns report of what was
+// pruned.
+func applyRetention(
+	file string, database *Database, retention Retention, now time.Time,
+) ([]string, error) {
+	report := []string{}
+	details := map[string]interface{}{}
+
+	if retention.Trials.Duration > 0 {
+		pruned := pruneTrials(database, now.Add(-retention.Trials.Duration))
+		if pruned > 0 {
+			details["trials"] = pruned
+			report = append(report, fmt.Sprintf(
+				"pruned tests of %d sessions older than %s",
+				pruned, retention.Trials,
+			))
+		}
+	}
+
+	if retention.Recordings.Duration > 0 {
+		pruned, err := pruneRecordings(
+			file, now.Add(-retention.Recordings.Duration),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if pruned > 0 {
+			details["recordings"] = pruned
+			report = append(report, fmt.Sprintf(
+				"pruned %d recordings older than %s",
+				pruned, retention.Recordings,
+			))
+		}
+	}
+
+	if retention.Events.Duration > 0 {
+		pruned, err := pruneEvents(file, now.Add(-retention.Events.Duration))
+		if err != nil {
+			return nil, err
+		}
+
+		if pruned > 0 {
+			details["events"] = pruned
+			report = append(report, fmt.Sprintf(
+				"pruned %d events older than %s",
+				pruned, retention.Events,
+			))
+		}
+	}
+
+	if len(details) > 0 {
+		logEvent(file, eventDataPruned, details)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// intervals between retests of an item by its Leitner box, correct answer
+// moves item to the next box, wrong answer moves it back to the first one
+var retestIntervals = []time.Duration{
+	0,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+type RetestItem struct {
+	Box  int       `json:"box"`
+	Due  time.Time `json:"due"`
+	Seen time.Time `json:"seen"`
+}
+
+// state of spaced retesting, items are identified by arbitrary keys
+type Retest map[string]RetestItem
+
+func getRetestFile(database string) string {
+	return database + ".retest"
+}
+
+func loadRetest(file string) (Retest, error) {
+	retest := Retest{}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return retest, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &retest)
+	if err != nil {
+		return nil, err
+	}
+
+	return retest, nil
+}
+
+func saveRetest(file string, retest Retest) error {
+	content, err := json.MarshalIndent(retest, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0600)
+}
+
+// pickDue returns up to count keys to test: overdue items first, then new
+// items, then items which are due soonest.
+func (retest Retest) pickDue(keys []string, count int, now time.Time) []string {
+	keys = append([]string{}, keys...)
+
+	// shuffle first, so items with equal priority come in random order
+	shuffle(len(keys), func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return retest.getPriority(keys[i], now).Before(
+			retest.getPriority(keys[j], now),
+		)
+	})
+
+	if count > len(keys) {
+		count = len(keys)
+	}
+
+	return keys[:count]
+}
+
+func (retest Retest) getPriority(key string, now time.Time) time.Time {
+	item, ok := retest[key]
+	if !ok {
+		// new items go right after overdue ones
+		return now
+	}
+
+	return item.Due
+}
+
+func (retest Retest) update(key string, correct bool, now time.Time) {
+	item := retest[key]
+
+	if correct {
+		item.Box++
+		if item.Box >= len(retestIntervals) {
+			item.Box = len(retestIntervals) - 1
+		}
+	} else {
+		item.Box = 0
+	}
+
+	item.Seen = now
+	item.Due = now.Add(retestIntervals[item.Box])
+
+	retest[key] = item
+}
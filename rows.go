@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// length of row in rows mode when test doesn't specify it
+const defaultRowLength = 40
+
+// generateRows returns random decimal or binary digits split into rows.
+func generateRows(count, length int, binary bool) []string {
+	base := 10
+	if binary {
+		base = 2
+	}
+
+	rows := []string{}
+	row := ""
+	for i := 0; i < count; i++ {
+		row += fmt.Sprint(randomInt(base))
+		if len(row) == length || i == count-1 {
+			rows = append(rows, row)
+			row = ""
+		}
+	}
+
+	return rows
+}
+
+func runRowsTest(options Options, test Test) (Result, error) {
+	length := test.RowLength
+	if length == 0 {
+		length = defaultRowLength
+	}
+
+	rows := generateRows(test.Count, length, test.Binary)
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	err := showRows(rows, test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	input, err := recallRows(rows, test.Binary)
+	if err != nil {
+		return Result{}, err
+	}
+
+	scoring := options.getScoring(modeRows)
+	score := scoreRowsBy(scoring, rows, input)
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			getRowsFeedback(rows, input), score, test.Count,
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    test.Count,
+		Note:     note,
+		Mode:     modeRows,
+		Scoring:  scoring,
+		Items:    rows,
+		Input:    input,
+	}, nil
+}
+
+// formatRows numbers rows and splits digits into groups of five.
+func formatRows(rows []string) []string {
+	lines := []string{}
+	for index, row := range rows {
+		groups := []string{}
+		for start := 0; start < len(row); start += 5 {
+			end := start + 5
+			if end > len(row) {
+				end = len(row)
+			}
+
+			groups = append(groups, row[start:end])
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"%3d  %s", index+1, strings.Join(groups, " "),
+		))
+	}
+
+	return lines
+}
+
+// showRows shows rows until Enter is pressed or exposure is over, rows which
+// don't fit the screen are scrolled with arrows.
+func showRows(rows []string, exposure time.Duration) error {
+	lines := formatRows(rows)
+
+	if exposure > 0 {
+		timer := time.AfterFunc(exposure, screen.Interrupt)
+		defer timer.Stop()
+	}
+
+	deadline := time.Now().Add(exposure)
+
+	offset := 0
+	for {
+		_, height := screen.Size()
+
+		visible := height - 2
+		if visible < 1 {
+			visible = 1
+		}
+
+		if offset > len(lines)-visible {
+			offset = len(lines) - visible
+		}
+
+		if offset < 0 {
+			offset = 0
+		}
+
+		end := offset + visible
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		clearScreen()
+		printLines(lines[offset:end])
+		if len(lines) > visible {
+			printCentered("Up/Down: scroll, Enter: recall", height-1)
+		}
+
+		screen.HideCursor()
+		screen.Flush()
+
+		event := pollEvent()
+
+		switch event.Type {
+		case ui.EventInterrupt:
+			if exposure > 0 && !time.Now().Before(deadline) {
+				return nil
+			}
+
+		case ui.EventKey:
+			if direction, ok := keys.getDirection(event); ok {
+				offset += direction.y
+				continue
+			}
+
+			switch event.Key {
+			case ui.KeyEnter:
+				return nil
+			case ui.KeyCtrlC, ui.KeyCtrlZ:
+				return errAborted
+			case ui.KeyEsc:
+				return errStopped
+			}
+		}
+	}
+}
+
+// recallRows reads rows one by one, empty row finishes recall.
+func recallRows(rows []string, binary bool) ([]string, error) {
+	_, height := screen.Size()
+
+	input := []string{}
+	for index := range rows {
+		text, err := readLine(
+			fmt.Sprintf(
+				"row %d of %d, empty to finish:", index+1, len(rows),
+			),
+			height/2-1,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		row := strings.Map(func(symbol rune) rune {
+			if !isDigit(symbol) || binary && symbol > '1' {
+				return -1
+			}
+
+			return symbol
+		}, text)
+		if row == "" {
+			break
+		}
+
+		input = append(input, row)
+	}
+
+	for len(input) < len(rows) {
+		input = append(input, "")
+	}
+
+	return input, nil
+}
+
+// getRowsFeedback returns correct and entered digits of recalled rows.
+func getRowsFeedback(rows, input []string) []styledLine {
+	lines := []styledLine{}
+	for index, row := range rows {
+		if input[index] == "" {
+			break
+		}
+
+		valid := strings.Split(row, "")
+		entered := strings.Split(input[index], "")
+
+		lines = append(lines,
+			styledLine{{fmt.Sprintf("%3d  %s", index+1, row), theme.text}},
+			getDiffLine("     ", entered, "", isSameAt(valid, entered)),
+		)
+	}
+
+	return lines
+}
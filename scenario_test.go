@@ -0,0 +1,472 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docopt/docopt-go"
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// fakeTerminal is in-memory terminal, events are produced by steps of
+// scenario which see the current contents of the screen.
+type fakeTerminal struct {
+	width  int
+	height int
+	cells  []ui.Cell
+	shown  []ui.Cell
+	init   bool
+	queue  []ui.Event
+	steps  []step
+
+	// error of the scenario, session is aborted when it happens
+	err error
+}
+
+// step returns events which are sent to the application when it waits for
+// input, screen contains flushed lines of the terminal.
+type step func(screen []string) ([]ui.Event, error)
+
+func newFakeTerminal(width, height int, steps []step) *fakeTerminal {
+	return &fakeTerminal{
+		width:  width,
+		height: height,
+		cells:  make([]ui.Cell, width*height),
+		shown:  make([]ui.Cell, width*height),
+		steps:  steps,
+	}
+}
+
+func (terminal *fakeTerminal) Init() error {
+	terminal.init = true
+	return nil
+}
+
+func (terminal *fakeTerminal) Close() {
+	terminal.init = false
+}
+
+func (terminal *fakeTerminal) IsInit() bool {
+	return terminal.init
+}
+
+func (terminal *fakeTerminal) Size() (int, int) {
+	return terminal.width, terminal.height
+}
+
+func (terminal *fakeTerminal) EnableFocus() {}
+
+func (terminal *fakeTerminal) SetCell(
+	x, y int, symbol rune, fg, bg ui.Attribute,
+) {
+	if x < 0 || y < 0 || x >= terminal.width || y >= terminal.height {
+		return
+	}
+
+	terminal.cells[y*terminal.width+x] = ui.Cell{
+		Ch: symbol, Fg: fg, Bg: bg,
+	}
+}
+
+func (terminal *fakeTerminal) SetCursor(x, y int) {}
+
+func (terminal *fakeTerminal) HideCursor() {}
+
+func (terminal *fakeTerminal) CellBuffer() []ui.Cell {
+	return terminal.cells
+}
+
+func (terminal *fakeTerminal) Clear(fg, bg ui.Attribute) error {
+	for i := range terminal.cells {
+		terminal.cells[i] = ui.Cell{Ch: ' ', Fg: fg, Bg: bg}
+	}
+
+	return nil
+}
+
+func (terminal *fakeTerminal) Flush() error {
+	copy(terminal.shown, terminal.cells)
+	return nil
+}
+
+// PollEvent runs the next step of scenario when queued events are over,
+// the session is aborted when steps are over or step failed.
+func (terminal *fakeTerminal) PollEvent() ui.Event {
+	for len(terminal.queue) == 0 {
+		if len(terminal.steps) == 0 {
+			terminal.err = fmt.Errorf("application waits for unexpected input")
+			return pressKey(ui.KeyCtrlC)[0]
+		}
+
+		step := terminal.steps[0]
+		terminal.steps = terminal.steps[1:]
+
+		events, err := step(terminal.getLines())
+		if err != nil {
+			terminal.err = err
+			return pressKey(ui.KeyCtrlC)[0]
+		}
+
+		terminal.queue = events
+	}
+
+	event := terminal.queue[0]
+	terminal.queue = terminal.queue[1:]
+
+	return event
+}
+
+func (terminal *fakeTerminal) Interrupt() {
+	terminal.queue = append(
+		terminal.queue, ui.Event{Type: ui.EventInterrupt},
+	)
+}
+
+func (terminal *fakeTerminal) getLines() []string {
+	lines := []string{}
+	for y := 0; y < terminal.height; y++ {
+		row := terminal.shown[y*terminal.width : (y+1)*terminal.width]
+
+		line := []rune{}
+		for _, cell := range row {
+			if cell.Ch == 0 {
+				cell.Ch = ' '
+			}
+
+			line = append(line, cell.Ch)
+		}
+
+		lines = append(lines, strings.TrimSpace(string(line)))
+	}
+
+	return lines
+}
+
+// fake clock ticks by one second on every reading
+func newFakeClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func typeText(text string) []ui.Event {
+	events := []ui.Event{}
+	for _, symbol := range text {
+		event := ui.Event{Type: ui.EventKey, Ch: symbol}
+		if symbol == ' ' {
+			event = ui.Event{Type: ui.EventKey, Key: ui.KeySpace}
+		}
+
+		events = append(events, event)
+	}
+
+	return append(events, pressKey(ui.KeyEnter)...)
+}
+
+func pressKey(key ui.Key) []ui.Event {
+	return []ui.Event{{Type: ui.EventKey, Key: key}}
+}
+
+// getShown returns the only non-empty line of the screen.
+func getShown(screen []string) (string, error) {
+	shown := []string{}
+	for _, line := range screen {
+		if line != "" {
+			shown = append(shown, line)
+		}
+	}
+
+	if len(shown) != 1 {
+		return "", fmt.Errorf("expected single line on screen, got %q", shown)
+	}
+
+	return shown[0], nil
+}
+
+// scenario runs session with given arguments and checks the database after
+type scenario struct {
+	name  string
+	args  []string
+	steps []step
+	check func(database Database, events []Event) error
+}
+
+// recallSteps remembers numbers shown on the screen and enters them back,
+// changed by alter function
+func recallSteps(tests int, alter func(string) string) []step {
+	steps := []step{}
+	for i := 0; i < tests; i++ {
+		var shown string
+		steps = append(steps,
+			func(screen []string) ([]ui.Event, error) {
+				var err error
+				shown, err = getShown(screen)
+				return pressKey(ui.KeyEnter), err
+			},
+			func(screen []string) ([]ui.Event, error) {
+				return typeText(alter(shown)), nil
+			},
+		)
+	}
+
+	return steps
+}
+
+// quitResults checks that results screen lists all tests and closes it.
+func quitResults(tests int) step {
+	return func(screen []string) ([]ui.Event, error) {
+		listed := 0
+		for _, line := range screen {
+			if strings.Contains(line, " sec") && strings.Contains(line, ". ") {
+				listed++
+			}
+		}
+
+		if listed != tests {
+			return nil, fmt.Errorf(
+				"expected %d tests on results screen, got %q", tests, screen,
+			)
+		}
+
+		return typeText("q")[:1], nil
+	}
+}
+
+func getScenarios() []scenario {
+	return []scenario{
+		{
+			name: "perfect recall",
+			args: []string{"-n", "2", "-c", "5"},
+			steps: append(
+				recallSteps(2, func(shown string) string { return shown }),
+				quitResults(2),
+			),
+			check: func(database Database, events []Event) error {
+				return checkSession(database, 2, 10, 5)
+			},
+		},
+		{
+			name: "wrong recall",
+			args: []string{"-n", "1", "-c", "3"},
+			steps: append(
+				recallSteps(1, func(shown string) string {
+					return "1 2 3"
+				}),
+				quitResults(1),
+			),
+			check: func(database Database, events []Event) error {
+				return checkSession(database, 1, 0, 3)
+			},
+		},
+		{
+			name: "abort",
+			args: []string{"-n", "3"},
+			steps: []step{
+				func(screen []string) ([]ui.Event, error) {
+					return pressKey(ui.KeyCtrlC), nil
+				},
+			},
+			check: func(database Database, events []Event) error {
+				if len(database.Sessions) != 0 {
+					return fmt.Errorf("aborted session is saved")
+				}
+
+				last := events[len(events)-1]
+				if last.Type != eventSessionAborted {
+					return fmt.Errorf("last event is %s", last.Type)
+				}
+
+				return nil
+			},
+		},
+		{
+			name: "interrupt keeps completed tests",
+			args: []string{"-n", "3", "-c", "3"},
+			steps: append(
+				recallSteps(1, func(shown string) string { return shown }),
+				func(screen []string) ([]ui.Event, error) {
+					return pressKey(ui.KeyCtrlC), nil
+				},
+			),
+			check: func(database Database, events []Event) error {
+				return checkStopped(database, 1)
+			},
+		},
+		{
+			name: "stop skips test in progress",
+			args: []string{"-n", "3", "-c", "3"},
+			steps: append(
+				recallSteps(1, func(shown string) string { return shown }),
+				func(screen []string) ([]ui.Event, error) {
+					return pressKey(ui.KeyEsc), nil
+				},
+				quitResults(1),
+			),
+			check: func(database Database, events []Event) error {
+				return checkStopped(database, 1)
+			},
+		},
+		{
+			name: "esc cancels note",
+			args: []string{"-n", "1", "-c", "3", "--feedback"},
+			steps: append(
+				recallSteps(1, func(shown string) string { return shown }),
+				func(screen []string) ([]ui.Event, error) {
+					return typeText("n")[:1], nil
+				},
+				func(screen []string) ([]ui.Event, error) {
+					return append(
+						typeText("lost")[:4], pressKey(ui.KeyEsc)...,
+					), nil
+				},
+				func(screen []string) ([]ui.Event, error) {
+					return pressKey(ui.KeyEnter), nil
+				},
+				quitResults(1),
+			),
+			check: func(database Database, events []Event) error {
+				err := checkSession(database, 1, 3, 3)
+				if err != nil {
+					return err
+				}
+
+				session := database.Sessions[0]
+				if session.Aborted || session.Results[0].Note != "" {
+					return fmt.Errorf("canceled note is saved: %+v", session)
+				}
+
+				return nil
+			},
+		},
+	}
+}
+
+// checkStopped checks that session stopped by Esc or Ctrl+C is saved with
+// completed tests only.
+func checkStopped(database Database, tests int) error {
+	if len(database.Sessions) != 1 {
+		return fmt.Errorf("expected 1 session, got %d", len(database.Sessions))
+	}
+
+	session := database.Sessions[0]
+	if !session.Aborted {
+		return fmt.Errorf("stopped session is not marked as aborted")
+	}
+
+	if len(session.Results) != tests {
+		return fmt.Errorf(
+			"expected %d results, got %d", tests, len(session.Results),
+		)
+	}
+
+	return nil
+}
+
+func checkSession(database Database, tests, score, count int) error {
+	if len(database.Sessions) != 1 {
+		return fmt.Errorf("expected 1 session, got %d", len(database.Sessions))
+	}
+
+	session := database.Sessions[0]
+	if len(session.Results) != tests {
+		return fmt.Errorf(
+			"expected %d results, got %d", tests, len(session.Results),
+		)
+	}
+
+	if session.TotalScore != score {
+		return fmt.Errorf(
+			"expected score %d, got %d", score, session.TotalScore,
+		)
+	}
+
+	for _, result := range session.Results {
+		if result.Count != count {
+			return fmt.Errorf("expected count %d, got %d", count, result.Count)
+		}
+
+		// clock is read once when numbers are shown and once when hidden
+		if result.Duration != 1 {
+			return fmt.Errorf("expected duration 1s, got %v", result.Duration)
+		}
+	}
+
+	return nil
+}
+
+// TestScenarios drives full sessions through fake terminal and fake clock,
+// each scenario uses its own temporary database.
+func TestScenarios(t *testing.T) {
+	dir := t.TempDir()
+
+	defer func(real ui.Terminal, realClock func() time.Time) {
+		screen = real
+		clock = realClock
+	}(screen, clock)
+
+	// scenarios start with new databases, which would show the tutorial and
+	// rules of modes
+	config := filepath.Join(dir, "config.toml")
+	markTutorialShown(config)
+	for _, mode := range modes {
+		hideRules(config, mode)
+	}
+
+	for index, scenario := range getScenarios() {
+		file := filepath.Join(dir, fmt.Sprintf("%d.db", index))
+
+		t.Run(scenario.name, func(t *testing.T) {
+			err := runScenario(scenario, file, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func runScenario(scenario scenario, file string, config string) error {
+	args, err := docopt.Parse(
+		usage, append(scenario.args, "-f", file, "--config", config),
+		false, "", false, false,
+	)
+	if err != nil {
+		return err
+	}
+
+	terminal := newFakeTerminal(80, 24, scenario.steps)
+
+	screen = terminal
+	clock = newFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	err = runSession(file, args)
+	if err != nil {
+		return err
+	}
+
+	if terminal.err != nil {
+		return terminal.err
+	}
+
+	if len(terminal.steps) > 0 {
+		return fmt.Errorf(
+			"session is over before %d steps", len(terminal.steps),
+		)
+	}
+
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	events, err := readEvents(getEventsFile(file))
+	if err != nil {
+		return err
+	}
+
+	return scenario.check(database, events)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// all tests of the same count of numbers go one after another
+	scheduleBlocked = "blocked"
+
+	// counts of numbers are shuffled across the session
+	scheduleInterleaved = "interleaved"
+)
+
+// parameters of a single test
+type Test struct {
+	Mode     string
+	Count    int
+	Exposure time.Duration
+
+	// layout of digits in rows mode
+	RowLength int
+	Binary    bool
+
+	// interval between digits in spoken mode
+	Rate time.Duration
+}
+
+// getTests returns tests of the session, either from specified script or
+// from command line options.
+func getTests(options Options, config Config) ([]Test, error) {
+	tests := []Test{}
+
+	if options.Preset != nil {
+		return []Test{options.Preset.getTest()}, nil
+	}
+
+	if options.Script == "" {
+		counts := getSchedule(options.Lengths, options.Tests, options.Schedule)
+		for _, count := range counts {
+			tests = append(tests, Test{
+				Mode:     options.Mode,
+				Count:    count,
+				Exposure: options.Exposure,
+			})
+		}
+
+		return tests, nil
+	}
+
+	script, ok := config.Scripts[options.Script]
+	if !ok {
+		return nil, fmt.Errorf(
+			"script %q is not defined in %s", options.Script, options.Config,
+		)
+	}
+
+	for _, block := range script.Blocks {
+		test := Test{
+			Mode:     block.Mode,
+			Count:    block.Count,
+			Exposure: time.Duration(block.Exposure * float64(time.Second)),
+		}
+
+		if test.Mode == "" {
+			test.Mode = modeDigits
+		}
+
+		for i := 0; i < block.Trials; i++ {
+			tests = append(tests, test)
+		}
+	}
+
+	return tests, nil
+}
+
+// getSchedule returns count of numbers for every test of the session, every
+// count gets equal share of tests.
+func getSchedule(lengths []int, tests int, schedule string) []int {
+	counts := []int{}
+	for index, length := range lengths {
+		share := tests / len(lengths)
+		if index < tests%len(lengths) {
+			share++
+		}
+
+		for i := 0; i < share; i++ {
+			counts = append(counts, length)
+		}
+	}
+
+	if schedule == scheduleInterleaved {
+		shuffle(len(counts), func(i, j int) {
+			counts[i], counts[j] = counts[j], counts[i]
+		})
+	}
+
+	return counts
+}
+
+func parseLengths(value string) ([]int, error) {
+	lengths := []int{}
+	for _, piece := range strings.Split(value, ",") {
+		length, err := strconv.Atoi(strings.TrimSpace(piece))
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf(
+				"--lengths: %q is not a valid count of numbers", piece,
+			)
+		}
+
+		lengths = append(lengths, length)
+	}
+
+	return lengths, nil
+}
+
+// isUniform returns true if all tests have the same mode and count.
+func isUniform(tests []Test) bool {
+	for _, test := range tests {
+		if test.Mode != tests[0].Mode || test.Count != tests[0].Count {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,237 @@
+package main
+
+const (
+	// row without mistakes gets full points, row with one mistake gets
+	// half, rows with more mistakes get nothing
+	scoringRows = "rows"
+
+	// every digit recalled at its position gets a point
+	scoringDigits = "digits"
+)
+
+// strategies of scoring items of digits, reverse and chess modes
+const (
+	// items are counted until the first mistake
+	scoringStrict = "strict"
+
+	// every item recalled at its position gets a point
+	scoringPositional = "positional"
+
+	// every recalled item gets a point regardless of its position
+	scoringSet = "set"
+
+	// count of items without edit distance between correct and entered
+	// items, so missed or extra item costs one point instead of all next
+	scoringLevenshtein = "levenshtein"
+)
+
+var (
+	rowsScorings     = []string{scoringRows, scoringDigits}
+	sequenceScorings = []string{
+		scoringStrict, scoringPositional, scoringSet, scoringLevenshtein,
+	}
+
+	scorings = append(append([]string{}, rowsScorings...), sequenceScorings...)
+)
+
+func isKnownScoring(scoring string) bool {
+	for _, known := range scorings {
+		if scoring == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getScoring returns strategy of scoring tests of specified mode, strategy
+// of another kind of modes means the default one.
+func (options Options) getScoring(mode string) string {
+	if mode == modeRows {
+		if indexOf(rowsScorings, options.Scoring) < 0 {
+			return scoringRows
+		}
+
+		return options.Scoring
+	}
+
+	if indexOf(sequenceScorings, options.Scoring) < 0 {
+		return scoringStrict
+	}
+
+	return options.Scoring
+}
+
+// scoreSequence scores recalled items by specified strategy.
+func scoreSequence(scoring string, valid, input []string) int {
+	switch scoring {
+	case scoringPositional:
+		return scorePositions(valid, input)
+	case scoringSet:
+		return scoreSet(valid, input)
+	case scoringLevenshtein:
+		return scoreLevenshtein(valid, input)
+	}
+
+	return compareTokens(valid, input)
+}
+
+func scorePositions(valid, input []string) int {
+	score := 0
+	for index, item := range valid {
+		if index < len(input) && input[index] == item {
+			score++
+		}
+	}
+
+	return score
+}
+
+// scoreSet counts recalled items, every shown item is counted as many times
+// as it was shown.
+func scoreSet(valid, input []string) int {
+	shown := map[string]int{}
+	for _, item := range valid {
+		shown[item]++
+	}
+
+	score := 0
+	for _, item := range input {
+		if shown[item] > 0 {
+			shown[item]--
+			score++
+		}
+	}
+
+	return score
+}
+
+func scoreLevenshtein(valid, input []string) int {
+	score := len(valid) - getEditDistance(valid, input)
+	if score < 0 {
+		return 0
+	}
+
+	return score
+}
+
+// getEditDistance returns count of items to insert, delete or replace to
+// get input from valid items.
+func getEditDistance(valid, input []string) int {
+	previous := make([]int, len(input)+1)
+	for index := range previous {
+		previous[index] = index
+	}
+
+	for row := 1; row <= len(valid); row++ {
+		current := make([]int, len(input)+1)
+		current[0] = row
+
+		for column := 1; column <= len(input); column++ {
+			cost := 1
+			if valid[row-1] == input[column-1] {
+				cost = 0
+			}
+
+			distance := previous[column-1] + cost
+			if previous[column]+1 < distance {
+				distance = previous[column] + 1
+			}
+			if current[column-1]+1 < distance {
+				distance = current[column-1] + 1
+			}
+
+			current[column] = distance
+		}
+
+		previous = current
+	}
+
+	return previous[len(input)]
+}
+
+// scoreRowsBy scores recalled rows by specified strategy.
+func scoreRowsBy(scoring string, valid, input []string) int {
+	if scoring == scoringDigits {
+		return scoreDigits(valid, input)
+	}
+
+	return scoreRows(valid, input)
+}
+
+// scoreRows scores recalled rows by memory sport rules: complete row
+// without mistakes gets full points, row with one mistake gets half, rows
+// with more mistakes get nothing. The last recalled row may be incomplete,
+// then it's scored by count of recalled digits.
+func scoreRows(valid, input []string) int {
+	last := -1
+	for index, row := range input {
+		if row != "" {
+			last = index
+		}
+	}
+
+	score := 0
+	for index, row := range valid {
+		if index > last {
+			break
+		}
+
+		recalled := input[index]
+
+		points := len(row)
+		if index == last && len(recalled) < len(row) {
+			points = len(recalled)
+			row = row[:len(recalled)]
+		}
+
+		// missing and extra digits are mistakes too
+		mistakes := len(recalled) - len(row)
+		if mistakes < 0 {
+			mistakes = -mistakes
+		}
+
+		for position := range row {
+			if position < len(recalled) && recalled[position] != row[position] {
+				mistakes++
+			}
+		}
+
+		switch mistakes {
+		case 0:
+			score += points
+		case 1:
+			score += (points + 1) / 2
+		}
+	}
+
+	return score
+}
+
+func scoreDigits(valid, input []string) int {
+	score := 0
+	for index, row := range valid {
+		for position := range row {
+			if position < len(input[index]) &&
+				input[index][position] == row[position] {
+				score++
+			}
+		}
+	}
+
+	return score
+}
+
+// getScoring returns scoring strategy of the result, results recorded
+// before strategies were added are scored by rows or strictly.
+func (result Result) getScoring() string {
+	if result.Scoring == "" && result.getMode() == modeRows {
+		return scoringRows
+	}
+
+	if result.Scoring == "" {
+		return scoringStrict
+	}
+
+	return result.Scoring
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreSequence(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid string
+		input string
+
+		// scores by strict, positional, set and levenshtein strategies
+		scores [4]int
+	}{
+		{"exact", "1 2 3 4", "1 2 3 4", [4]int{4, 4, 4, 4}},
+		{"insertion", "1 2 3 4", "1 9 2 3 4", [4]int{1, 1, 4, 3}},
+		{"deletion", "1 2 3 4", "1 3 4", [4]int{1, 1, 3, 3}},
+		{"transposition", "1 2 3 4", "1 3 2 4", [4]int{1, 2, 4, 2}},
+		{"substitution", "1 2 3 4", "1 2 7 4", [4]int{2, 3, 3, 3}},
+		{"empty input", "1 2 3 4", "", [4]int{0, 0, 0, 0}},
+		{"empty test", "", "", [4]int{0, 0, 0, 0}},
+		{"extra items", "1 2", "1 2 3 4 5", [4]int{2, 2, 2, 0}},
+		{"repeated items", "5 5 5", "5", [4]int{1, 1, 1, 1}},
+	}
+
+	strategies := []string{
+		scoringStrict, scoringPositional, scoringSet, scoringLevenshtein,
+	}
+
+	for _, test := range tests {
+		valid := strings.Fields(test.valid)
+		input := strings.Fields(test.input)
+
+		for index, scoring := range strategies {
+			score := scoreSequence(scoring, valid, input)
+			if score != test.scores[index] {
+				t.Errorf(
+					"%s: %s scoring of %q for %q: expected %d, got %d",
+					test.name, scoring, test.input, test.valid,
+					test.scores[index], score,
+				)
+			}
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		valid    string
+		input    string
+		distance int
+	}{
+		{"", "", 0},
+		{"1 2 3", "", 3},
+		{"", "1 2", 2},
+		{"1 2 3", "1 2 3", 0},
+		{"1 2 3", "2 3", 1},
+		{"1 2 3", "1 2 3 4", 1},
+		{"1 2 3", "2 1 3", 2},
+		{"1 2 3", "4 5 6", 3},
+	}
+
+	for _, test := range tests {
+		distance := getEditDistance(
+			strings.Fields(test.valid), strings.Fields(test.input),
+		)
+		if distance != test.distance {
+			t.Errorf(
+				"distance from %q to %q: expected %d, got %d",
+				test.valid, test.input, test.distance, distance,
+			)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// sentences used when no corpus file is given
+var builtinCorpus = []string{
+	"The old man walked slowly to the market",
+	"She left her keys on the kitchen table",
+	"A small dog barked at the passing train",
+	"We will meet again after the summer rain",
+	"The children built a castle out of sand",
+	"He forgot to water the plants last week",
+	"Bright stars filled the sky above the quiet lake",
+	"The letter arrived three days later than expected",
+	"My neighbor plays the piano every Sunday morning",
+	"They painted the fence green before the party",
+	"The doctor asked him to come back tomorrow",
+	"A cold wind blew through the empty streets",
+	"Please close the window before you leave the room",
+	"Our train was delayed by a fallen tree",
+	"The cat slept all afternoon in the sun",
+	"She bought fresh bread from the corner bakery",
+}
+
+func loadCorpus(file string) ([]string, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	sentences := []string{}
+
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		sentence := trimLine(scanner.Text())
+		if sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	return sentences, scanner.Err()
+}
+
+// pickSentence returns words of random sentence which word count is the
+// closest to specified count.
+func pickSentence(corpus []string, count int) []string {
+	candidates := [][]string{}
+	distance := -1
+	for _, sentence := range corpus {
+		words := strings.Fields(sentence)
+
+		wordsDistance := len(words) - count
+		if wordsDistance < 0 {
+			wordsDistance = -wordsDistance
+		}
+
+		switch {
+		case distance == -1 || wordsDistance < distance:
+			distance = wordsDistance
+			candidates = [][]string{words}
+		case wordsDistance == distance:
+			candidates = append(candidates, words)
+		}
+	}
+
+	return candidates[randomInt(len(candidates))]
+}
+
+func runSentenceTest(options Options, test Test) (Result, error) {
+	corpus := options.Corpus
+	if len(corpus) == 0 {
+		corpus = builtinCorpus
+	}
+
+	words := pickSentence(corpus, test.Count)
+	if options.Scramble {
+		shuffle(len(words), func(i, j int) {
+			words[i], words[j] = words[j], words[i]
+		})
+	}
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(strings.Join(words, " "), height/2)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	text, err := readLine("", height/2-1)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input := strings.Fields(text)
+	score := compareWords(words, input)
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, modeSentence, words, " "),
+				getDiffLine("entered: ", input, " ", func(index int) bool {
+					return index < len(words) && normalizeWord(words[index]) ==
+						normalizeWord(input[index])
+				}),
+			},
+			score, len(words),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    len(words),
+		Note:     note,
+		Mode:     modeSentence,
+		Items:    words,
+		Input:    input,
+	}, nil
+}
+
+// compareWords counts words recalled at their positions, case and
+// punctuation are ignored.
+func compareWords(validWords, inputWords []string) int {
+	score := 0
+	for index, word := range validWords {
+		if index >= len(inputWords) {
+			break
+		}
+
+		if normalizeWord(word) == normalizeWord(inputWords[index]) {
+			score++
+		}
+	}
+
+	return score
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+}
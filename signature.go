@@ -0,0 +1,269 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minimal time of studying one item, faster results are not humanly
+// possible and mean that items were taken from elsewhere
+const minItemStudyTime = 0.1
+
+// extra time of timed tests for delays of the terminal
+const exposureSlack = 0.5
+
+// signedResult is result of challenge which can be shared and verified by
+// anyone who has the challenge code
+type signedResult struct {
+	Code  string       `json:"code"`
+	Date  time.Time    `json:"date"`
+	Tests []signedTest `json:"tests"`
+
+	// public key of the player, signature is made by its private key
+	Key       []byte `json:"key"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+type signedTest struct {
+	Items    []string `json:"items"`
+	Input    []string `json:"input"`
+	Score    int      `json:"score"`
+	Duration float64  `json:"duration"`
+}
+
+// getChallengeKeyFile returns file next to the config with private key
+// challenge results are signed by.
+func getChallengeKeyFile(config string) string {
+	return filepath.Join(filepath.Dir(config), ".challenge_key")
+}
+
+// loadChallengeKey reads private key of the player, the key is generated on
+// the first use.
+func loadChallengeKey(config string) (ed25519.PrivateKey, error) {
+	file := getChallengeKeyFile(config)
+
+	content, err := ioutil.ReadFile(file)
+	if err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(content)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("%s: malformed challenge key", file)
+		}
+
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err == nil {
+		err = writeFile(file, []byte(hex.EncodeToString(key.Seed())+"\n"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// getSignedResultFile returns file next to the database with the latest
+// signed result of the challenge.
+func getSignedResultFile(database string, code string) string {
+	return database + ".challenge-" + code + ".json"
+}
+
+// writeSignedResult signs results of challenge session and writes them
+// into the file, which can be shared with other players.
+func writeSignedResult(
+	database string, config string, session Session,
+) (string, error) {
+	key, err := loadChallengeKey(config)
+	if err != nil {
+		return "", err
+	}
+
+	result := signedResult{
+		Code: session.Challenge,
+		Date: session.Date,
+		Key:  key.Public().(ed25519.PublicKey),
+	}
+
+	for _, test := range session.Results {
+		result.Tests = append(result.Tests, signedTest{
+			Items:    test.Items,
+			Input:    test.Input,
+			Score:    test.Score,
+			Duration: test.Duration,
+		})
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	result.Signature = ed25519.Sign(key, payload)
+
+	content, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		return "", err
+	}
+
+	file := getSignedResultFile(database, session.Challenge)
+
+	return file, writeFile(file, append(content, '\n'))
+}
+
+// verifyResult checks signature of shared result, that its items are the
+// stimuli of the challenge, its scores and timing.
+func verifyResult(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var result signedResult
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return fmt.Errorf("can't decode signed result %s: %s", file, err)
+	}
+
+	signature := result.Signature
+	result.Signature = nil
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Key) != ed25519.PublicKeySize ||
+		!ed25519.Verify(result.Key, payload, signature) {
+		return fmt.Errorf("signature doesn't match, result was changed")
+	}
+
+	challenge, err := decodeChallenge(result.Code)
+	if err != nil {
+		return err
+	}
+
+	stimuli, err := challenge.getStimuli()
+	if err != nil {
+		return err
+	}
+
+	if len(result.Tests) != len(stimuli) {
+		return fmt.Errorf(
+			"challenge has %d tests, result has %d",
+			len(stimuli), len(result.Tests),
+		)
+	}
+
+	score, total := 0, 0
+	for index, test := range result.Tests {
+		err = challenge.verifyTest(test, stimuli[index])
+		if err != nil {
+			return fmt.Errorf("test #%d: %s", index+1, err)
+		}
+
+		score += test.Score
+		total += len(stimuli[index])
+	}
+
+	fingerprint := sha256.Sum256(result.Key)
+
+	fmt.Printf("challenge: %s\n", result.Code)
+	fmt.Printf("player:    %x\n", fingerprint[:8])
+	fmt.Printf("date:      %s\n", result.Date.Format("2006-01-02 15:04"))
+	fmt.Printf("score:     %d/%d\n", score, total)
+	fmt.Println("signature, stimuli, scores and timing are valid")
+
+	return nil
+}
+
+// getStimuli regenerates items of every test of the challenge, only modes
+// whose items depend on nothing but random numbers can be verified.
+func (challenge Challenge) getStimuli() ([][]string, error) {
+	charset, err := parseCharset(challenge.Charset)
+	if err != nil {
+		return nil, err
+	}
+
+	seedRandom(challenge.Seed)
+	defer func() {
+		seeded = nil
+	}()
+
+	stimuli := [][]string{}
+	for i := 0; i < challenge.Tests; i++ {
+		switch challenge.Mode {
+		case modeDigits, modeReverse:
+			stimuli = append(stimuli, charset.generateTokens(
+				seeded, challenge.Min, challenge.Max, challenge.Count,
+			))
+		case modeChess:
+			stimuli = append(stimuli, generateSquares(seeded, challenge.Count))
+		case modeWords:
+			stimuli = append(stimuli, pickWords(builtinWords, challenge.Count))
+		default:
+			return nil, fmt.Errorf(
+				"results of %s mode can't be verified", challenge.Mode,
+			)
+		}
+	}
+
+	return stimuli, nil
+}
+
+func (challenge Challenge) verifyTest(test signedTest, items []string) error {
+	if strings.Join(test.Items, " ") != strings.Join(items, " ") {
+		return fmt.Errorf("items are not the stimuli of the challenge")
+	}
+
+	var score int
+	switch challenge.Mode {
+	case modeReverse:
+		score = compareTokens(reverseTokens(items), test.Input)
+	case modeWords:
+		score = compareWords(items, test.Input)
+	default:
+		score = compareTokens(items, test.Input)
+	}
+
+	if score != test.Score {
+		return fmt.Errorf(
+			"score is %d, but entered items score %d", test.Score, score,
+		)
+	}
+
+	if test.Duration < minItemStudyTime*float64(len(items)) {
+		return fmt.Errorf(
+			"%.2f seconds is too short to study %d items",
+			test.Duration, len(items),
+		)
+	}
+
+	exposure := challenge.Exposure.Seconds()
+	if exposure > 0 && test.Duration > exposure+exposureSlack {
+		return fmt.Errorf(
+			"items were studied for %.2f seconds, but shown for %.2f",
+			test.Duration, exposure,
+		)
+	}
+
+	return nil
+}
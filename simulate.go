@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator of simulated tests, items are recalled with mistakes and
+// scored by every strategy suitable for them
+type simulatedGenerator struct {
+	name     string
+	generate func(count int) []string
+	scorings []simulatedScoring
+}
+
+type simulatedScoring struct {
+	name  string
+	score func(valid, input []string) int
+}
+
+var (
+	// items recalled in order until the first mistake, like in digits,
+	// reverse, chess and spoken modes
+	simulatedStrict = simulatedScoring{scoringStrict, compareTokens}
+
+	// every item recalled at its position, like in words, sentence and
+	// dates modes, and digits scoring of rows mode
+	simulatedPositional = simulatedScoring{scoringPositional, scorePositions}
+
+	simulatedSet         = simulatedScoring{scoringSet, scoreSet}
+	simulatedLevenshtein = simulatedScoring{scoringLevenshtein, scoreLevenshtein}
+
+	// rows of memory sport rules, like in rows mode
+	simulatedRows = simulatedScoring{
+		scoringRows, func(valid, input []string) int {
+			return scoreRows(
+				splitRows(valid, defaultRowLength),
+				splitRows(input, defaultRowLength),
+			)
+		},
+	}
+
+	// strategies of --scoring for digits, reverse and chess modes
+	simulatedSequence = []simulatedScoring{
+		simulatedStrict, simulatedPositional, simulatedSet,
+		simulatedLevenshtein,
+	}
+)
+
+func getSimulatedGenerators(options Options) []simulatedGenerator {
+	letters, _ := parseCharset(charsetLetters)
+
+	digits := func(binary bool) func(count int) []string {
+		return func(count int) []string {
+			return strings.Split(generateRows(count, count, binary)[0], "")
+		}
+	}
+
+	name := "numbers"
+	if !options.Charset.isDigits() {
+		name = options.Charset.Name
+	}
+
+	return []simulatedGenerator{
+		{
+			name: name,
+			generate: func(count int) []string {
+				return options.Charset.generateTokens(
+					seeded, options.Min, options.Max, count,
+				)
+			},
+			scorings: simulatedSequence,
+		},
+		{
+			name: "letters",
+			generate: func(count int) []string {
+				return letters.generateTokens(seeded, 0, 0, count)
+			},
+			scorings: simulatedSequence,
+		},
+		{
+			name:     "decimal",
+			generate: digits(false),
+			scorings: append(simulatedSequence, simulatedRows),
+		},
+		{
+			name:     "binary",
+			generate: digits(true),
+			scorings: append(simulatedSequence, simulatedRows),
+		},
+	}
+}
+
+// simulateRecall returns items recalled by player of specified accuracy,
+// every item is mistaken independently of others.
+func simulateRecall(
+	generator simulatedGenerator, items []string, accuracy float64,
+) []string {
+	const precision = 1 << 20
+
+	input := []string{}
+	for _, item := range items {
+		recalled := item
+		if float64(randomInt(precision))/precision >= accuracy {
+			recalled = simulateMistake(generator, item)
+		}
+
+		input = append(input, recalled)
+	}
+
+	return input
+}
+
+// simulateMistake returns another item of the generator, generators with
+// tiny ranges may not have another item, then the item is forgotten.
+func simulateMistake(generator simulatedGenerator, item string) string {
+	for attempt := 0; attempt < 10; attempt++ {
+		mistake := generator.generate(1)[0]
+		if mistake != item {
+			return mistake
+		}
+	}
+
+	return ""
+}
+
+// splitRows joins single digits into rows of specified length.
+func splitRows(digits []string, length int) []string {
+	rows := []string{}
+	for start := 0; start < len(digits); start += length {
+		end := start + length
+		if end > len(digits) {
+			end = len(digits)
+		}
+
+		rows = append(rows, strings.Join(digits[start:end], ""))
+	}
+
+	return rows
+}
+
+// runSimulation simulates sessions of bot opponent from config and prints
+// distribution of accuracy by every generator and scoring strategy, so
+// defaults of new modes can be compared before playing them.
+func runSimulation(args map[string]interface{}) error {
+	options, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	trials, err := strconv.Atoi(args["--trials"].(string))
+	if err != nil || trials <= 0 {
+		return fmt.Errorf(
+			"--trials: %q is not a positive number", args["--trials"],
+		)
+	}
+
+	config, _, err := loadConfig(options.Config)
+	if err != nil {
+		return err
+	}
+
+	err = setLocale(config.Locale)
+	if err != nil {
+		return err
+	}
+
+	player := config.Opponent.withDefaults()
+
+	// simulation is reproducible, so defaults can be compared run by run
+	seedRandom(1)
+	defer func() {
+		seeded = nil
+	}()
+
+	fmt.Printf(
+		"%s sessions of %d items, player accuracy %s%% ± %s%%\n\n",
+		formatInt(trials), options.Count,
+		formatFloat(player.Skill*100, 0), formatFloat(player.Spread*100, 0),
+	)
+	fmt.Printf(
+		"%-9s %-11s %6s %6s %6s %6s %6s %6s\n",
+		"items", "scoring", "mean", "sd", "p10", "p50", "p90", "zero",
+	)
+
+	for _, generator := range getSimulatedGenerators(options) {
+		accuracies := make([][]float64, len(generator.scorings))
+
+		for trial := 0; trial < trials; trial++ {
+			accuracy := clampFloat(
+				player.Skill+player.Spread*sampleNormal(), 0, 1,
+			)
+
+			items := generator.generate(options.Count)
+			input := simulateRecall(generator, items, accuracy)
+
+			for index, scoring := range generator.scorings {
+				accuracies[index] = append(
+					accuracies[index],
+					float64(scoring.score(items, input))/float64(len(items)),
+				)
+			}
+		}
+
+		for index, scoring := range generator.scorings {
+			printDistribution(generator.name, scoring.name, accuracies[index])
+		}
+	}
+
+	return nil
+}
+
+// printDistribution prints mean, standard deviation, percentiles and share
+// of zero scores of accuracy in percents.
+func printDistribution(generator, scoring string, accuracies []float64) {
+	sort.Float64s(accuracies)
+
+	var sum, zeros float64
+	for _, accuracy := range accuracies {
+		sum += accuracy
+		if accuracy == 0 {
+			zeros++
+		}
+	}
+
+	mean := sum / float64(len(accuracies))
+
+	var deviation float64
+	for _, accuracy := range accuracies {
+		deviation += (accuracy - mean) * (accuracy - mean)
+	}
+
+	deviation = math.Sqrt(deviation / float64(len(accuracies)))
+
+	percentile := func(share float64) float64 {
+		return accuracies[int(share*float64(len(accuracies)-1))]
+	}
+
+	fmt.Printf("%-9s %-11s", generator, scoring)
+	for _, value := range []float64{
+		mean, deviation, percentile(0.1), percentile(0.5), percentile(0.9),
+		zeros / float64(len(accuracies)),
+	} {
+		fmt.Printf(" %5s%%", formatFloat(value*100, 0))
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+const (
+	// side of the smallest grid, the grid grows so the sequence takes at
+	// most half of its cells
+	minSpatialSide = 4
+
+	// cell is drawn like "[   ]", cells of a row are split by the gap
+	spatialCellWidth = 5
+	spatialGap       = 1
+)
+
+// getSpatialSide returns count of cells in row and column of the grid.
+func getSpatialSide(count int) int {
+	side := minSpatialSide
+	for side*side < count*2 {
+		side++
+	}
+
+	return side
+}
+
+// generateCells returns sequence of different cells of the grid, which are
+// named like chess squares, but rows are counted from the top. Cells are
+// drawn from crypto/rand if the source is nil.
+func generateCells(source *rand.Rand, count int) []string {
+	side := getSpatialSide(count)
+
+	cells := []string{}
+	for len(cells) < count {
+		cell := getCellName(
+			randomIntFrom(source, side), randomIntFrom(source, side),
+		)
+		if indexOf(cells, cell) < 0 {
+			cells = append(cells, cell)
+		}
+	}
+
+	return cells
+}
+
+func getCellName(x, y int) string {
+	return fmt.Sprintf("%c%d", 'a'+x, y+1)
+}
+
+func runSpatialTest(options Options, test Test) (Result, error) {
+	rate := test.Rate
+	if rate == 0 {
+		rate = scaleDuration(defaultRate, options.TimeScale)
+	}
+
+	side := getSpatialSide(test.Count)
+	cells := generateCells(seeded, test.Count)
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	err := showCells(side, cells, rate)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input, err := recallCells(side, len(cells))
+	if err != nil {
+		return Result{}, err
+	}
+
+	scoring := options.getScoring(modeSpatial)
+	score := scoreSequence(scoring, cells, input)
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, modeSpatial, cells, " "),
+				getDiffLine("entered: ", input, " ", isSameAt(cells, input)),
+			},
+			score, len(cells),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Interference: interference,
+		Score:        score,
+		Duration:     duration,
+		Count:        len(cells),
+		Note:         note,
+		Mode:         modeSpatial,
+		Scoring:      scoring,
+		Items:        cells,
+		Input:        input,
+	}, nil
+}
+
+// showCells lights cells one by one at the rate, the grid is blank for a
+// quarter of the rate between cells, so neighbours are seen as two cells.
+func showCells(side int, cells []string, rate time.Duration) error {
+	for _, cell := range cells {
+		drawGrid(side, cell, "", nil)
+		screen.HideCursor()
+		screen.Flush()
+
+		err := waitTimeout(rate - rate/4)
+		if err != nil {
+			return err
+		}
+
+		drawGrid(side, "", "", nil)
+		screen.Flush()
+
+		err = waitTimeout(rate / 4)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recallCells reads cells selected by moving the cursor, Enter or Space
+// selects the cell under it and Backspace unselects the last one.
+func recallCells(side, count int) ([]string, error) {
+	x, y := side/2, side/2
+
+	input := []string{}
+	for len(input) < count {
+		drawGrid(side, "", getCellName(x, y), input)
+
+		_, height := screen.Size()
+		printCentered(
+			fmt.Sprintf(
+				"cell %d of %d, Enter: select, Backspace: undo",
+				len(input)+1, count,
+			),
+			height-1,
+		)
+
+		screen.HideCursor()
+		screen.Flush()
+
+		event := pollEvent()
+		if event.Type != ui.EventKey {
+			continue
+		}
+
+		if direction, ok := keys.getDirection(event); ok {
+			x = clamp(x+direction.x, 0, side-1)
+			y = clamp(y+direction.y, 0, side-1)
+			continue
+		}
+
+		switch event.Key {
+		case ui.KeyEnter, ui.KeySpace:
+			input = append(input, getCellName(x, y))
+		case ui.KeyBackspace:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		case ui.KeyCtrlC, ui.KeyCtrlZ:
+			return nil, errAborted
+		case ui.KeyEsc:
+			return nil, errStopped
+		}
+	}
+
+	return input, nil
+}
+
+// drawGrid draws the grid in the center of the screen, the lit cell is
+// filled, the cursor is reversed and selected cells show their order.
+func drawGrid(side int, lit string, cursor string, selected []string) {
+	width, height := screen.Size()
+
+	step := spatialCellWidth + spatialGap
+	left := width/2 - (side*step-spatialGap)/2
+	top := height/2 - side + 1
+
+	clearScreen()
+
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			name := getCellName(x, y)
+
+			text := "[   ]"
+			color := theme.text
+			if name == lit {
+				text = "[###]"
+				color = theme.correct
+			}
+
+			for order, cell := range selected {
+				if cell == name {
+					text = fmt.Sprintf("[%2d ]", order+1)
+				}
+			}
+
+			if name == cursor {
+				color |= ui.AttrReverse
+			}
+
+			for offset, symbol := range text {
+				screen.SetCell(
+					left+x*step+offset, top+y*2, symbol,
+					color, ui.ColorDefault,
+				)
+			}
+		}
+	}
+}
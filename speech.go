@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+var (
+	numberWords = map[string]int{
+		"zero": 0, "oh": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+		"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+		"eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+		"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18,
+		"nineteen": 19,
+	}
+
+	tensWords = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+		"seventy": 70, "eighty": 80, "ninety": 90,
+	}
+)
+
+// recallBySpeech runs speech recognizer which should record the user and
+// print transcript to stdout, returns numbers found in the transcript.
+func recallBySpeech(command string, y int) ([]int, string, error) {
+	clearScreen()
+	printCentered("listening, Ctrl+C to abort", y)
+	screen.HideCursor()
+	screen.Flush()
+
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, "", fmt.Errorf("speech command is empty")
+	}
+
+	recognizer := exec.Command(args[0], args[1:]...)
+
+	output := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	recognizer.Stdout = output
+	recognizer.Stderr = stderr
+
+	err := recognizer.Start()
+	if err != nil {
+		return nil, "", fmt.Errorf("can't run speech command: %s", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- recognizer.Wait()
+		screen.Interrupt()
+	}()
+
+	for {
+		event := pollEvent()
+
+		switch event.Type {
+		case ui.EventInterrupt:
+			// interrupt could be left by the timer of exposure
+			select {
+			case err := <-done:
+				if err != nil {
+					return nil, "", fmt.Errorf(
+						"speech command failed: %s: %s",
+						err, strings.TrimSpace(stderr.String()),
+					)
+				}
+
+				transcript := strings.TrimSpace(output.String())
+
+				return parseTranscript(transcript), transcript, nil
+			default:
+			}
+
+		case ui.EventKey:
+			switch event.Key {
+			case ui.KeyCtrlC, ui.KeyCtrlZ:
+				recognizer.Process.Kill()
+				<-done
+
+				return nil, "", errAborted
+			case ui.KeyEsc:
+				recognizer.Process.Kill()
+				<-done
+
+				return nil, "", errStopped
+			}
+		}
+	}
+}
+
+// parseTranscript extracts numbers from transcript of speech, numbers may be
+// written with digits or words up to ninety nine, like "forty two 17 five".
+func parseTranscript(transcript string) []int {
+	words := strings.FieldsFunc(
+		strings.ToLower(transcript),
+		func(symbol rune) bool {
+			return !unicode.IsLetter(symbol) && !unicode.IsDigit(symbol)
+		},
+	)
+
+	numbers := []int{}
+
+	// tens word like "forty" waits for units word, like "two"
+	pending := -1
+	flush := func() {
+		if pending >= 0 {
+			numbers = append(numbers, pending)
+			pending = -1
+		}
+	}
+
+	for _, word := range words {
+		if value, ok := tensWords[word]; ok {
+			flush()
+			pending = value
+			continue
+		}
+
+		if value, ok := numberWords[word]; ok {
+			if pending >= 0 && value < 10 && value > 0 {
+				numbers = append(numbers, pending+value)
+				pending = -1
+				continue
+			}
+
+			flush()
+			numbers = append(numbers, value)
+			continue
+		}
+
+		if value, err := strconv.Atoi(word); err == nil {
+			flush()
+			numbers = append(numbers, value)
+		}
+	}
+
+	flush()
+
+	return numbers
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// interval between digits in spoken mode when test doesn't specify it
+const defaultRate = time.Second
+
+func runSpokenTest(options Options, test Test) (Result, error) {
+	rate := test.Rate
+	if rate == 0 {
+		rate = scaleDuration(defaultRate, options.TimeScale)
+	}
+
+	digits := []int{}
+	for i := 0; i < test.Count; i++ {
+		digits = append(digits, randomInt(10))
+	}
+
+	_, height := screen.Size()
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	for _, digit := range digits {
+		clearScreen()
+		printCentered(fmt.Sprint(digit), height/2)
+		screen.HideCursor()
+		screen.Flush()
+
+		speak(options.SpeakCommand, digit)
+
+		err := waitTimeout(rate)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	clearScreen()
+
+	text, err := readLine("digits:", height/2-1)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input := []int{}
+	for _, symbol := range text {
+		if isDigit(symbol) {
+			input = append(input, int(symbol-'0'))
+		}
+	}
+
+	// spoken numbers are scored until the first mistake
+	score := compare(digits, input)
+
+	clearScreen()
+
+	valid := strings.Fields(joinNumbers(digits))
+	entered := strings.Fields(joinNumbers(input))
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, modeSpoken, valid, ""),
+				getDiffLine("entered: ", entered, "", isSameAt(valid, entered)),
+			},
+			score, len(digits),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Score:    score,
+		Duration: duration,
+		Count:    test.Count,
+		Note:     note,
+		Mode:     modeSpoken,
+		Items:    valid,
+		Input:    entered,
+	}, nil
+}
+
+// speak runs speak_command from config with the digit as the last argument,
+// the command isn't waited, so it can't delay the next digit.
+func speak(command string, digit int) {
+	if command == "" {
+		return
+	}
+
+	args := append(strings.Fields(command), fmt.Sprint(digit))
+
+	err := exec.Command(args[0], args[1:]...).Start()
+	if err != nil {
+		log.warn("can't run speak command", Fields{"error": err})
+	}
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// every SQLite database starts with this header, databases without it are
+// JSON files written by previous versions
+const sqliteHeader = "SQLite format 3\x00"
+
+// sessions and results keep fields used for queries in columns, the rest is
+// in data column as JSON, so new fields don't need schema changes
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY,
+		date TEXT NOT NULL,
+		avg_duration REAL NOT NULL,
+		total_score INTEGER NOT NULL,
+		practice INTEGER NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS results (
+		session_id INTEGER NOT NULL
+			REFERENCES sessions (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		mode TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		score INTEGER NOT NULL,
+		duration REAL NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (session_id, position)
+	)`,
+	`CREATE INDEX IF NOT EXISTS sessions_date ON sessions (date)`,
+	`CREATE TABLE IF NOT EXISTS stimuli (
+		id TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`,
+}
+
+// version of database which moved items of results into stimuli archive
+const stimuliVersion = 3
+
+// items shorter than this are kept in results, reference to the archive
+// would take as much space as them
+const minArchivedItems = 64
+
+func openSQLite(file string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", file+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range sqliteSchema {
+		_, err = db.Exec(statement)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("can't create schema of %s: %s", file, err)
+		}
+	}
+
+	var version int
+	err = db.QueryRow(`PRAGMA user_version`).Scan(&version)
+	if err == nil && version > databaseVersion {
+		err = fmt.Errorf(
+			"unsupported database version %d, latest known is %d",
+			version, databaseVersion,
+		)
+	}
+	if err == nil && version < stimuliVersion {
+		err = archiveStimuli(db)
+	}
+	if err == nil {
+		_, err = db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, databaseVersion))
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// isSQLite tells if the file is SQLite database, missing file is not.
+func isSQLite(file string) (bool, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+	defer fd.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	_, err = fd.Read(header)
+
+	return err == nil && string(header) == sqliteHeader, nil
+}
+
+// importJSON converts JSON database written by previous versions into SQLite
+// database at the same path, so -f keeps pointing at the database. Original
+// file is kept next to it with .json suffix.
+func importJSON(file string) error {
+	fd, err := openCompressed(file)
+	if err != nil {
+		return err
+	}
+
+	database, _, err := decodeDatabaseStream(bufio.NewReader(fd))
+	fd.Close()
+	if err != nil {
+		return fmt.Errorf("can't decode database %s: %s", file, err)
+	}
+
+	// database is built aside and renamed over JSON file, so interrupted
+	// import leaves JSON file in place and is done again next time
+	temp := file + ".import"
+	os.Remove(temp)
+
+	err = writeSQLite(temp, database)
+	if err != nil {
+		os.Remove(temp)
+		return err
+	}
+
+	// original is kept as it is, so it's still compressed if it was
+	backup := file + ".json"
+	if isCompressed(file) {
+		backup += filepath.Ext(file)
+	}
+
+	err = copyFile(file, backup)
+	if err != nil {
+		os.Remove(temp)
+		return err
+	}
+
+	err = os.Rename(temp, file)
+	if err != nil {
+		return err
+	}
+
+	log.info("database imported into SQLite", Fields{
+		"database": file,
+		"sessions": len(database.Sessions),
+		"backup":   backup,
+	})
+
+	return nil
+}
+
+func writeSQLite(file string, database Database) error {
+	db, err := openSQLite(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return replaceSessions(db, database.Sessions)
+}
+
+// readSessions returns all sessions in order of their recording.
+func readSessions(db *sql.DB) ([]Session, error) {
+	sessions := []Session{}
+	ids := map[int64]int{}
+
+	stimuli, err := readStimuli(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id, data FROM sessions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int64
+			data []byte
+		)
+
+		err = rows.Scan(&id, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		session := Session{}
+		err = json.Unmarshal(data, &session)
+		if err != nil {
+			return nil, fmt.Errorf("session #%d: %s", id, err)
+		}
+
+		session.Results = []Result{}
+
+		ids[id] = len(sessions)
+		sessions = append(sessions, session)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := db.Query(
+		`SELECT session_id, data FROM results ORDER BY session_id, position`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	for results.Next() {
+		var (
+			id   int64
+			data []byte
+		)
+
+		err = results.Scan(&id, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		index, ok := ids[id]
+		if !ok {
+			continue
+		}
+
+		result := Result{}
+		err = json.Unmarshal(data, &result)
+		if err != nil {
+			return nil, fmt.Errorf("result of session #%d: %s", id, err)
+		}
+
+		if result.Stimulus != "" {
+			result.Items = stimuli[result.Stimulus]
+		}
+
+		sessions[index].Results = append(sessions[index].Results, result)
+	}
+
+	return sessions, results.Err()
+}
+
+// replaceSessions rewrites all sessions in one transaction.
+func replaceSessions(db *sql.DB, sessions []Session) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM sessions`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, session := range sessions {
+		err = insertSession(tx, session)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// items of removed sessions are not referenced anymore
+	_, err = tx.Exec(
+		`DELETE FROM stimuli WHERE id NOT IN (
+			SELECT json_extract(data, '$.stimulus') FROM results
+			WHERE json_extract(data, '$.stimulus') IS NOT NULL
+		)`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertSession(tx *sql.Tx, session Session) error {
+	results := session.Results
+	session.Results = nil
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := tx.Exec(
+		`INSERT INTO sessions (date, avg_duration, total_score, practice, data)
+		VALUES (?, ?, ?, ?, ?)`,
+		session.Date.UTC().Format(time.RFC3339Nano), session.AvgDuration,
+		session.TotalScore, session.Practice, data,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := inserted.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for position, result := range results {
+		result, err := archiveItems(tx, result)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO results
+			(session_id, position, mode, count, score, duration, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, position, result.getMode(), result.Count, result.Score,
+			result.Duration, data,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendSession adds session to the database without rewriting others,
+// compressed JSON databases are rewritten. The database is locked while
+// it's written.
+func appendSession(file string, session Session) error {
+	release, err := lockDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	compressed, err := isCompressedDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	if compressed {
+		database, err := readCompressedDatabase(file)
+		if err != nil {
+			return err
+		}
+
+		database.Sessions = append(database.Sessions, session)
+
+		return writeCompressedDatabase(file, database)
+	}
+
+	err = ensureSQLite(file)
+	if err != nil {
+		return err
+	}
+
+	db, err := openSQLite(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = insertSession(tx, session)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ensureSQLite imports JSON database if the file is not SQLite yet, empty
+// JSON files are replaced by new database.
+func ensureSQLite(file string) error {
+	ok, err := isSQLite(file)
+	if err != nil || ok {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(content)) == 0 {
+		return os.Remove(file)
+	}
+
+	return importJSON(file)
+}
+
+func copyFile(source string, target string) error {
+	input, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	output, err := os.OpenFile(
+		target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(output, input)
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// readStimuli returns items of stimuli archive by their ids.
+func readStimuli(db *sql.DB) (map[string][]string, error) {
+	stimuli := map[string][]string{}
+
+	rows, err := db.Query(`SELECT id, data FROM stimuli`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    string
+			data  []byte
+			items []string
+		)
+
+		err = rows.Scan(&id, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(data, &items)
+		if err != nil {
+			return nil, fmt.Errorf("stimulus %s: %s", id, err)
+		}
+
+		stimuli[id] = items
+	}
+
+	return stimuli, rows.Err()
+}
+
+// archiveItems moves long items of the result into stimuli archive, result
+// refers to them by id, which is hash of the items.
+func archiveItems(tx *sql.Tx, result Result) (Result, error) {
+	if len(result.Items) == 0 {
+		return result, nil
+	}
+
+	data, err := json.Marshal(result.Items)
+	if err != nil {
+		return result, err
+	}
+
+	if len(data) < minArchivedItems {
+		return result, nil
+	}
+
+	hash := sha256.Sum256(data)
+	id := hex.EncodeToString(hash[:16])
+
+	_, err = tx.Exec(
+		`INSERT OR IGNORE INTO stimuli (id, data) VALUES (?, ?)`, id, data,
+	)
+	if err != nil {
+		return result, err
+	}
+
+	result.Stimulus = id
+	result.Items = nil
+
+	return result, nil
+}
+
+// archiveStimuli moves items of results written by previous versions into
+// stimuli archive and compacts the file.
+func archiveStimuli(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(
+		`SELECT session_id, position, data FROM results
+		WHERE json_extract(data, '$.items') IS NOT NULL`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	type archived struct {
+		session  int64
+		position int
+		result   Result
+	}
+
+	results := []archived{}
+	for rows.Next() {
+		var (
+			row  archived
+			data []byte
+		)
+
+		err = rows.Scan(&row.session, &row.position, &data)
+		if err == nil {
+			err = json.Unmarshal(data, &row.result)
+		}
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+
+		results = append(results, row)
+	}
+
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, row := range results {
+		result, err := archiveItems(tx, row.result)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE results SET data = ? WHERE session_id = ? AND position = ?`,
+			data, row.session, row.position,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil || len(results) == 0 {
+		return err
+	}
+
+	log.info("items of results moved into stimuli archive", Fields{
+		"results": len(results),
+	})
+
+	_, err = db.Exec(`VACUUM`)
+
+	return err
+}
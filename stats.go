@@ -0,0 +1,729 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// count of study time bins in accuracy-speed tradeoff table
+	tradeoffBins = 5
+
+	plotWidth  = 60
+	plotHeight = 10
+)
+
+const (
+	statsOverview   = "overview"
+	statsTradeoff   = "tradeoff"
+	statsSchedule   = "schedule"
+	statsScript     = "script"
+	statsAlteration = "alteration"
+	statsRating     = "rating"
+	statsDifficulty = "difficulty"
+	statsChart      = "chart"
+	statsFitness    = "fitness"
+)
+
+// printStats prints the view of scored sessions of the profile, last is
+// count of sessions plotted by chart and fitness. Sessions are bucketed into
+// days in the location and into weeks starting from the weekday.
+func printStats(
+	file string, view string, profile string, last int, fitness Fitness,
+	location *time.Location, weekStart time.Weekday,
+) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	database = database.getProfile(profile).getScored()
+
+	// anomalies are listed, but excluded ones don't shift trends
+	scored := database
+	database = database.getUntagged(anomalyTag)
+
+	if len(database.Sessions) == 0 {
+		fmt.Println("no sessions recorded yet")
+		return nil
+	}
+
+	switch view {
+	case statsTradeoff:
+		printTradeoff(database)
+	case statsSchedule:
+		printScheduleComparison(database)
+	case statsScript:
+		printScriptComparison(database)
+	case statsAlteration:
+		printAlterations(database)
+	case statsRating:
+		printRating(database)
+	case statsDifficulty:
+		printDifficulty(database)
+	case statsChart:
+		printChart(database, last, location)
+	case statsFitness:
+		printFitness(database, fitness, last)
+	default:
+		printOverview(database, fitness, clock().In(location), weekStart)
+		printAnomalies(scored)
+	}
+
+	return nil
+}
+
+func printOverview(
+	database Database, fitness Fitness, now time.Time, weekStart time.Weekday,
+) {
+	var (
+		tests       int
+		sumScore    int
+		sumDuration float64
+	)
+
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			tests++
+			sumScore += result.Score
+			sumDuration += result.Duration
+		}
+	}
+
+	// index of the week is shown first, it's the number to follow
+	since := now.AddDate(0, 0, -7)
+	recent := []Session{}
+	for _, session := range database.Sessions {
+		if session.Date.After(since) {
+			recent = append(recent, session)
+		}
+	}
+
+	if index, ok := fitness.getAverageFitness(recent); ok {
+		fmt.Printf(
+			"fitness:  %s, average of the last 7 days\n", formatFloat(index, 1),
+		)
+	}
+
+	fmt.Printf("sessions: %s\n", formatInt(len(database.Sessions)))
+	fmt.Printf("tests:    %s\n", formatInt(tests))
+
+	if tests > 0 {
+		fmt.Printf(
+			"average:  %s (%s sec)\n",
+			formatFloat(float64(sumScore)/float64(tests), 2),
+			formatFloat(sumDuration/float64(tests), 2),
+		)
+	}
+
+	ratings := getRatings(database)
+	fmt.Printf("rating:   %s\n", formatFloat(ratings[len(ratings)-1].Rating, 0))
+
+	printRowsScores(database)
+	printMatches(database)
+	printAggregates(database, now, weekStart)
+}
+
+// printAggregates shows the best and the worst sessions, rolling average of
+// the last days and accuracy by weeks and by count of items.
+func printAggregates(
+	database Database, now time.Time, weekStart time.Weekday,
+) {
+	sessions := findSessions(database, Query{}, orderDate, false)
+
+	best, worst := sessions[0], sessions[0]
+	for _, index := range sessions {
+		accuracy := getSessionAccuracy(database.Sessions[index])
+		if accuracy > getSessionAccuracy(database.Sessions[best]) {
+			best = index
+		}
+
+		if accuracy < getSessionAccuracy(database.Sessions[worst]) {
+			worst = index
+		}
+	}
+
+	fmt.Println()
+	for _, line := range []struct {
+		label string
+		index int
+	}{
+		{"best:", best},
+		{"worst:", worst},
+	} {
+		session := database.Sessions[line.index]
+		fmt.Printf(
+			"%-9s %s %s%% (%s tests)\n",
+			line.label, session.Date.Format("2006-01-02 15:04"),
+			formatFloat(getSessionAccuracy(session)*100, 1),
+			formatInt(getSessionTests(session)),
+		)
+	}
+
+	rolling := getRollingAverages(database, now)
+	if last := rolling[len(rolling)-1]; last.tests > 0 {
+		fmt.Printf(
+			"%-9s %s%% (%s tests)\n", fmt.Sprintf("%d days:", rollingDays),
+			formatFloat(last.accuracy*100, 1), formatInt(last.tests),
+		)
+	}
+
+	if len(rolling) > 1 {
+		values := []float64{}
+		for _, day := range rolling {
+			values = append(values, day.accuracy*100)
+		}
+
+		fmt.Printf("\n%d-day rolling average, %%:\n", rollingDays)
+		for _, line := range renderLine(values, plotWidth, plotHeight) {
+			fmt.Println(line)
+		}
+	}
+
+	fmt.Println("\nby week:")
+	printAccuracyGroups(database, func(session Session, result Result) string {
+		week := getWeek(session.Date.In(now.Location()), weekStart)
+		return week.Format("2006-01-02")
+	})
+
+	// forward and backward spans of the same count are different tests, so
+	// are spans of letters and digits
+	fmt.Println("\nby count:")
+	printAccuracyGroups(database, func(session Session, result Result) string {
+		key := fmt.Sprintf("%-8s count %3d", result.getMode(), result.Count)
+		if result.Charset != "" {
+			key += " " + result.Charset
+		}
+
+		return key
+	})
+}
+
+// days of rolling average
+const rollingDays = 7
+
+type rollingAverage struct {
+	tests    int
+	accuracy float64
+}
+
+// getRollingAverages returns average accuracy of tests of the last days for
+// every day since the first session till now.
+func getRollingAverages(database Database, now time.Time) []rollingAverage {
+	type day struct {
+		tests int
+		sum   float64
+	}
+
+	days := map[time.Time]*day{}
+	first := getDay(now)
+	for _, session := range database.Sessions {
+		date := getDay(session.Date.In(now.Location()))
+		if date.Before(first) {
+			first = date
+		}
+
+		if days[date] == nil {
+			days[date] = &day{}
+		}
+
+		for _, result := range session.Results {
+			days[date].tests++
+			days[date].sum += getAccuracy(result)
+		}
+	}
+
+	averages := []rollingAverage{}
+	for date := first; !date.After(getDay(now)); date = date.AddDate(0, 0, 1) {
+		var total day
+		for offset := 0; offset < rollingDays; offset++ {
+			if value := days[date.AddDate(0, 0, -offset)]; value != nil {
+				total.tests += value.tests
+				total.sum += value.sum
+			}
+		}
+
+		average := rollingAverage{tests: total.tests}
+		if total.tests > 0 {
+			average.accuracy = total.sum / float64(total.tests)
+		}
+
+		averages = append(averages, average)
+	}
+
+	return averages
+}
+
+// printAccuracyGroups shows accuracy of tests grouped by key, groups are
+// sorted by key.
+func printAccuracyGroups(
+	database Database, getKey func(Session, Result) string,
+) {
+	type group struct {
+		tests int
+		sum   float64
+	}
+
+	groups := map[string]*group{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			key := getKey(session, result)
+			if groups[key] == nil {
+				groups[key] = &group{}
+			}
+
+			groups[key].tests++
+			groups[key].sum += getAccuracy(result)
+		}
+	}
+
+	keys := []string{}
+	for key := range groups {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		accuracy := groups[key].sum / float64(groups[key].tests)
+		fmt.Printf(
+			"  %s %5s tests %5s%% %s\n",
+			key, formatInt(groups[key].tests), formatFloat(accuracy*100, 1),
+			renderBar(accuracy, 20),
+		)
+	}
+}
+
+// printRating plots rating after every session, rating accounts for mode,
+// count of items and study time, so it's comparable across sessions.
+func printRating(database Database) {
+	ratings := getRatings(database)
+
+	values := []float64{}
+	for _, point := range ratings {
+		values = append(values, point.Rating)
+	}
+
+	for _, line := range renderLine(values, plotWidth, plotHeight) {
+		fmt.Println(line)
+	}
+
+	first, last := ratings[0], ratings[len(ratings)-1]
+	fmt.Printf(
+		"\n%s: %s, %s: %s\n",
+		first.Date.Format("2006-01-02"), formatFloat(first.Rating, 0),
+		last.Date.Format("2006-01-02"), formatFloat(last.Rating, 0),
+	)
+}
+
+// printChart plots average score of tests by days of the last sessions,
+// days without sessions are skipped.
+func printChart(database Database, last int, location *time.Location) {
+	sessions := append([]Session{}, database.Sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Date.Before(sessions[j].Date)
+	})
+
+	if len(sessions) > last {
+		sessions = sessions[len(sessions)-last:]
+	}
+
+	days := []string{}
+	scores := map[string]int{}
+	tests := map[string]int{}
+	for _, session := range sessions {
+		// results of pruned sessions are not kept, only accuracy is
+		if len(session.Results) == 0 {
+			continue
+		}
+
+		day := session.Date.In(location).Format("2006-01-02")
+		if tests[day] == 0 {
+			days = append(days, day)
+		}
+
+		for _, result := range session.Results {
+			scores[day] += result.Score
+			tests[day]++
+		}
+	}
+
+	if len(days) == 0 {
+		fmt.Println("no tests in the last sessions")
+		return
+	}
+
+	// every day takes a column
+	if len(days) > plotWidth {
+		days = days[len(days)-plotWidth:]
+	}
+
+	values := []float64{}
+	for _, day := range days {
+		values = append(values, float64(scores[day])/float64(tests[day]))
+	}
+
+	for _, line := range renderBars(values, plotWidth, plotHeight) {
+		fmt.Println(line)
+	}
+
+	first, latest := days[0], days[len(days)-1]
+	fmt.Printf(
+		"\n%s: %s, %s: %s, average score of tests by %d days\n",
+		first, formatFloat(values[0], 2),
+		latest, formatFloat(values[len(values)-1], 2), len(days),
+	)
+}
+
+// printMatches shows total outcome of sessions played against the bot.
+func printMatches(database Database) {
+	var total Match
+	matches := 0
+	for _, session := range database.Sessions {
+		if session.Match == nil {
+			continue
+		}
+
+		matches++
+		total.Wins += session.Match.Wins
+		total.Losses += session.Match.Losses
+		total.Draws += session.Match.Draws
+	}
+
+	if matches == 0 {
+		return
+	}
+
+	fmt.Printf(
+		"matches:  %s, tests won %s, lost %s, draw %s\n",
+		formatInt(matches), formatInt(total.Wins), formatInt(total.Losses),
+		formatInt(total.Draws),
+	)
+}
+
+// printRowsScores shows points of rows mode tests for every scoring
+// strategy, points of different strategies can't be compared.
+func printRowsScores(database Database) {
+	type group struct {
+		tests  int
+		points int
+		best   int
+		digits int
+	}
+
+	groups := map[string]*group{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.getMode() != modeRows {
+				continue
+			}
+
+			scoring := result.getScoring()
+			if groups[scoring] == nil {
+				groups[scoring] = &group{}
+			}
+
+			group := groups[scoring]
+			group.tests++
+			group.points += result.Score
+			group.digits += result.Count
+			if result.Score > group.best {
+				group.best = result.Score
+			}
+		}
+	}
+
+	for _, scoring := range scorings {
+		group, ok := groups[scoring]
+		if !ok {
+			continue
+		}
+
+		fmt.Printf(
+			"rows, %s scoring: %s tests, %s points of %s digits "+
+				"on average, best %s\n",
+			scoring, formatInt(group.tests),
+			formatFloat(float64(group.points)/float64(group.tests), 1),
+			formatFloat(float64(group.digits)/float64(group.tests), 1),
+			formatInt(group.best),
+		)
+	}
+}
+
+// printTradeoff shows how recall accuracy depends on study time for every
+// count of numbers, so the user can see whether studying longer pays off.
+func printTradeoff(database Database) {
+	spans := map[int][]point{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.Count == 0 {
+				continue
+			}
+
+			spans[result.Count] = append(spans[result.Count], point{
+				X: result.Duration,
+				Y: float64(result.Score) / float64(result.Count),
+			})
+		}
+	}
+
+	counts := []int{}
+	for count := range spans {
+		counts = append(counts, count)
+	}
+
+	sort.Ints(counts)
+
+	for _, count := range counts {
+		points := spans[count]
+
+		fmt.Printf("count %d, %d tests\n\n", count, len(points))
+
+		for _, line := range renderScatter(points, plotWidth, plotHeight, "sec") {
+			fmt.Println(line)
+		}
+
+		fmt.Println()
+
+		for _, bin := range binPoints(points, tradeoffBins) {
+			if len(bin.points) == 0 {
+				continue
+			}
+
+			accuracy := averageY(bin.points)
+			fmt.Printf(
+				"  %5s-%-5s sec %4s tests %4s%% %s\n",
+				formatFloat(bin.from, 1), formatFloat(bin.to, 1),
+				formatInt(len(bin.points)), formatFloat(accuracy*100, 0),
+				renderBar(accuracy, 20),
+			)
+		}
+
+		fmt.Println()
+	}
+}
+
+// printScheduleComparison compares accuracy of blocked and interleaved
+// sessions for every count of numbers.
+func printScheduleComparison(database Database) {
+	type group struct {
+		sessions int
+		tests    int
+		accuracy float64
+	}
+
+	groups := map[string]map[int]*group{}
+	totals := map[string]*group{}
+	counts := map[int]bool{}
+
+	for _, session := range database.Sessions {
+		if session.Schedule == "" {
+			continue
+		}
+
+		if groups[session.Schedule] == nil {
+			groups[session.Schedule] = map[int]*group{}
+			totals[session.Schedule] = &group{}
+		}
+
+		totals[session.Schedule].sessions++
+
+		for _, result := range session.Results {
+			item := groups[session.Schedule][result.Count]
+			if item == nil {
+				item = &group{}
+				groups[session.Schedule][result.Count] = item
+			}
+
+			item.tests++
+			item.accuracy += getAccuracy(result)
+			totals[session.Schedule].tests++
+			totals[session.Schedule].accuracy += getAccuracy(result)
+			counts[result.Count] = true
+		}
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("no sessions with several counts of numbers recorded yet")
+		return
+	}
+
+	sortedCounts := []int{}
+	for count := range counts {
+		sortedCounts = append(sortedCounts, count)
+	}
+
+	sort.Ints(sortedCounts)
+
+	for _, schedule := range []string{scheduleBlocked, scheduleInterleaved} {
+		total := totals[schedule]
+		if total == nil {
+			fmt.Printf("%s: no sessions\n\n", schedule)
+			continue
+		}
+
+		fmt.Printf(
+			"%s: %s sessions, %s tests, %s%% accuracy\n",
+			schedule, formatInt(total.sessions), formatInt(total.tests),
+			formatFloat(total.accuracy/float64(total.tests)*100, 1),
+		)
+
+		for _, count := range sortedCounts {
+			item := groups[schedule][count]
+			if item == nil {
+				continue
+			}
+
+			accuracy := item.accuracy / float64(item.tests)
+			fmt.Printf(
+				"  count %-3d %4s tests %5s%% %s\n",
+				count, formatInt(item.tests), formatFloat(accuracy*100, 1),
+				renderBar(accuracy, 20),
+			)
+		}
+
+		fmt.Println()
+	}
+}
+
+// printScriptComparison shows accuracy of sessions grouped by script.
+func printScriptComparison(database Database) {
+	type group struct {
+		sessions int
+		tests    int
+		accuracy float64
+	}
+
+	groups := map[string]*group{}
+	for _, session := range database.Sessions {
+		if session.Script == "" {
+			continue
+		}
+
+		item := groups[session.Script]
+		if item == nil {
+			item = &group{}
+			groups[session.Script] = item
+		}
+
+		item.sessions++
+		for _, result := range session.Results {
+			item.tests++
+			item.accuracy += getAccuracy(result)
+		}
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("no sessions run by scripts recorded yet")
+		return
+	}
+
+	names := []string{}
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		item := groups[name]
+
+		accuracy := 0.0
+		if item.tests > 0 {
+			accuracy = item.accuracy / float64(item.tests)
+		}
+
+		fmt.Printf(
+			"%-20s %4s sessions %5s tests %5s%% %s\n",
+			name, formatInt(item.sessions), formatInt(item.tests),
+			formatFloat(accuracy*100, 1),
+			renderBar(accuracy, 20),
+		)
+	}
+}
+
+// printAlterations shows how often every kind of alteration was missed in
+// judgment mode.
+func printAlterations(database Database) {
+	shown := map[string]int{}
+	missed := map[string]int{}
+	for _, session := range database.Sessions {
+		for _, result := range session.Results {
+			if result.Judgment == nil {
+				continue
+			}
+
+			kind := result.Judgment.Alteration
+			if kind == "" {
+				kind = "none"
+			}
+
+			shown[kind]++
+			if !result.Judgment.Correct {
+				missed[kind]++
+			}
+		}
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("no judgment tests recorded yet")
+		return
+	}
+
+	for _, kind := range append([]string{"none"}, alterations...) {
+		if shown[kind] == 0 {
+			continue
+		}
+
+		rate := float64(missed[kind]) / float64(shown[kind])
+		fmt.Printf(
+			"%-15s %4s shown %4s missed %5s%% %s\n",
+			kind, formatInt(shown[kind]), formatInt(missed[kind]),
+			formatFloat(rate*100, 1), renderBar(rate, 20),
+		)
+	}
+}
+
+type pointsBin struct {
+	from   float64
+	to     float64
+	points []point
+}
+
+// binPoints splits points into bins of equal width by X value.
+func binPoints(points []point, count int) []pointsBin {
+	maxX := 0.0
+	for _, point := range points {
+		maxX = math.Max(maxX, point.X)
+	}
+
+	width := maxX / float64(count)
+
+	bins := make([]pointsBin, count)
+	for index := range bins {
+		bins[index].from = width * float64(index)
+		bins[index].to = width * float64(index+1)
+	}
+
+	for _, point := range points {
+		index := count - 1
+		if width > 0 {
+			index = clamp(int(point.X/width), 0, count-1)
+		}
+
+		bins[index].points = append(bins[index].points, point)
+	}
+
+	return bins
+}
+
+func averageY(points []point) float64 {
+	sum := 0.0
+	for _, point := range points {
+		sum += point.Y
+	}
+
+	return sum / float64(len(points))
+}
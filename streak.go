@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// getStreak returns count of consecutive days with at least one session,
+// ending today or yesterday, and whether there is a session today. Streak
+// which ends yesterday is still alive, the user can extend it today.
+func getStreak(database Database, now time.Time) (int, bool) {
+	days := map[time.Time]bool{}
+	for _, session := range database.Sessions {
+		days[getDay(session.Date.In(now.Location()))] = true
+	}
+
+	today := getDay(now)
+	practicedToday := days[today]
+
+	day := today
+	if !practicedToday {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for days[day] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return streak, practicedToday
+}
+
+// getDay truncates time to the beginning of its day.
+func getDay(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+}
+
+// getWeek returns the beginning of the week the date belongs to, weeks
+// start from the weekday.
+func getWeek(date time.Time, start time.Weekday) time.Time {
+	day := getDay(date)
+	offset := (int(day.Weekday()) - int(start) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// parseGoal returns daily goal of tests set by --goal, zero if it's not set.
+func parseGoal(args map[string]interface{}) (int, error) {
+	value, ok := args["--goal"].(string)
+	if !ok {
+		return 0, nil
+	}
+
+	goal, err := parseInt("--goal", value)
+	if err == nil && goal <= 0 {
+		err = fmt.Errorf("--goal: count of tests should be positive")
+	}
+
+	return goal, err
+}
+
+// getTestsOfDay returns count of tests of sessions recorded on the day of
+// the date.
+func getTestsOfDay(database Database, date time.Time) int {
+	day := getDay(date)
+
+	tests := 0
+	for _, session := range database.Sessions {
+		if getDay(session.Date.In(date.Location())).Equal(day) {
+			tests += getSessionTests(session)
+		}
+	}
+
+	return tests
+}
+
+// formatGoal returns count of tests of today compared with the goal, zero
+// goal means there is no goal.
+func formatGoal(database Database, goal int, now time.Time) string {
+	tests := getTestsOfDay(database, now)
+	if goal == 0 {
+		return fmt.Sprintf("%d tests", tests)
+	}
+
+	if tests >= goal {
+		return fmt.Sprintf("%d of %d tests, goal is met", tests, goal)
+	}
+
+	return fmt.Sprintf(
+		"%d of %d tests, %d more to meet the goal", tests, goal, goal-tests,
+	)
+}
+
+func formatStreak(database Database, now time.Time) string {
+	streak, today := getStreak(database, now)
+
+	line := formatInt(streak) + " days"
+	if !today {
+		line += ", not practiced today yet"
+	}
+
+	return line
+}
+
+// printStreak shows streak of the profile and tests of today, days are
+// taken in the location.
+func printStreak(
+	file string, profile string, goal int, location *time.Location,
+) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	database = database.getProfile(profile)
+	now := clock().In(location)
+
+	fmt.Printf("streak: %s\n", formatStreak(database, now))
+	fmt.Printf("today:  %s\n", formatGoal(database, goal, now))
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WeeklySummary struct {
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	Sessions         int       `json:"sessions"`
+	Tests            int       `json:"tests"`
+	BestSpan         int       `json:"best_span"`
+	Accuracy         float64   `json:"accuracy"`
+	PreviousAccuracy *float64  `json:"previous_accuracy"`
+	Streak           int       `json:"streak"`
+	PracticedToday   bool      `json:"practiced_today"`
+
+	// average memory fitness index of sessions of the week
+	Fitness *float64 `json:"fitness"`
+}
+
+// printSummary prints digest of the week of now in the location, weeks
+// start from the weekday. Empty profile means all profiles.
+func printSummary(
+	file string, format string, profile string, fitness Fitness,
+	location *time.Location, weekStart time.Weekday,
+) error {
+	database, err := loadDatabase(file)
+	if err != nil {
+		return err
+	}
+
+	summary := getWeeklySummary(
+		database.getProfile(profile).getScored(), fitness,
+		clock().In(location), weekStart,
+	)
+
+	var output string
+	switch format {
+	case "text":
+		output = formatSummaryText(summary)
+	case "markdown":
+		output = formatSummaryMarkdown(summary)
+	case "json":
+		content, err := json.MarshalIndent(summary, "", "    ")
+		if err != nil {
+			return err
+		}
+
+		output = string(content) + "\n"
+	default:
+		return fmt.Errorf(
+			"--format: unknown summary format %q, expected text, markdown or json",
+			format,
+		)
+	}
+
+	fmt.Print(output)
+
+	return nil
+}
+
+func getWeeklySummary(
+	database Database, fitness Fitness, now time.Time, weekStart time.Weekday,
+) WeeklySummary {
+	week := getWeek(now, weekStart)
+	previousWeek := week.AddDate(0, 0, -7)
+
+	summary := WeeklySummary{
+		From: week,
+		To:   week.AddDate(0, 0, 6),
+	}
+
+	var (
+		accuracy         float64
+		previousAccuracy float64
+		previousTests    int
+		sessions         []Session
+	)
+
+	for _, session := range database.Sessions {
+		date := session.Date.In(now.Location())
+		switch {
+		case !date.Before(week):
+			summary.Sessions++
+			sessions = append(sessions, session)
+			for _, result := range session.Results {
+				summary.Tests++
+				accuracy += getAccuracy(result)
+
+				if result.Score == result.Count && result.Count > summary.BestSpan {
+					summary.BestSpan = result.Count
+				}
+			}
+
+		case !date.Before(previousWeek):
+			for _, result := range session.Results {
+				previousTests++
+				previousAccuracy += getAccuracy(result)
+			}
+		}
+	}
+
+	if summary.Tests > 0 {
+		summary.Accuracy = accuracy / float64(summary.Tests)
+	}
+
+	if previousTests > 0 {
+		previousAccuracy /= float64(previousTests)
+		summary.PreviousAccuracy = &previousAccuracy
+	}
+
+	if index, ok := fitness.getAverageFitness(sessions); ok {
+		summary.Fitness = &index
+	}
+
+	summary.Streak, summary.PracticedToday = getStreak(database, now)
+
+	return summary
+}
+
+// getAccuracy returns part of numbers which were recalled correctly.
+func getAccuracy(result Result) float64 {
+	if result.Count == 0 {
+		return 0
+	}
+
+	return float64(result.Score) / float64(result.Count)
+}
+
+func getSummaryLines(summary WeeklySummary) [][2]string {
+	trend := "no data for previous week"
+	if summary.PreviousAccuracy != nil {
+		change := (summary.Accuracy - *summary.PreviousAccuracy) * 100
+
+		sign := "+"
+		if change < 0 {
+			sign = ""
+		}
+
+		trend = sign + formatFloat(change, 1) + "% vs previous week"
+	}
+
+	streak := formatInt(summary.Streak) + " days"
+	if !summary.PracticedToday {
+		streak += ", not practiced today yet"
+	}
+
+	index := "no tests this week"
+	if summary.Fitness != nil {
+		index = formatFloat(*summary.Fitness, 1)
+	}
+
+	return [][2]string{
+		{"fitness", index},
+		{"sessions", formatInt(summary.Sessions)},
+		{"tests", formatInt(summary.Tests)},
+		{"best span", formatInt(summary.BestSpan)},
+		{"accuracy", formatFloat(summary.Accuracy*100, 1) + "%"},
+		{"trend", trend},
+		{"streak", streak},
+	}
+}
+
+func formatSummaryText(summary WeeklySummary) string {
+	buffer := fmt.Sprintf(
+		"week %s - %s\n",
+		summary.From.Format("2006-01-02"), summary.To.Format("2006-01-02"),
+	)
+
+	for _, line := range getSummaryLines(summary) {
+		buffer += fmt.Sprintf("%-10s %s\n", line[0]+":", line[1])
+	}
+
+	return buffer
+}
+
+func formatSummaryMarkdown(summary WeeklySummary) string {
+	lines := []string{
+		fmt.Sprintf(
+			"## Week %s - %s",
+			summary.From.Format("2006-01-02"), summary.To.Format("2006-01-02"),
+		),
+		"",
+		"| metric | value |",
+		"|---|---|",
+	}
+
+	for _, line := range getSummaryLines(summary) {
+		lines = append(lines, fmt.Sprintf("| %s | %s |", line[0], line[1]))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
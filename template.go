@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fields of session available in summary line template
+var templateFields = []string{
+	"avg_score", "avg_duration", "max_span", "total_score", "tests",
+	"accuracy", "practice", "mode", "date", "fitness",
+}
+
+// lineTemplate is text with {field} placeholders, literal braces are
+// written as {{ and }}
+type lineTemplate []templatePart
+
+// templatePart is either literal text or name of field
+type templatePart struct {
+	text  string
+	field string
+}
+
+func parseTemplate(text string) (lineTemplate, error) {
+	template := lineTemplate{}
+
+	literal := ""
+	runes := []rune(text)
+	for index := 0; index < len(runes); index++ {
+		symbol := runes[index]
+
+		switch {
+		case (symbol == '{' || symbol == '}') &&
+			index+1 < len(runes) && runes[index+1] == symbol:
+			literal += string(symbol)
+			index++
+
+		case symbol == '}':
+			return nil, fmt.Errorf(
+				"unexpected } at %d, use }} for brace", index,
+			)
+
+		case symbol == '{':
+			end := indexRune(runes[index:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed { at %d", index)
+			}
+
+			field := strings.TrimSpace(string(runes[index+1 : index+end]))
+			if !isTemplateField(field) {
+				return nil, fmt.Errorf(
+					"unknown field %q, expected one of: %s",
+					field, strings.Join(templateFields, ", "),
+				)
+			}
+
+			if literal != "" {
+				template = append(template, templatePart{text: literal})
+				literal = ""
+			}
+
+			template = append(template, templatePart{field: field})
+			index += end
+
+		default:
+			literal += string(symbol)
+		}
+	}
+
+	if literal != "" {
+		template = append(template, templatePart{text: literal})
+	}
+
+	return template, nil
+}
+
+func indexRune(runes []rune, symbol rune) int {
+	for index, candidate := range runes {
+		if candidate == symbol {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func isTemplateField(field string) bool {
+	for _, known := range templateFields {
+		if field == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (template lineTemplate) render(values map[string]string) string {
+	line := ""
+	for _, part := range template {
+		if part.field == "" {
+			line += part.text
+		} else {
+			line += values[part.field]
+		}
+	}
+
+	return line
+}
+
+// getTemplateValues returns fields of the session for summary line.
+func getTemplateValues(session Session, fitness Fitness) map[string]string {
+	tests := len(session.Results)
+	index, _ := fitness.getFitness(session)
+
+	return map[string]string{
+		"avg_score": formatFloat(
+			float64(session.TotalScore)/float64(tests), 2,
+		),
+		"avg_duration": formatFloat(session.AvgDuration, 2),
+		"max_span":     formatInt(getMaxSpan(session)),
+		"total_score":  formatInt(session.TotalScore),
+		"tests":        formatInt(tests),
+		"accuracy":     formatFloat(getSessionAccuracy(session)*100, 1),
+		"practice":     fmt.Sprint(session.Practice),
+		"mode":         getSessionMode(session),
+		"date":         session.Date.Format("2006-01-02 15:04"),
+		"fitness":      formatFloat(index, 1),
+	}
+}
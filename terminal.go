@@ -0,0 +1,13 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// screen is terminal of the application, tests replace it with fake one.
+var screen = ui.New()
+
+// clock returns current time for measuring tests and dating sessions.
+var clock = time.Now
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// byte order mark which Windows editors put at the beginning of UTF-8 files
+const byteOrderMark = "\ufeff"
+
+// normalizeText removes byte order mark and converts CRLF line endings, so
+// files edited on Windows are read the same way.
+func normalizeText(content []byte) []byte {
+	content = bytes.TrimPrefix(content, []byte(byteOrderMark))
+
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// trimLine trims whitespace and byte order mark of line read from file.
+func trimLine(line string) string {
+	return strings.TrimSpace(strings.TrimPrefix(line, byteOrderMark))
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	colorsAuto      = "auto"
+	colors8         = "8"
+	colors256       = "256"
+	colorsTrueColor = "truecolor"
+)
+
+// Theme is [theme] section of config, colors are names like "red", indexes
+// of 256-color palette like "214" or hex like "#5faf5f". Colors which
+// terminal can't show are replaced with the nearest supported ones.
+type Theme struct {
+	// count of colors supported by terminal: auto, 8, 256 or truecolor
+	Colors string `toml:"colors"`
+
+	Text    string `toml:"text"`
+	Correct string `toml:"correct"`
+	Wrong   string `toml:"wrong"`
+}
+
+// palette is theme resolved for the terminal
+type palette struct {
+	colors  string
+	text    ui.Attribute
+	correct ui.Attribute
+	wrong   ui.Attribute
+}
+
+var theme = palette{
+	colors:  colors8,
+	text:    ui.ColorDefault,
+	correct: ui.ColorGreen,
+	wrong:   ui.ColorRed,
+}
+
+var colorNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+}
+
+// detectColors guesses colors supported by terminal from environment.
+func detectColors() string {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return colorsTrueColor
+	}
+
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return colors256
+	}
+
+	return colors8
+}
+
+// loadTheme resolves colors of theme for the terminal.
+func loadTheme(config Theme) (palette, error) {
+	resolved := theme
+
+	resolved.colors = config.Colors
+	switch resolved.colors {
+	case "", colorsAuto:
+		resolved.colors = detectColors()
+	case colors8, colors256, colorsTrueColor:
+	default:
+		return palette{}, fmt.Errorf(
+			"theme: unknown colors %q, expected auto, 8, 256 or truecolor",
+			config.Colors,
+		)
+	}
+
+	for _, color := range []struct {
+		name   string
+		value  string
+		target *ui.Attribute
+	}{
+		{"text", config.Text, &resolved.text},
+		{"correct", config.Correct, &resolved.correct},
+		{"wrong", config.Wrong, &resolved.wrong},
+	} {
+		if color.value == "" {
+			continue
+		}
+
+		attribute, err := parseColor(color.value, resolved.colors)
+		if err != nil {
+			return palette{}, fmt.Errorf("theme: %s: %s", color.name, err)
+		}
+
+		*color.target = attribute
+	}
+
+	return resolved, nil
+}
+
+// parseColor parses color and converts it to attribute supported by
+// terminal. Attributes are colors of 256-color palette, so true colors are
+// shown as the nearest of them.
+func parseColor(value string, colors string) (ui.Attribute, error) {
+	if value == "default" {
+		return ui.ColorDefault, nil
+	}
+
+	for index, name := range colorNames {
+		if value == name {
+			return ui.Attribute(index + 1), nil
+		}
+	}
+
+	var index int
+	switch {
+	case strings.HasPrefix(value, "#"):
+		rgb, err := strconv.ParseUint(strings.TrimPrefix(value, "#"), 16, 32)
+		if err != nil || len(value) != 7 {
+			return 0, fmt.Errorf(
+				"invalid hex color %q, expected #rrggbb", value,
+			)
+		}
+
+		index = getNearest256(int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff))
+
+	default:
+		var err error
+		index, err = strconv.Atoi(value)
+		if err != nil || index < 0 || index > 255 {
+			return 0, fmt.Errorf(
+				"unknown color %q, expected name, 0-255 or #rrggbb", value,
+			)
+		}
+	}
+
+	if colors == colors8 {
+		return getNearest8(get256RGB(index)), nil
+	}
+
+	return ui.Attribute(index + 1), nil
+}
+
+// getNearest256 returns index of the nearest color in 6x6x6 cube or
+// grayscale ramp of 256-color palette.
+func getNearest256(r, g, b int) int {
+	level := func(value int) int {
+		if value < 48 {
+			return 0
+		}
+
+		if value < 115 {
+			return 1
+		}
+
+		return (value - 35) / 40
+	}
+
+	cube := 16 + 36*level(r) + 6*level(g) + level(b)
+
+	gray := (r + g + b) / 3
+	grayIndex := 232 + (gray-8)/10
+	if gray < 8 {
+		grayIndex = 16
+	}
+
+	if grayIndex > 255 {
+		grayIndex = 231
+	}
+
+	if getDistance(get256RGB(grayIndex), [3]int{r, g, b}) <
+		getDistance(get256RGB(cube), [3]int{r, g, b}) {
+		return grayIndex
+	}
+
+	return cube
+}
+
+// get256RGB returns approximate red, green and blue of 256-color palette
+// color.
+func get256RGB(index int) [3]int {
+	switch {
+	case index < 16:
+		intensity := 128
+		if index >= 8 {
+			intensity = 255
+		}
+
+		return [3]int{
+			index & 1 * intensity,
+			index >> 1 & 1 * intensity,
+			index >> 2 & 1 * intensity,
+		}
+
+	case index < 232:
+		steps := []int{0, 95, 135, 175, 215, 255}
+		index -= 16
+
+		return [3]int{steps[index/36], steps[index/6%6], steps[index%6]}
+
+	default:
+		gray := 8 + (index-232)*10
+		return [3]int{gray, gray, gray}
+	}
+}
+
+func getDistance(a, b [3]int) int {
+	distance := 0
+	for i := range a {
+		distance += (a[i] - b[i]) * (a[i] - b[i])
+	}
+
+	return distance
+}
+
+// getNearest8 returns the nearest of 8 basic colors.
+func getNearest8(rgb [3]int) ui.Attribute {
+	index := 0
+	for bit, value := range rgb {
+		if value >= 128 {
+			index |= 1 << uint(bit)
+		}
+	}
+
+	return ui.Attribute(index + 1)
+}
+
+// getHeatColor returns color from red for zero accuracy through yellow to
+// green for full accuracy, 8-color terminals get three steps.
+func getHeatColor(accuracy float64) ui.Attribute {
+	accuracy = clampFloat(accuracy, 0, 1)
+
+	if theme.colors == colors8 {
+		switch {
+		case accuracy < 0.5:
+			return ui.ColorRed
+		case accuracy < 0.9:
+			return ui.ColorYellow
+		default:
+			return ui.ColorGreen
+		}
+	}
+
+	red, green := 255, 255
+	if accuracy < 0.5 {
+		green = int(255 * accuracy * 2)
+	} else {
+		red = int(255 * (1 - accuracy) * 2)
+	}
+
+	return ui.Attribute(getNearest256(red, green, 0) + 1)
+}
+
+func clampFloat(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
+// span is part of line drawn with specified color
+type span struct {
+	text  string
+	color ui.Attribute
+}
+
+// styledLine is line of text parts with different colors
+type styledLine []span
+
+func plainLines(lines []string) []styledLine {
+	styled := []styledLine{}
+	for _, line := range lines {
+		styled = append(styled, styledLine{{line, theme.text}})
+	}
+
+	return styled
+}
+
+func (line styledLine) String() string {
+	text := ""
+	for _, span := range line {
+		text += span.text
+	}
+
+	return text
+}
+
+// getDiffLine colors every entered item as correct or wrong.
+func getDiffLine(
+	label string, input []string, separator string, isCorrect func(int) bool,
+) styledLine {
+	line := styledLine{{label, theme.text}}
+	for index, item := range input {
+		color := theme.wrong
+		if isCorrect(index) {
+			color = theme.correct
+		}
+
+		if index > 0 {
+			line = append(line, span{separator, theme.text})
+		}
+
+		line = append(line, span{item, color})
+	}
+
+	return line
+}
+
+// alignTokens pads correct and entered items to the same width at every
+// position, so entered items are drawn right under correct ones.
+func alignTokens(valid, input []string) ([]string, []string) {
+	size := len(valid)
+	if len(input) > size {
+		size = len(input)
+	}
+
+	alignedValid := make([]string, size)
+	alignedInput := make([]string, size)
+	for index := 0; index < size; index++ {
+		if index < len(valid) {
+			alignedValid[index] = valid[index]
+		}
+
+		if index < len(input) {
+			alignedInput[index] = input[index]
+		}
+
+		width := len([]rune(alignedValid[index]))
+		if length := len([]rune(alignedInput[index])); length > width {
+			width = length
+		}
+
+		alignedValid[index] = padRight(alignedValid[index], width)
+		alignedInput[index] = padRight(alignedInput[index], width)
+	}
+
+	return alignedValid, alignedInput
+}
+
+func padRight(text string, width int) string {
+	return text + strings.Repeat(" ", width-len([]rune(text)))
+}
+
+// isSameAt returns function which checks if input item equals to valid item
+// at the same position.
+func isSameAt(valid, input []string) func(int) bool {
+	return func(index int) bool {
+		return index < len(valid) && index < len(input) &&
+			valid[index] == input[index]
+	}
+}
+
+// printStyled prints line in the center of screen, lines which don't fit
+// are wrapped without colors.
+func printStyled(line styledLine, y int) {
+	width, _ := screen.Size()
+
+	text := line.String()
+	if runewidth.StringWidth(text) > width-2 {
+		printCentered(text, y)
+		return
+	}
+
+	x := getCenteredX(text, width)
+	for _, span := range line {
+		for _, symbol := range span.text {
+			x++
+			screen.SetCell(x, y, symbol, span.color, ui.ColorDefault)
+
+			if runewidth.RuneWidth(symbol) == 2 {
+				x++
+			}
+		}
+	}
+
+	screen.Flush()
+}
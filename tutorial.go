@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kovetskiy/short/internal/ui"
+)
+
+// numbers of sample trial of the tutorial
+var tutorialNumbers = []int{42, 17, 83}
+
+// getTutorialMarker returns file next to the config, which tells that the
+// tutorial was shown.
+func getTutorialMarker(config string) string {
+	return filepath.Join(filepath.Dir(config), ".tutorial")
+}
+
+// isFirstRun tells if the tutorial should be shown before the session, it's
+// shown once for new users, who have no database yet.
+func isFirstRun(database, config string) bool {
+	for _, file := range []string{database, getTutorialMarker(config)} {
+		_, err := os.Stat(file)
+		if !os.IsNotExist(err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func markTutorialShown(config string) {
+	marker := getTutorialMarker(config)
+
+	err := os.MkdirAll(filepath.Dir(marker), 0700)
+	if err == nil {
+		err = ioutil.WriteFile(marker, nil, 0600)
+	}
+
+	if err != nil {
+		log.warn("can't mark tutorial as shown", Fields{"error": err})
+	}
+}
+
+// runTutorial shows the tutorial as standalone command.
+func runTutorial(config string) error {
+	err := screen.Init()
+	if err != nil {
+		return err
+	}
+
+	err = showTutorial()
+	screen.Close()
+
+	markTutorialShown(config)
+
+	if err == errAborted || err == errStopped {
+		return nil
+	}
+
+	return err
+}
+
+// showTutorial walks through sample trial, every step explains the screen
+// under it in the overlay on the top of the screen.
+func showTutorial() error {
+	width, height := screen.Size()
+	y := height / 2
+
+	clearScreen()
+	drawOverlay(
+		"Welcome to short, it tests your short-term memory.",
+		"Numbers are shown, you remember them and type them back.",
+		"Enter: next step, Esc or Ctrl+C: skip the tutorial.",
+	)
+
+	err := wait()
+	if err != nil {
+		return err
+	}
+
+	shown := joinNumbers(tutorialNumbers)
+
+	clearScreen()
+	printCentered(shown, y)
+	drawOverlay(
+		"Remember these numbers, time is counted while they are shown.",
+		"Press Enter when you are ready, numbers will be hidden.",
+		"With --expose they are hidden automatically.",
+	)
+
+	err = wait()
+	if err != nil {
+		return err
+	}
+
+	clearScreen()
+	drawOverlay(
+		"Type the numbers in the same order separated by spaces.",
+		"Backspace fixes mistakes, Enter submits the answer.",
+	)
+
+	x := getCenteredX(shown, width)
+	screen.SetCursor(x+1, y)
+	screen.Flush()
+
+	text, err := readText(x, y, isDigit)
+	if err != nil {
+		return err
+	}
+
+	input := parseNumbers(text)
+	score := compare(tutorialNumbers, input)
+
+	valid := strings.Fields(shown)
+	entered := strings.Fields(joinNumbers(input))
+
+	clearScreen()
+	printStyled(styledLine{{"correct: " + shown, theme.text}}, y-1)
+	printStyled(
+		getDiffLine("entered: ", entered, " ", isSameAt(valid, entered)), y,
+	)
+	printCentered(fmt.Sprintf("score: %d/%d", score, len(valid)), y+2)
+	drawOverlay(
+		"Score counts numbers recalled in order until the first mistake.",
+		"Use --feedback to see this screen after every test.",
+		"Try -c to change count of numbers and -n count of tests.",
+	)
+
+	err = wait()
+	if err != nil {
+		return err
+	}
+
+	clearScreen()
+	drawOverlay(
+		"That's it! Run short tutorial to see this again,",
+		"short stats to see your progress and short --help for more.",
+		"During session p pauses after the test, Esc stops it keeping",
+		"finished tests. Press Enter to start.",
+	)
+
+	return wait()
+}
+
+// drawOverlay draws framed annotation on the top of the screen.
+func drawOverlay(lines ...string) {
+	width, _ := screen.Size()
+
+	boxWidth := 0
+	for _, line := range lines {
+		if len([]rune(line)) > boxWidth {
+			boxWidth = len([]rune(line))
+		}
+	}
+
+	boxWidth = clamp(boxWidth+4, 4, width)
+	left := (width - boxWidth) / 2
+
+	for y := 0; y < len(lines)+2; y++ {
+		for x := left; x < left+boxWidth; x++ {
+			symbol := ' '
+			switch {
+			case (y == 0 || y == len(lines)+1) &&
+				(x == left || x == left+boxWidth-1):
+				symbol = '+'
+			case y == 0 || y == len(lines)+1:
+				symbol = '-'
+			case x == left || x == left+boxWidth-1:
+				symbol = '|'
+			}
+
+			screen.SetCell(x, y, symbol, theme.text, ui.ColorDefault)
+		}
+	}
+
+	for index, line := range lines {
+		runes := []rune(line)
+		if len(runes) > boxWidth-4 {
+			runes = runes[:clamp(boxWidth-4, 0, len(runes))]
+		}
+
+		for offset, symbol := range runes {
+			screen.SetCell(
+				left+2+offset, index+1, symbol, theme.text,
+				ui.ColorDefault,
+			)
+		}
+	}
+
+	screen.HideCursor()
+	screen.Flush()
+}
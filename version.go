@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kovetskiy/short/version"
+)
+
+func printVersion(asJSON bool) error {
+	info := version.Get()
+
+	if asJSON {
+		content, err := json.MarshalIndent(info, "", "    ")
+		if err != nil {
+			return err
+		}
+
+		return writeOutput("-", append(content, '\n'))
+	}
+
+	fmt.Printf("short %s", info.Version)
+	if info.Commit != "" {
+		fmt.Printf(", commit %s", info.Commit)
+	}
+
+	if info.Date != "" {
+		fmt.Printf(", built %s", info.Date)
+	}
+
+	fmt.Printf(", %s %s\n", info.GoVersion, info.Platform)
+
+	return nil
+}
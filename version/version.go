@@ -0,0 +1,62 @@
+// Package version provides build metadata of short. Packagers set it with
+// linker flags:
+//
+//	go build -ldflags "
+//	    -X github.com/kovetskiy/short/version.version=1.1.0
+//	    -X github.com/kovetskiy/short/version.commit=$(git rev-parse HEAD)
+//	    -X github.com/kovetskiy/short/version.date=$(date -u +%FT%TZ)"
+//
+// Commit and date default to VCS information recorded by go build.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	version = "1.0"
+	commit  = ""
+	date    = ""
+)
+
+// Info is build metadata of the binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Version returns version of short, like 1.0.
+func Version() string {
+	return version
+}
+
+// Get returns build metadata of the binary.
+func Get() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range build.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.Date == "":
+			info.Date = setting.Value
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// default count of attempts to post results, the first one is made
+	// when session is finished, the next ones when queue is flushed
+	defaultWebhookAttempts = 10
+
+	webhookTimeout = 10 * time.Second
+)
+
+// Webhook is [webhook] section of config, results of every session are
+// posted as JSON to url. Body is signed by HMAC-SHA256 with secret, the
+// signature is sent in X-Short-Signature header as sha256=<hex>, and
+// X-Short-Delivery header identifies the session for deduplication.
+type Webhook struct {
+	URL      string `toml:"url"`
+	Secret   string `toml:"secret"`
+
+	// count of attempts to post results, failed requests are queued and
+	// sent again on next runs
+	Attempts int `toml:"attempts"`
+
+	// post one digest of sessions per day instead of every session
+	Digest bool `toml:"digest"`
+}
+
+func (webhook Webhook) validate() error {
+	switch {
+	case webhook.URL == "" && webhook.Secret != "":
+		return fmt.Errorf("secret is set, but url is not")
+	case webhook.Attempts < 0:
+		return fmt.Errorf("attempts can't be negative")
+	}
+
+	return nil
+}
+
+func (webhook Webhook) getAttempts() int {
+	if webhook.Attempts == 0 {
+		return defaultWebhookAttempts
+	}
+
+	return webhook.Attempts
+}
+
+// webhookPayload is body of the request with either session or digest of
+// the day, delivery identifies them, so receiver can drop duplicates of
+// retried requests
+type webhookPayload struct {
+	Delivery string     `json:"delivery"`
+	Session  *Session   `json:"session,omitempty"`
+	Digest   *digestDay `json:"digest,omitempty"`
+}
+
+// signPayload returns signature of body in format of X-Short-Signature
+// header.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func getDeliveryID(session Session) string {
+	return strconv.FormatInt(session.Date.UnixNano(), 36)
+}
+
+// postResults posts session to webhook, or adds it to the daily digest if
+// digest is enabled.
+func postResults(
+	database string, webhook Webhook, session Session, location *time.Location,
+) error {
+	if webhook.Digest {
+		return postDigest(database, webhook, session, clock().In(location))
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Delivery: getDeliveryID(session),
+		Session:  &session,
+	})
+	if err != nil {
+		return err
+	}
+
+	return deliver(database, webhook, getDeliveryID(session), body)
+}
+
+// postDigest adds session to the digest and posts digests of previous days,
+// so the receiver gets at most one notification per day. Digest of today is
+// posted by the first session of another day.
+func postDigest(
+	database string, webhook Webhook, session Session, now time.Time,
+) error {
+	days, err := updateDigest(database, integrationWebhook, session, now)
+	if err != nil {
+		return err
+	}
+
+	// days are already removed from the pending digest, so every one of
+	// them is delivered or queued even if previous ones fail
+	errs := []error{}
+	for _, day := range days {
+		delivery := "digest-" + day.Date
+
+		body, err := json.Marshal(webhookPayload{
+			Delivery: delivery,
+			Digest:   &day,
+		})
+		if err == nil {
+			err = deliver(database, webhook, delivery, body)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest of %s: %w", day.Date, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver posts body to webhook, failed request is queued right away to
+// be sent on the next run, so unreachable receiver doesn't delay exit.
+func deliver(
+	database string, webhook Webhook, delivery string, body []byte,
+) error {
+	err := sendWebhook(webhook, delivery, body)
+	if err == nil {
+		return nil
+	}
+
+	log.warn("can't post results", Fields{"url": webhook.URL, "error": err})
+
+	if isRejected(err) {
+		// the same payload would be rejected again
+		return fmt.Errorf("results are rejected by %s: %w", webhook.URL, err)
+	}
+
+	queueErr := enqueue(database, queuedPayload{
+		Kind:     queueWebhook,
+		URL:      webhook.URL,
+		Delivery: delivery,
+		Body:     body,
+		Queued:   time.Now(),
+		Attempts: 1,
+		Error:    err.Error(),
+	})
+	if queueErr != nil {
+		return fmt.Errorf("%s, can't queue results: %s", err, queueErr)
+	}
+
+	return fmt.Errorf("%s, results are queued", err)
+}
+
+func sendWebhook(webhook Webhook, delivery string, body []byte) error {
+	request, err := http.NewRequest(
+		http.MethodPost, webhook.URL, bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Short-Delivery", delivery)
+	if webhook.Secret != "" {
+		request.Header.Set(
+			"X-Short-Signature", signPayload(webhook.Secret, body),
+		)
+	}
+
+	client := http.Client{Timeout: webhookTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return statusError{code: response.StatusCode, status: response.Status}
+	}
+
+	return nil
+}
+
+// statusError is response of the receiver with unexpected status
+type statusError struct {
+	code   int
+	status string
+}
+
+func (err statusError) Error() string {
+	return "unexpected status " + err.status
+}
+
+// isRejected returns true if the receiver rejected the request, client
+// errors except timeouts and rate limits are not fixed by retries.
+func isRejected(err error) bool {
+	var status statusError
+	if !errors.As(err, &status) {
+		return false
+	}
+
+	return status.code >= 400 && status.code < 500 &&
+		status.code != http.StatusRequestTimeout &&
+		status.code != http.StatusTooManyRequests
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignPayload(t *testing.T) {
+	// test case 2 of RFC 4231
+	signature := signPayload("Jefe", []byte("what do ya want for nothing?"))
+
+	expected := "sha256=" +
+		"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if signature != expected {
+		t.Errorf("expected %s, got %s", expected, signature)
+	}
+}
+
+func TestDeliverQueuesFailedRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		queued int
+	}{
+		{"delivered", http.StatusNoContent, 0},
+		{"unavailable", http.StatusServiceUnavailable, 1},
+		{"rejected", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(
+			func(writer http.ResponseWriter, request *http.Request) {
+				requests++
+
+				signature := request.Header.Get("X-Short-Signature")
+				if signature != signPayload("secret", []byte(`{}`)) {
+					t.Errorf("%s: wrong signature %q", test.name, signature)
+				}
+
+				writer.WriteHeader(test.status)
+			},
+		))
+
+		database := filepath.Join(t.TempDir(), "db")
+		webhook := Webhook{URL: server.URL, Secret: "secret"}
+
+		err := deliver(database, webhook, "delivery", []byte(`{}`))
+		server.Close()
+
+		if (err == nil) != (test.status == http.StatusNoContent) {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+
+		// failed request is queued without retrying it on the way out
+		if requests != 1 {
+			t.Errorf("%s: expected 1 request, got %d", test.name, requests)
+		}
+
+		queue, err := loadQueue(getQueueFile(database))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(queue) != test.queued {
+			t.Errorf(
+				"%s: expected %d queued, got %d",
+				test.name, test.queued, len(queue),
+			)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// condition of --where, like date<2025-01-01 or mode=digits
+type condition struct {
+	field    string
+	operator string
+	value    string
+
+	match func(session Session) bool
+}
+
+// operators are ordered, so two-character operators are found first
+var operators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+func parseWhere(expression string) (condition, error) {
+	for _, operator := range operators {
+		position := strings.Index(expression, operator)
+		if position < 0 {
+			continue
+		}
+
+		condition := condition{
+			field:    strings.TrimSpace(expression[:position]),
+			operator: operator,
+			value:    strings.TrimSpace(expression[position+len(operator):]),
+		}
+
+		err := condition.compile()
+		if err != nil {
+			return condition, fmt.Errorf("--where %q: %s", expression, err)
+		}
+
+		return condition, nil
+	}
+
+	return condition{}, fmt.Errorf(
+		"--where %q: expected field, operator and value, like date<2025-01-01",
+		expression,
+	)
+}
+
+func (condition *condition) compile() error {
+	switch condition.field {
+	case "date":
+		start, err := time.ParseInLocation(
+			"2006-01-02", condition.value, time.Local,
+		)
+		if err != nil {
+			return fmt.Errorf("date should be in YYYY-MM-DD format")
+		}
+
+		// date is compared as a day, so date<=2025-01-01 includes the whole
+		// day, as well as date=2025-01-01
+		end := start.AddDate(0, 0, 1)
+
+		condition.match = func(session Session) bool {
+			date := session.Date
+			switch condition.operator {
+			case "<":
+				return date.Before(start)
+			case "<=":
+				return date.Before(end)
+			case ">":
+				return !date.Before(end)
+			case ">=":
+				return !date.Before(start)
+			case "!=":
+				return date.Before(start) || !date.Before(end)
+			default:
+				return !date.Before(start) && date.Before(end)
+			}
+		}
+
+	case "score", "tests":
+		value, err := strconv.ParseFloat(condition.value, 64)
+		if err != nil {
+			return fmt.Errorf("%s should be a number", condition.field)
+		}
+
+		condition.match = func(session Session) bool {
+			actual := getSessionAccuracy(session) * 100
+			if condition.field == "tests" {
+				actual = float64(getSessionTests(session))
+			}
+
+			return compareOrdered(condition.operator, actual, value)
+		}
+
+	case "mode", "tag", "practice":
+		if condition.operator != "=" && condition.operator != "!=" {
+			return fmt.Errorf(
+				"%s can be compared only with = or !=", condition.field,
+			)
+		}
+
+		condition.match = func(session Session) bool {
+			var matched bool
+			switch condition.field {
+			case "mode":
+				matched = getSessionMode(session) == condition.value
+			case "tag":
+				matched = session.hasTag(condition.value)
+			case "practice":
+				practice := strconv.FormatBool(session.Practice)
+				matched = practice == condition.value
+			}
+
+			return matched == (condition.operator == "=")
+		}
+
+	default:
+		return fmt.Errorf(
+			"unknown field %q, expected date, score, tests, mode, tag "+
+				"or practice",
+			condition.field,
+		)
+	}
+
+	return nil
+}
+
+func compareOrdered(operator string, a, b float64) bool {
+	switch operator {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	default:
+		return a == b
+	}
+}
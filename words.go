@@ -0,0 +1,143 @@
+package main
+
+import (
+	"strings"
+)
+
+// words used when no word list is given, common concrete nouns, which are
+// easy to tell apart
+var builtinWords = []string{
+	"apple", "arm", "baby", "bag", "ball", "bank", "bed", "bell", "bird",
+	"boat", "body", "bone", "book", "boot", "bottle", "box", "boy", "brain",
+	"bread", "brick", "bridge", "brush", "bucket", "cake", "camera", "car",
+	"card", "carpet", "cat", "chain", "chair", "cheese", "chest", "child",
+	"church", "circle", "clock", "cloud", "coat", "coin", "comb", "cow",
+	"cup", "curtain", "cushion", "desk", "dog", "door", "drain", "dress",
+	"drum", "ear", "egg", "engine", "eye", "face", "farm", "feather",
+	"finger", "fish", "flag", "floor", "flower", "fly", "foot", "fork",
+	"fruit", "garden", "girl", "glove", "goat", "gun", "hair", "hammer",
+	"hand", "hat", "head", "heart", "hook", "horn", "horse", "house",
+	"island", "jewel", "kettle", "key", "knee", "knife", "knot", "lamp",
+	"leaf", "leg", "library", "lock", "map", "market", "match", "milk",
+	"monkey", "moon", "mouth", "nail", "neck", "needle", "nest", "nose",
+	"orange", "oven", "parcel", "pen", "pencil", "picture", "pig", "pin",
+	"pipe", "plane", "plate", "plow", "pocket", "pot", "potato", "prison",
+	"pump", "rail", "rat", "ring", "river", "rod", "roof", "root", "sail",
+	"school", "scissors", "screw", "seed", "sheep", "shelf", "ship",
+	"shirt", "shoe", "skirt", "snake", "sock", "spade", "sponge", "spoon",
+	"spring", "square", "stamp", "star", "station", "stem", "stick",
+	"stocking", "stomach", "store", "street", "sun", "table", "tail",
+	"thread", "throat", "thumb", "ticket", "toe", "tongue", "tooth", "town",
+	"train", "tray", "tree", "trousers", "umbrella", "wall", "watch",
+	"wheel", "whip", "whistle", "window", "wing", "wire", "worm",
+}
+
+// loadWords reads word list from specified file, words are separated by
+// spaces or new lines, duplicates are removed.
+func loadWords(file string) ([]string, error) {
+	lines, err := loadCorpus(file)
+	if err != nil {
+		return nil, err
+	}
+
+	words := []string{}
+	seen := map[string]bool{}
+	for _, line := range lines {
+		for _, word := range strings.Fields(line) {
+			if !seen[normalizeWord(word)] {
+				seen[normalizeWord(word)] = true
+				words = append(words, word)
+			}
+		}
+	}
+
+	return words, nil
+}
+
+// pickWords returns specified count of different random words.
+// getWordList returns words of --wordlist or built-in words.
+func getWordList(options Options) []string {
+	if len(options.Words) == 0 {
+		return builtinWords
+	}
+
+	return options.Words
+}
+
+func pickWords(words []string, count int) []string {
+	picked := []string{}
+	for _, index := range pickRandomIndexes(len(words)) {
+		if len(picked) == count {
+			break
+		}
+
+		picked = append(picked, words[index])
+	}
+
+	return picked
+}
+
+func runWordsTest(options Options, test Test) (Result, error) {
+	words := pickWords(getWordList(options), test.Count)
+
+	timeStart := clock()
+	pausedStart := getPausedDuration()
+
+	_, height := screen.Size()
+
+	clearScreen()
+	printCentered(strings.Join(words, " "), height/2)
+	screen.HideCursor()
+	screen.Flush()
+
+	err := waitTimeout(test.Exposure)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paused := getPausedDuration() - pausedStart
+	duration := clock().Sub(timeStart).Seconds() - paused.Seconds()
+
+	interference, err := interfere(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	text, err := readLine("", height/2-1)
+	if err != nil {
+		return Result{}, err
+	}
+
+	input := strings.Fields(text)
+	score := compareWords(words, input)
+
+	clearScreen()
+
+	var note string
+	if options.Feedback {
+		note, err = showFeedback(
+			[]styledLine{
+				getCorrectLine(options, modeWords, words, " "),
+				getDiffLine("entered: ", input, " ", func(index int) bool {
+					return index < len(words) && normalizeWord(words[index]) ==
+						normalizeWord(input[index])
+				}),
+			},
+			score, len(words),
+		)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		Interference: interference,
+		Score:        score,
+		Duration:     duration,
+		Count:        len(words),
+		Note:         note,
+		Mode:         modeWords,
+		Items:        words,
+		Input:        input,
+	}, nil
+}